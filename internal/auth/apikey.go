@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+)
+
+// APIKeyHeader is the metadata key, or http header, carrying an API key.
+const APIKeyHeader = "x-api-key"
+
+// APIKeyAuthenticator identifies callers by a static API key. Keys are only
+// kept as hashes.
+type APIKeyAuthenticator struct {
+	identities map[[sha256.Size]byte]string
+}
+
+// NewAPIKeyAuthenticator maps every API key to an identity.
+func NewAPIKeyAuthenticator(keys map[string]string) *APIKeyAuthenticator {
+	identities := map[[sha256.Size]byte]string{}
+	for key, id := range keys {
+		identities[sha256.Sum256([]byte(key))] = id
+	}
+	return &APIKeyAuthenticator{identities: identities}
+}
+
+// LoadAPIKeyAuthenticator reads the keys from a file of "<identity> <key>"
+// lines.
+func LoadAPIKeyAuthenticator(path string) (*APIKeyAuthenticator, error) {
+	pairs, err := readPairs(path)
+	if err != nil {
+		return nil, err
+	}
+	keys := map[string]string{}
+	for _, pair := range pairs {
+		keys[pair[1]] = pair[0]
+	}
+	return NewAPIKeyAuthenticator(keys), nil
+}
+
+func (a *APIKeyAuthenticator) Authenticate(ctx context.Context) (*Identity, error) {
+	key, ok := incomingHeader(ctx, APIKeyHeader)
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+
+	id, ok := a.identities[sha256.Sum256([]byte(key))]
+	if !ok {
+		return nil, fmt.Errorf("unknown api key")
+	}
+	return &Identity{ID: id, Method: "api-key"}, nil
+}
@@ -0,0 +1,186 @@
+// Package auth authenticates callers of the gRPC service and its HTTP
+// gateway. Authenticators check credentials offline, with no call to an
+// identity provider, and the Interceptor puts the resulting Identity in the
+// request's context.
+package auth
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// ErrNoCredentials is returned by an Authenticator when the request carries
+// none of the credentials it checks, so the next authenticator can try.
+var ErrNoCredentials = errors.New("no credentials")
+
+// Identity is who a request was authenticated as.
+type Identity struct {
+	ID     string
+	Method string // the authenticator that vouched for the identity, ex: "api-key"
+}
+
+type Authenticator interface {
+	// Authenticate checks the credentials of the request in ctx. It returns
+	// ErrNoCredentials if there are none to check.
+	Authenticate(ctx context.Context) (*Identity, error)
+}
+
+type (
+	identityKey  struct{}
+	anonymousKey struct{}
+)
+
+func NewContext(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// FromContext returns the identity a request was authenticated as, if any.
+func FromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(*Identity)
+	return identity, ok && identity != nil
+}
+
+// Anonymous reports whether a request was let through without credentials
+// by an interceptor with authenticators, so the caller could have proven who
+// they are and didn't.
+func Anonymous(ctx context.Context) bool {
+	anonymous, _ := ctx.Value(anonymousKey{}).(bool)
+	return anonymous
+}
+
+// Interceptor runs its authenticators in order on every request. The first
+// one to find credentials decides. Requests without any credentials are let
+// through anonymously unless authentication is required.
+type Interceptor struct {
+	authenticators []Authenticator
+	required       bool
+}
+
+func NewInterceptor(required bool, authenticators ...Authenticator) *Interceptor {
+	return &Interceptor{authenticators: authenticators, required: required}
+}
+
+func (i *Interceptor) authenticate(ctx context.Context) (context.Context, error) {
+	for _, authenticator := range i.authenticators {
+		identity, err := authenticator.Authenticate(ctx)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		if err != nil {
+			return nil, status.Errorf(codes.Unauthenticated, "invalid credentials: %v", err)
+		}
+		return NewContext(ctx, identity), nil
+	}
+
+	if i.required {
+		return nil, status.Error(codes.Unauthenticated, "missing credentials")
+	}
+	if len(i.authenticators) > 0 {
+		ctx = context.WithValue(ctx, anonymousKey{}, true)
+	}
+	return ctx, nil
+}
+
+func (i *Interceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := i.authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func (i *Interceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := i.authenticate(stream.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// HTTP authenticates requests to an http.Handler. Headers are passed to the
+// authenticators as gRPC metadata, and the connection's TLS state as the
+// peer, so both transports accept the same credentials. onError writes the
+// response for requests that fail authentication.
+func (i *Interceptor) HTTP(next http.Handler, onError func(http.ResponseWriter, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		md := metadata.MD{}
+		for key, values := range r.Header {
+			md.Append(strings.ToLower(key), values...)
+		}
+		ctx := metadata.NewIncomingContext(r.Context(), md)
+		if r.TLS != nil {
+			ctx = peer.NewContext(ctx, &peer.Peer{AuthInfo: credentials.TLSInfo{State: *r.TLS}})
+		}
+
+		ctx, err := i.authenticate(ctx)
+		if err != nil {
+			onError(w, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// readPairs reads a file of whitespace separated pairs, one per line. Blank
+// lines and lines starting with # are skipped.
+func readPairs(path string) ([][2]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	pairs := [][2]string{}
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected 2 fields, got=%d", path, lineNumber, len(fields))
+		}
+		pairs = append(pairs, [2]string{fields[0], fields[1]})
+	}
+
+	return pairs, scanner.Err()
+}
+
+// incomingHeader returns the first value of a metadata key, if present.
+func incomingHeader(ctx context.Context, key string) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+	values := md.Get(key)
+	if len(values) == 0 {
+		return "", false
+	}
+	return values[0], true
+}
@@ -0,0 +1,275 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func withHeaders(pairs ...string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(pairs...))
+}
+
+func withClientCert(t *testing.T, commonName string) (context.Context, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("could not generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("could not create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("could not parse certificate: %v", err)
+	}
+
+	state := tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+	return peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{State: state}}), cert
+}
+
+func TestAPIKeyAuthenticator(t *testing.T) {
+	authenticator := NewAPIKeyAuthenticator(map[string]string{"s3cret": "alice"})
+
+	testCases := []struct {
+		name        string
+		ctx         context.Context
+		expectedID  string
+		expectedErr bool
+	}{
+		{"valid key", withHeaders(APIKeyHeader, "s3cret"), "alice", false},
+		{"unknown key", withHeaders(APIKeyHeader, "nope"), "", true},
+		{"no key", withHeaders("authorization", "Bearer abc"), "", false},
+		{"no metadata", context.Background(), "", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			identity, err := authenticator.Authenticate(tc.ctx)
+			checkAuthentication(t, identity, err, tc.expectedID, tc.expectedErr)
+		})
+	}
+}
+
+func TestTokenAuthenticator(t *testing.T) {
+	authenticator, err := NewTokenAuthenticator(testSecret)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	authenticator.now = func() time.Time { return now }
+
+	token, err := authenticator.Issue("bob", time.Hour)
+	if err != nil {
+		t.Fatalf("could not issue token: %v", err)
+	}
+
+	other, _ := NewTokenAuthenticator([]byte("another secret that is long enough"))
+	other.now = authenticator.now
+	forged, _ := other.Issue("bob", time.Hour)
+
+	testCases := []struct {
+		name        string
+		ctx         context.Context
+		expectedID  string
+		expectedErr bool
+	}{
+		{"valid token", withHeaders("authorization", "Bearer "+token), "bob", false},
+		{"other secret", withHeaders("authorization", "Bearer "+forged), "", true},
+		{"tampered", withHeaders("authorization", "Bearer x"+token), "", true},
+		{"malformed", withHeaders("authorization", "Bearer nodot"), "", true},
+		{"not a bearer token", withHeaders("authorization", "Basic abc"), "", false},
+		{"no token", withHeaders(APIKeyHeader, "s3cret"), "", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			identity, err := authenticator.Authenticate(tc.ctx)
+			checkAuthentication(t, identity, err, tc.expectedID, tc.expectedErr)
+		})
+	}
+
+	now = now.Add(2 * time.Hour)
+	if _, err := authenticator.Authenticate(withHeaders("authorization", "Bearer "+token)); err == nil {
+		t.Fatalf("expected an expired token to fail")
+	}
+}
+
+func TestTokenSecretSize(t *testing.T) {
+	if _, err := NewTokenAuthenticator([]byte("short")); err == nil {
+		t.Fatalf("expected a short secret to be rejected")
+	}
+}
+
+func TestMTLSAuthenticator(t *testing.T) {
+	ctx, cert := withClientCert(t, "carol")
+	fingerprint := sha256.Sum256(cert.Raw)
+
+	testCases := []struct {
+		name        string
+		identities  map[string]string
+		ctx         context.Context
+		expectedID  string
+		expectedErr bool
+	}{
+		{"common name", nil, ctx, "carol", false},
+		{"mapped common name", map[string]string{"carol": "gm"}, ctx, "gm", false},
+		{"mapped fingerprint", map[string]string{hex.EncodeToString(fingerprint[:]): "gm"}, ctx, "gm", false},
+		{"unmapped", map[string]string{"dave": "gm"}, ctx, "", true},
+		{"no certificate", nil, context.Background(), "", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			identity, err := NewMTLSAuthenticator(tc.identities).Authenticate(tc.ctx)
+			checkAuthentication(t, identity, err, tc.expectedID, tc.expectedErr)
+		})
+	}
+}
+
+// an expectedID of "" with no expected error means ErrNoCredentials
+func checkAuthentication(t *testing.T, identity *Identity, err error, expectedID string, expectedErr bool) {
+	t.Helper()
+	switch {
+	case expectedErr:
+		if err == nil || errors.Is(err, ErrNoCredentials) {
+			t.Fatalf("expected invalid credentials, got identity=%v err=%v", identity, err)
+		}
+	case expectedID == "":
+		if !errors.Is(err, ErrNoCredentials) {
+			t.Fatalf("expected ErrNoCredentials, got identity=%v err=%v", identity, err)
+		}
+	default:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if identity.ID != expectedID {
+			t.Fatalf("expected id=%s, got=%s", expectedID, identity.ID)
+		}
+	}
+}
+
+func TestInterceptor(t *testing.T) {
+	apiKeys := NewAPIKeyAuthenticator(map[string]string{"s3cret": "alice"})
+
+	testCases := []struct {
+		name              string
+		required          bool
+		ctx               context.Context
+		expectedID        string
+		expectedAnonymous bool
+		expectedCode      codes.Code
+	}{
+		{"authenticated", true, withHeaders(APIKeyHeader, "s3cret"), "alice", false, codes.OK},
+		{"invalid credentials", false, withHeaders(APIKeyHeader, "nope"), "", false, codes.Unauthenticated},
+		{"anonymous", false, context.Background(), "", true, codes.OK},
+		{"anonymous but required", true, context.Background(), "", false, codes.Unauthenticated},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			interceptor := NewInterceptor(tc.required, apiKeys)
+			var got *Identity
+			anonymous := false
+			_, err := interceptor.Unary()(tc.ctx, nil, nil, func(ctx context.Context, req any) (any, error) {
+				got, _ = FromContext(ctx)
+				anonymous = Anonymous(ctx)
+				return nil, nil
+			})
+			if status.Code(err) != tc.expectedCode {
+				t.Fatalf("expected code=%s, got=%v", tc.expectedCode, err)
+			}
+			if tc.expectedID != "" && (got == nil || got.ID != tc.expectedID) {
+				t.Fatalf("expected id=%s, got=%v", tc.expectedID, got)
+			}
+			if tc.expectedID == "" && got != nil {
+				t.Fatalf("expected no identity, got=%v", got)
+			}
+			if anonymous != tc.expectedAnonymous {
+				t.Fatalf("expected anonymous=%t, got=%t", tc.expectedAnonymous, anonymous)
+			}
+		})
+	}
+
+	// without authenticators no caller can prove who they are
+	_, err := NewInterceptor(false).Unary()(context.Background(), nil, nil, func(ctx context.Context, req any) (any, error) {
+		if Anonymous(ctx) {
+			t.Fatalf("expected a request to a server without authenticators not to be anonymous")
+		}
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestInterceptorHTTP(t *testing.T) {
+	interceptor := NewInterceptor(true, NewAPIKeyAuthenticator(map[string]string{"s3cret": "alice"}))
+	handler := interceptor.HTTP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, _ := FromContext(r.Context())
+		w.Write([]byte(identity.ID))
+	}), func(w http.ResponseWriter, err error) {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Api-Key", "s3cret")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Body.String() != "alice" {
+		t.Fatalf("expected alice to be authenticated, got status=%d body=%s", rec.Code, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status=%d, got=%d", http.StatusUnauthorized, rec.Code)
+	}
+}
+
+func TestLoadAPIKeyAuthenticator(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys")
+	os.WriteFile(path, []byte("# identity key\nalice s3cret\n\nbob hunter2\n"), 0o600)
+
+	authenticator, err := LoadAPIKeyAuthenticator(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	identity, err := authenticator.Authenticate(withHeaders(APIKeyHeader, "hunter2"))
+	if err != nil || identity.ID != "bob" {
+		t.Fatalf("expected bob, got identity=%v err=%v", identity, err)
+	}
+
+	os.WriteFile(path, []byte("alice\n"), 0o600)
+	if _, err := LoadAPIKeyAuthenticator(path); err == nil {
+		t.Fatalf("expected a malformed line to fail")
+	}
+}
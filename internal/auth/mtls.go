@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// MTLSAuthenticator identifies callers by the client certificate they
+// presented. The certificate must already have been verified by the TLS
+// handshake against the server's client CAs.
+type MTLSAuthenticator struct {
+	identities map[string]string
+}
+
+// NewMTLSAuthenticator maps a certificate's SHA-256 fingerprint, or its
+// subject common name, to an identity. With no identities, the common name
+// is the identity.
+func NewMTLSAuthenticator(identities map[string]string) *MTLSAuthenticator {
+	return &MTLSAuthenticator{identities: identities}
+}
+
+// LoadMTLSAuthenticator reads the identities from a file of
+// "<fingerprint or common name> <identity>" lines. An empty path maps every
+// certificate to its common name.
+func LoadMTLSAuthenticator(path string) (*MTLSAuthenticator, error) {
+	if path == "" {
+		return NewMTLSAuthenticator(nil), nil
+	}
+
+	pairs, err := readPairs(path)
+	if err != nil {
+		return nil, err
+	}
+	identities := map[string]string{}
+	for _, pair := range pairs {
+		identities[pair[0]] = pair[1]
+	}
+	return NewMTLSAuthenticator(identities), nil
+}
+
+func (m *MTLSAuthenticator) Authenticate(ctx context.Context) (*Identity, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return nil, ErrNoCredentials
+	}
+
+	cert := tlsInfo.State.VerifiedChains[0][0]
+	commonName := cert.Subject.CommonName
+
+	if len(m.identities) == 0 {
+		if commonName == "" {
+			return nil, fmt.Errorf("client certificate has no common name")
+		}
+		return &Identity{ID: commonName, Method: "mtls"}, nil
+	}
+
+	fingerprint := sha256.Sum256(cert.Raw)
+	if id, ok := m.identities[hex.EncodeToString(fingerprint[:])]; ok {
+		return &Identity{ID: id, Method: "mtls"}, nil
+	}
+	if id, ok := m.identities[commonName]; ok && commonName != "" {
+		return &Identity{ID: id, Method: "mtls"}, nil
+	}
+	return nil, fmt.Errorf("client certificate %q is not mapped to an identity", commonName)
+}
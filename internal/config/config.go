@@ -0,0 +1,433 @@
+// Package config holds the server's settings. Every setting has a default,
+// and can be set from a YAML or TOML file, an environment variable, or a
+// flag, each overriding the one before.
+//
+// A setting's file key is its section and name, ex: tls.cert. Its
+// environment variable is the key in upper case, prefixed with CALCUROLLER_
+// and with dots as underscores, ex: CALCUROLLER_TLS_CERT.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+const envPrefix = "CALCUROLLER_"
+
+type Config struct {
+	Listen    Listen    `yaml:"listen" toml:"listen"`
+	TLS       TLS       `yaml:"tls" toml:"tls"`
+	Auth      Auth      `yaml:"auth" toml:"auth"`
+	History   History   `yaml:"history" toml:"history"`
+	Limits    Limits    `yaml:"limits" toml:"limits"`
+	RateLimit RateLimit `yaml:"rate_limit" toml:"rate_limit"`
+	RNG       RNG       `yaml:"rng" toml:"rng"`
+	Log       Log       `yaml:"log" toml:"log"`
+	Metrics   Metrics   `yaml:"metrics" toml:"metrics"`
+	Tracing   Tracing   `yaml:"tracing" toml:"tracing"`
+	Features  Features  `yaml:"features" toml:"features"`
+	Health    Health    `yaml:"health" toml:"health"`
+	Shutdown  Shutdown  `yaml:"shutdown" toml:"shutdown"`
+}
+
+type Listen struct {
+	GRPCAddress string `yaml:"grpc_address" toml:"grpc_address" flag:"grpc-address" usage:"address the gRPC server listens on"`
+	HTTPAddress string `yaml:"http_address" toml:"http_address" flag:"http-address" usage:"address the HTTP gateway listens on"`
+}
+
+type TLS struct {
+	Plaintext bool   `yaml:"plaintext" toml:"plaintext" flag:"plaintext" usage:"serve gRPC without TLS. for local development only"`
+	Cert      string `yaml:"cert" toml:"cert" flag:"tls-cert" usage:"the server's certificate"`
+	Key       string `yaml:"key" toml:"key" flag:"tls-key" usage:"the server's private key"`
+	ClientCA  string `yaml:"client_ca" toml:"client_ca" flag:"client-ca" usage:"CA certificates to verify client certificates with. enables mtls"`
+}
+
+type Auth struct {
+	Required         bool   `yaml:"required" toml:"required" flag:"auth-required" usage:"reject requests without credentials"`
+	ClientIdentities string `yaml:"client_identities" toml:"client_identities" flag:"client-identities" usage:"file of '<cert fingerprint or common name> <identity>' lines. defaults to the common name"`
+	APIKeys          string `yaml:"api_keys" toml:"api_keys" flag:"api-keys" usage:"file of '<identity> <api key>' lines. enables api keys"`
+	TokenSecret      string `yaml:"token_secret" toml:"token_secret" flag:"token-secret" usage:"file with the secret bearer tokens are signed with. enables tokens"`
+}
+
+type History struct {
+	Backend          string `yaml:"backend" toml:"backend" flag:"history" usage:"where rolls are recorded. one of memory, jsonl, sqlite. sqlite needs a build with cgo"`
+	Path             string `yaml:"path" toml:"path" flag:"history-path" usage:"the file the jsonl and sqlite history backends write to"`
+	MemoryMaxRecords int    `yaml:"memory_max_records" toml:"memory_max_records" flag:"history-memory-max-records" usage:"rolls the memory history backend keeps, dropping the oldest. 0 keeps every roll. jsonl and sqlite always keep every roll"`
+}
+
+type Limits struct {
+	MaxDiceStringLength int `yaml:"max_dice_string_length" toml:"max_dice_string_length" flag:"max-dice-string-length" usage:"longest dice string accepted, in bytes"`
+	MaxDice             int `yaml:"max_dice" toml:"max_dice" flag:"max-dice" usage:"most dice a single roll may roll"`
+	MaxDieSize          int `yaml:"max_die_size" toml:"max_die_size" flag:"max-die-size" usage:"most faces a die may have"`
+	MaxBatchSize        int `yaml:"max_batch_size" toml:"max_batch_size" flag:"max-batch-size" usage:"most requests accepted in a batch"`
+	BatchWorkers        int `yaml:"batch_workers" toml:"batch_workers" flag:"batch-workers" usage:"rolls evaluated concurrently per batch"`
+	MaxRequestBytes     int `yaml:"max_request_bytes" toml:"max_request_bytes" flag:"max-request-bytes" usage:"largest request body accepted by the HTTP gateway"`
+	SessionHistorySize  int `yaml:"session_history_size" toml:"session_history_size" flag:"session-history-size" usage:"events replayed to players joining a session table"`
+	SessionBufferSize   int `yaml:"session_buffer_size" toml:"session_buffer_size" flag:"session-buffer-size" usage:"events buffered per player before they are dropped from a table"`
+	ParseCacheSize      int `yaml:"parse_cache_size" toml:"parse_cache_size" flag:"parse-cache-size" usage:"dice strings kept parsed, so they aren't parsed again when rolled again"`
+}
+
+// RateLimit budgets are per caller: the identity a request was authenticated
+// as, or otherwise its address. Batches and stats are expensive and have a
+// budget of their own.
+type RateLimit struct {
+	Enabled        bool    `yaml:"enabled" toml:"enabled" flag:"rate-limit" usage:"limit how often each caller can call the server"`
+	Rate           float64 `yaml:"rate" toml:"rate" flag:"rate-limit-rate" usage:"calls per second each caller is allowed, sustained"`
+	Burst          int     `yaml:"burst" toml:"burst" flag:"rate-limit-burst" usage:"calls each caller is allowed at once"`
+	ExpensiveRate  float64 `yaml:"expensive_rate" toml:"expensive_rate" flag:"rate-limit-expensive-rate" usage:"batches and stats per second each caller is allowed, sustained"`
+	ExpensiveBurst int     `yaml:"expensive_burst" toml:"expensive_burst" flag:"rate-limit-expensive-burst" usage:"batches and stats each caller is allowed at once"`
+}
+
+const (
+	RNG_SEEDED = "seeded"
+	RNG_CRYPTO = "crypto"
+)
+
+type RNG struct {
+	Mode string `yaml:"mode" toml:"mode" flag:"rng" usage:"seeded rolls can be reproduced from their seed, crypto rolls can't be predicted. one of seeded, crypto"`
+}
+
+type Log struct {
+	Level  string `yaml:"level" toml:"level" flag:"log-level" usage:"one of debug, info, warn, error"`
+	Format string `yaml:"format" toml:"format" flag:"log-format" usage:"one of text, json"`
+}
+
+type Metrics struct {
+	Enabled bool   `yaml:"enabled" toml:"enabled" flag:"metrics" usage:"serve Prometheus metrics"`
+	Address string `yaml:"address" toml:"address" flag:"metrics-address" usage:"address /metrics is served on"`
+}
+
+type Tracing struct {
+	Exporter    string  `yaml:"exporter" toml:"exporter" flag:"tracing" usage:"where traces are exported to. one of none, stdout"`
+	SampleRatio float64 `yaml:"sample_ratio" toml:"sample_ratio" flag:"tracing-sample-ratio" usage:"fraction of new traces sampled, from 0 to 1"`
+}
+
+type Features struct {
+	HTTPGateway bool `yaml:"http_gateway" toml:"http_gateway" flag:"http-gateway" usage:"serve the HTTP/JSON gateway"`
+	Reflection  bool `yaml:"reflection" toml:"reflection" flag:"reflection" usage:"serve gRPC server reflection"`
+	Sessions    bool `yaml:"sessions" toml:"sessions" flag:"sessions" usage:"serve Roller.Session"`
+	Batch       bool `yaml:"batch" toml:"batch" flag:"batch" usage:"serve Roller.RollBatch"`
+	HistoryAPI  bool `yaml:"history_api" toml:"history_api" flag:"history-api" usage:"serve Roller.GetHistory, Roller.GetRoll and Roller.Stats"`
+}
+
+type Health struct {
+	CheckInterval time.Duration `yaml:"check_interval" toml:"check_interval" flag:"health-check-interval" usage:"how often the storage and rng health checks run"`
+}
+
+type Shutdown struct {
+	Timeout time.Duration `yaml:"timeout" toml:"timeout" flag:"shutdown-timeout" usage:"how long in-flight calls are given to finish on SIGTERM or SIGINT"`
+}
+
+func Default() *Config {
+	return &Config{
+		Listen: Listen{
+			GRPCAddress: ":8080",
+			HTTPAddress: ":8081",
+		},
+		History: History{
+			Backend:          "memory",
+			MemoryMaxRecords: 100000,
+		},
+		Limits: Limits{
+			MaxDiceStringLength: 1000,
+			MaxDice:             10000,
+			MaxDieSize:          1000000,
+			MaxBatchSize:        1000,
+			BatchWorkers:        8,
+			MaxRequestBytes:     1 << 20,
+			SessionHistorySize:  50,
+			SessionBufferSize:   64,
+			ParseCacheSize:      1000,
+		},
+		RateLimit: RateLimit{
+			Enabled:        true,
+			Rate:           10,
+			Burst:          20,
+			ExpensiveRate:  1,
+			ExpensiveBurst: 5,
+		},
+		RNG: RNG{Mode: RNG_SEEDED},
+		Log: Log{Level: "info", Format: "text"},
+		Metrics: Metrics{
+			Enabled: true,
+			Address: ":9090",
+		},
+		Tracing: Tracing{
+			Exporter:    "none",
+			SampleRatio: 1,
+		},
+		Features: Features{
+			HTTPGateway: true,
+			Reflection:  true,
+			Sessions:    true,
+			Batch:       true,
+			HistoryAPI:  true,
+		},
+		Health:   Health{CheckInterval: 10 * time.Second},
+		Shutdown: Shutdown{Timeout: 30 * time.Second},
+	}
+}
+
+// Load builds the config from the defaults, the file at path if it is not
+// empty, the environment, and the flags set on the command line, in that
+// order. The result is validated.
+func Load(path string, lookupEnv func(string) (string, bool), flags *Flags) (*Config, error) {
+	c := Default()
+
+	if path != "" {
+		if err := c.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, s := range c.settings() {
+		value, ok := lookupEnv(s.env)
+		if !ok {
+			continue
+		}
+		if err := s.set(value); err != nil {
+			return nil, fmt.Errorf("%s: %w", s.env, err)
+		}
+	}
+
+	if flags != nil {
+		for _, s := range c.settings() {
+			value, ok := flags.values[s.flag]
+			if !ok {
+				continue
+			}
+			if err := s.set(value); err != nil {
+				return nil, fmt.Errorf("--%s: %w", s.flag, err)
+			}
+		}
+	}
+
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// loadFile decodes a YAML or TOML file, picked by its extension. Unknown
+// keys are an error, since they are most likely typos.
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	switch ext := filepath.Ext(path); ext {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	case ".toml":
+		md, err := toml.Decode(string(data), c)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("%s: unknown keys %v", path, undecoded)
+		}
+	default:
+		return fmt.Errorf("%s: unknown config format %q. expected .yaml, .yml or .toml", path, ext)
+	}
+
+	return nil
+}
+
+// Validate checks every setting, returning all the problems found.
+func (c *Config) Validate() error {
+	errs := []error{}
+	check := func(ok bool, format string, a ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, a...))
+		}
+	}
+
+	for _, address := range []string{c.Listen.GRPCAddress, c.Listen.HTTPAddress, c.Metrics.Address} {
+		_, _, err := net.SplitHostPort(address)
+		check(err == nil, "invalid listen address %q: %v", address, err)
+	}
+	check(!c.Features.HTTPGateway || c.Listen.GRPCAddress != c.Listen.HTTPAddress, "the gRPC server and HTTP gateway can't both listen on %s", c.Listen.GRPCAddress)
+	check(!c.Metrics.Enabled || (c.Metrics.Address != c.Listen.GRPCAddress && (!c.Features.HTTPGateway || c.Metrics.Address != c.Listen.HTTPAddress)),
+		"metrics.address %s is already used by the gRPC server or HTTP gateway", c.Metrics.Address)
+
+	if c.TLS.Plaintext {
+		check(c.TLS.ClientCA == "", "tls.client_ca needs TLS, it can't be used with tls.plaintext")
+	} else {
+		check(c.TLS.Cert != "" && c.TLS.Key != "", "tls.cert and tls.key are needed unless tls.plaintext is set")
+	}
+
+	check(!c.Auth.Required || c.TLS.ClientCA != "" || c.Auth.APIKeys != "" || c.Auth.TokenSecret != "",
+		"auth.required needs tls.client_ca, auth.api_keys or auth.token_secret")
+
+	switch c.History.Backend {
+	case "memory":
+	case "jsonl", "sqlite":
+		check(c.History.Path != "", "history.path is needed by the %s backend", c.History.Backend)
+	default:
+		check(false, "unknown history.backend %q. expected one of memory, jsonl, sqlite", c.History.Backend)
+	}
+	check(c.History.MemoryMaxRecords >= 0, "history.memory_max_records must be at least 0, got=%d", c.History.MemoryMaxRecords)
+
+	for _, s := range c.settings() {
+		if strings.HasPrefix(s.key, "limits.") {
+			check(s.value.Int() > 0, "%s must be at least 1, got=%d", s.key, s.value.Int())
+		}
+	}
+
+	if c.RateLimit.Enabled {
+		check(c.RateLimit.Rate > 0, "rate_limit.rate must be more than 0, got=%v", c.RateLimit.Rate)
+		check(c.RateLimit.Burst > 0, "rate_limit.burst must be at least 1, got=%d", c.RateLimit.Burst)
+		check(c.RateLimit.ExpensiveRate > 0, "rate_limit.expensive_rate must be more than 0, got=%v", c.RateLimit.ExpensiveRate)
+		check(c.RateLimit.ExpensiveBurst > 0, "rate_limit.expensive_burst must be at least 1, got=%d", c.RateLimit.ExpensiveBurst)
+	}
+
+	check(c.RNG.Mode == RNG_SEEDED || c.RNG.Mode == RNG_CRYPTO, "unknown rng.mode %q. expected one of seeded, crypto", c.RNG.Mode)
+	check(slices.Contains([]string{"debug", "info", "warn", "error"}, c.Log.Level), "unknown log.level %q. expected one of debug, info, warn, error", c.Log.Level)
+	check(c.Log.Format == "text" || c.Log.Format == "json", "unknown log.format %q. expected one of text, json", c.Log.Format)
+	check(c.Tracing.Exporter == "none" || c.Tracing.Exporter == "stdout", "unknown tracing.exporter %q. expected one of none, stdout", c.Tracing.Exporter)
+	check(c.Health.CheckInterval > 0, "health.check_interval must be more than 0, got=%v", c.Health.CheckInterval)
+	check(c.Shutdown.Timeout > 0, "shutdown.timeout must be more than 0, got=%v", c.Shutdown.Timeout)
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio must be from 0 to 1, got=%v", c.Tracing.SampleRatio)
+
+	return errors.Join(errs...)
+}
+
+// YAML is the config as a config file.
+func (c *Config) YAML() ([]byte, error) {
+	return yaml.Marshal(c)
+}
+
+// Flags collects the settings given on the command line.
+type Flags struct {
+	values map[string]string
+}
+
+// RegisterFlags adds a flag for every setting to fs. Only flags that are
+// set override the config file and environment.
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	flags := &Flags{values: map[string]string{}}
+	for _, s := range Default().settings() {
+		fs.Var(&flagValue{flags: flags, setting: s}, s.flag, fmt.Sprintf("%s ($%s)", s.usage, s.env))
+	}
+	return flags
+}
+
+type flagValue struct {
+	flags   *Flags
+	setting setting
+}
+
+// String is the default shown by the flag package's usage message
+func (f *flagValue) String() string {
+	if f == nil || f.flags == nil {
+		return ""
+	}
+	return fmt.Sprint(f.setting.value.Interface())
+}
+
+func (f *flagValue) Set(value string) error {
+	if err := f.setting.check(value); err != nil {
+		return err
+	}
+	f.flags.values[f.setting.flag] = value
+	return nil
+}
+
+func (f *flagValue) IsBoolFlag() bool {
+	return f.setting.value.Kind() == reflect.Bool
+}
+
+// setting is a single field of the config
+type setting struct {
+	key   string
+	env   string
+	flag  string
+	usage string
+	value reflect.Value
+}
+
+func (c *Config) settings() []setting {
+	settings := []setting{}
+
+	sections := reflect.ValueOf(c).Elem()
+	for i := 0; i < sections.NumField(); i++ {
+		section := sections.Field(i)
+		sectionKey := sections.Type().Field(i).Tag.Get("yaml")
+
+		for j := 0; j < section.NumField(); j++ {
+			field := section.Type().Field(j)
+			key := sectionKey + "." + field.Tag.Get("yaml")
+			settings = append(settings, setting{
+				key:   key,
+				env:   envPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_")),
+				flag:  field.Tag.Get("flag"),
+				usage: field.Tag.Get("usage"),
+				value: section.Field(j),
+			})
+		}
+	}
+
+	return settings
+}
+
+func (s setting) set(value string) error {
+	if s.value.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("expected a duration, ex: 30s, got=%q", value)
+		}
+		s.value.SetInt(int64(d))
+		return nil
+	}
+
+	switch s.value.Kind() {
+	case reflect.String:
+		s.value.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("expected a boolean, got=%q", value)
+		}
+		s.value.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("expected an integer, got=%q", value)
+		}
+		s.value.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("expected a number, got=%q", value)
+		}
+		s.value.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported setting type %s", s.value.Kind())
+	}
+	return nil
+}
+
+// check reports whether value can be set, without setting it
+func (s setting) check(value string) error {
+	scratch := reflect.New(s.value.Type()).Elem()
+	return setting{value: scratch}.set(value)
+}
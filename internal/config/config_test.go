@@ -0,0 +1,200 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, name, contents string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("could not write %s: %v", path, err)
+	}
+	return path
+}
+
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := vars[key]
+		return value, ok
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	testCases := []struct {
+		name     string
+		file     string
+		contents string
+	}{
+		{"yaml", "config.yaml", `
+listen:
+  grpc_address: 0.0.0.0:9090
+tls:
+  plaintext: true
+history:
+  backend: jsonl
+  path: rolls.jsonl
+limits:
+  max_dice: 50
+rng:
+  mode: crypto
+features:
+  sessions: false
+shutdown:
+  timeout: 5s
+`},
+		{"toml", "config.toml", `
+[listen]
+grpc_address = "0.0.0.0:9090"
+
+[tls]
+plaintext = true
+
+[history]
+backend = "jsonl"
+path = "rolls.jsonl"
+
+[limits]
+max_dice = 50
+
+[rng]
+mode = "crypto"
+
+[features]
+sessions = false
+
+[shutdown]
+timeout = "5s"
+`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := Load(writeFile(t, tc.file, tc.contents), env(nil), nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			expected := Default()
+			expected.Listen.GRPCAddress = "0.0.0.0:9090"
+			expected.TLS.Plaintext = true
+			expected.History.Backend = "jsonl"
+			expected.History.Path = "rolls.jsonl"
+			expected.Limits.MaxDice = 50
+			expected.RNG.Mode = RNG_CRYPTO
+			expected.Features.Sessions = false
+			expected.Shutdown.Timeout = 5 * time.Second
+
+			if !reflect.DeepEqual(c, expected) {
+				t.Fatalf("expected=%+v, got=%+v", expected, c)
+			}
+		})
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeFile(t, "config.yaml", "tls:\n  plaintext: true\nlimits:\n  max_dice: 50\n  max_batch_size: 10\n  batch_workers: 2\n")
+	vars := map[string]string{
+		"CALCUROLLER_LIMITS_MAX_DICE":       "60",
+		"CALCUROLLER_LIMITS_MAX_BATCH_SIZE": "20",
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := RegisterFlags(fs)
+	if err := fs.Parse([]string{"--max-dice", "70"}); err != nil {
+		t.Fatalf("could not parse flags: %v", err)
+	}
+
+	c, err := Load(path, env(vars), flags)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if c.Limits.MaxDice != 70 || c.Limits.MaxBatchSize != 20 || c.Limits.BatchWorkers != 2 || c.Limits.SessionBufferSize != 64 {
+		t.Fatalf("expected flags over env over file over defaults, got=%+v", c.Limits)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		file     string
+		contents string
+		vars     map[string]string
+		args     []string
+		expected string
+	}{
+		{"no tls", "", "", nil, nil, "tls.cert and tls.key are needed"},
+		{"unknown yaml key", "config.yaml", "tls:\n  plaintxt: true\n", nil, nil, "field plaintxt not found"},
+		{"unknown toml key", "config.toml", "[tls]\nplaintxt = true\n", nil, nil, "unknown keys [tls.plaintxt]"},
+		{"unknown format", "config.json", "{}", nil, nil, "unknown config format"},
+		{"bad env value", "", "", map[string]string{"CALCUROLLER_TLS_PLAINTEXT": "yes please"}, nil, "CALCUROLLER_TLS_PLAINTEXT: expected a boolean"},
+		{"bad limit", "", "", map[string]string{"CALCUROLLER_TLS_PLAINTEXT": "true"}, []string{"--max-dice", "0"}, "limits.max_dice must be at least 1"},
+		{"bad history retention", "", "", map[string]string{"CALCUROLLER_TLS_PLAINTEXT": "true"}, []string{"--history-memory-max-records", "-1"}, "history.memory_max_records must be at least 0"},
+		{"bad address", "", "", map[string]string{"CALCUROLLER_TLS_PLAINTEXT": "true", "CALCUROLLER_LISTEN_GRPC_ADDRESS": "8080"}, nil, "invalid listen address"},
+		{"shared address", "", "", map[string]string{"CALCUROLLER_TLS_PLAINTEXT": "true", "CALCUROLLER_LISTEN_HTTP_ADDRESS": ":8080"}, nil, "can't both listen on :8080"},
+		{"mtls without tls", "", "", map[string]string{"CALCUROLLER_TLS_PLAINTEXT": "true", "CALCUROLLER_TLS_CLIENT_CA": "ca.pem"}, nil, "tls.client_ca needs TLS"},
+		{"auth without authenticators", "", "", map[string]string{"CALCUROLLER_TLS_PLAINTEXT": "true", "CALCUROLLER_AUTH_REQUIRED": "true"}, nil, "auth.required needs"},
+		{"history without path", "", "", map[string]string{"CALCUROLLER_TLS_PLAINTEXT": "true", "CALCUROLLER_HISTORY_BACKEND": "sqlite"}, nil, "history.path is needed"},
+		{"bad rate", "", "", map[string]string{"CALCUROLLER_TLS_PLAINTEXT": "true", "CALCUROLLER_RATE_LIMIT_RATE": "0"}, nil, "rate_limit.rate must be more than 0"},
+		{"bad float", "", "", map[string]string{"CALCUROLLER_TLS_PLAINTEXT": "true", "CALCUROLLER_RATE_LIMIT_EXPENSIVE_RATE": "fast"}, nil, "expected a number"},
+		{"shared metrics address", "", "", map[string]string{"CALCUROLLER_TLS_PLAINTEXT": "true", "CALCUROLLER_METRICS_ADDRESS": ":8081"}, nil, "metrics.address :8081 is already used"},
+		{"unknown exporter", "", "", map[string]string{"CALCUROLLER_TLS_PLAINTEXT": "true", "CALCUROLLER_TRACING_EXPORTER": "jaeger"}, nil, "unknown tracing.exporter"},
+		{"bad sample ratio", "", "", map[string]string{"CALCUROLLER_TLS_PLAINTEXT": "true"}, []string{"--tracing-sample-ratio", "2"}, "tracing.sample_ratio must be from 0 to 1"},
+		{"bad duration", "", "", map[string]string{"CALCUROLLER_TLS_PLAINTEXT": "true", "CALCUROLLER_SHUTDOWN_TIMEOUT": "30"}, nil, "expected a duration"},
+		{"zero duration", "", "", map[string]string{"CALCUROLLER_TLS_PLAINTEXT": "true"}, []string{"--health-check-interval", "0s"}, "health.check_interval must be more than 0"},
+		{"unknown rng", "", "", map[string]string{"CALCUROLLER_TLS_PLAINTEXT": "true", "CALCUROLLER_RNG_MODE": "dice"}, nil, "unknown rng.mode"},
+		{"unknown log level", "", "", map[string]string{"CALCUROLLER_TLS_PLAINTEXT": "true", "CALCUROLLER_LOG_LEVEL": "loud"}, nil, "unknown log.level"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := ""
+			if tc.file != "" {
+				path = writeFile(t, tc.file, tc.contents)
+			}
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			flags := RegisterFlags(fs)
+			if err := fs.Parse(tc.args); err != nil {
+				t.Fatalf("could not parse flags: %v", err)
+			}
+
+			_, err := Load(path, env(tc.vars), flags)
+			if err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Fatalf("expected an error containing %q, got=%v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestBadFlagValue(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(&strings.Builder{})
+	RegisterFlags(fs)
+	if err := fs.Parse([]string{"--max-dice", "lots"}); err == nil {
+		t.Fatalf("expected a bad flag value to fail parsing")
+	}
+}
+
+func TestYAMLRoundTrip(t *testing.T) {
+	c := Default()
+	c.TLS.Plaintext = true
+	c.Limits.MaxDice = 12
+
+	encoded, err := c.YAML()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	decoded, err := Load(writeFile(t, "config.yaml", string(encoded)), env(nil), nil)
+	if err != nil {
+		t.Fatalf("could not load the printed config: %v", err)
+	}
+	if !reflect.DeepEqual(c, decoded) {
+		t.Fatalf("expected=%+v, got=%+v", c, decoded)
+	}
+}
@@ -1,12 +1,210 @@
+// Package certs loads TLS certificates from disk at runtime. For local
+// development, gen.sh (make gen-certs) creates a CA and a server certificate
+// signed by it in this directory.
 package certs
 
-import _ "embed"
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
 
-//go:embed "ca-cert.pem"
-var CACertPEMBlock []byte
+// reloadInterval is how often, at most, the files are checked for changes
+const reloadInterval = 10 * time.Second
 
-//go:embed "server-key.pem"
-var ServerKeyPEMBlock []byte
+// Reloader serves the server certificate in certFile and keyFile, and
+// verifies client certificates against the CAs in clientCAFile when it is
+// set. The files are checked for changes during handshakes, so rotated
+// certificates are picked up without a restart.
+type Reloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
 
-//go:embed "server-cert.pem"
-var ServerCertPEMBlock []byte
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  map[string]time.Time
+	lastCheck time.Time
+	now       func() time.Time
+}
+
+func NewReloader(certFile, keyFile, clientCAFile string) (*Reloader, error) {
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("both a certificate and a key file are needed")
+	}
+
+	r := &Reloader{
+		certFile:     certFile,
+		keyFile:      keyFile,
+		clientCAFile: clientCAFile,
+		now:          time.Now,
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reads the files again. The previous certificates are kept if any
+// of the files can't be loaded.
+func (r *Reloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.reload()
+}
+
+func (r *Reloader) reload() error {
+	r.lastCheck = r.now()
+
+	modTimes, err := r.statFiles()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	var clientCAs *x509.CertPool
+	if r.clientCAFile != "" {
+		clientCAs, err = loadCertPool(r.clientCAFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.modTimes = modTimes
+	return nil
+}
+
+func (r *Reloader) statFiles() (map[string]time.Time, error) {
+	modTimes := map[string]time.Time{}
+	for _, path := range []string{r.certFile, r.keyFile, r.clientCAFile} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		modTimes[path] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+// reloadIfChanged reloads the files if any of them changed since they were
+// loaded. Failures are logged and the previous certificates kept, since a
+// rotation is often caught halfway through, ex: with the new certificate
+// written but not yet the new key.
+func (r *Reloader) reloadIfChanged() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.now().Sub(r.lastCheck) < reloadInterval {
+		return
+	}
+	r.lastCheck = r.now()
+
+	modTimes, err := r.statFiles()
+	if err != nil {
+		log.Printf("...could not check certificates for changes: %v", err)
+		return
+	}
+	for path, modTime := range modTimes {
+		if !modTime.Equal(r.modTimes[path]) {
+			if err := r.reload(); err != nil {
+				log.Printf("...could not reload certificates: %v", err)
+			}
+			return
+		}
+	}
+}
+
+// ServerConfig is a TLS config that always uses the latest certificates.
+func (r *Reloader) ServerConfig() *tls.Config {
+	return r.serverConfig("h2") // gRPC only speaks http/2
+}
+
+// HTTPServerConfig is ServerConfig for the HTTP gateway, which also serves
+// http/1.1 clients.
+func (r *Reloader) HTTPServerConfig() *tls.Config {
+	return r.serverConfig("h2", "http/1.1")
+}
+
+func (r *Reloader) serverConfig(nextProtos ...string) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return r.configForClient(nextProtos)
+		},
+	}
+}
+
+func (r *Reloader) configForClient(nextProtos []string) (*tls.Config, error) {
+	r.reloadIfChanged()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	config := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{*r.cert},
+		ClientAuth:   tls.NoClientCert,
+		NextProtos:   nextProtos,
+	}
+	// clients without a certificate can still connect, to authenticate some
+	// other way
+	if r.clientCAs != nil {
+		config.ClientCAs = r.clientCAs
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return config, nil
+}
+
+// ClientConfig is a TLS config for connecting to the server. The server's
+// certificate is verified against the CAs in caFile, or the system's CAs
+// when it is empty. certFile and keyFile are the optional client
+// certificate, for mtls.
+func ClientConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if caFile != "" {
+		rootCAs, err := loadCertPool(caFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = rootCAs
+	}
+
+	if certFile != "" {
+		clientCert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{clientCert}
+	}
+
+	return config, nil
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+	return pool, nil
+}
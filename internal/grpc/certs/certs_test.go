@@ -0,0 +1,319 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key := newTestKey(t)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("could not create ca: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key}
+}
+
+func newTestKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("could not generate key: %v", err)
+	}
+	return key
+}
+
+// writeCert signs a certificate for localhost and writes it and its key as
+// PEM files
+func (ca *testCA) writeCert(t *testing.T, commonName, certFile, keyFile string) {
+	key := newTestKey(t)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("could not create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("could not marshal key: %v", err)
+	}
+
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+}
+
+func (ca *testCA) writeCACert(t *testing.T, path string) {
+	writePEM(t, path, "CERTIFICATE", ca.cert.Raw)
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatalf("could not write %s: %v", path, err)
+	}
+}
+
+// handshake connects a client to the server config over loopback
+func handshake(serverConfig, clientConfig *tls.Config) (*tls.ConnectionState, error) {
+	lis, err := tls.Listen("tcp", "127.0.0.1:0", serverConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer lis.Close()
+
+	states := make(chan *tls.ConnectionState, 1)
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			states <- nil
+			return
+		}
+		defer conn.Close()
+		server := conn.(*tls.Conn)
+		if err := server.Handshake(); err != nil {
+			states <- nil
+			return
+		}
+		state := server.ConnectionState()
+		states <- &state
+	}()
+
+	client, err := tls.Dial("tcp", lis.Addr().String(), clientConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	// a rejected client certificate only fails the server's side
+	state := <-states
+	if state == nil {
+		return nil, fmt.Errorf("server handshake failed")
+	}
+	return state, nil
+}
+
+func TestHandshake(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	ca.writeCACert(t, filepath.Join(dir, "ca.pem"))
+	ca.writeCert(t, "server", filepath.Join(dir, "server.pem"), filepath.Join(dir, "server-key.pem"))
+	ca.writeCert(t, "alice", filepath.Join(dir, "client.pem"), filepath.Join(dir, "client-key.pem"))
+
+	reloader, err := NewReloader(filepath.Join(dir, "server.pem"), filepath.Join(dir, "server-key.pem"), filepath.Join(dir, "ca.pem"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	testCases := []struct {
+		name               string
+		certFile           string
+		keyFile            string
+		expectedClientName string
+	}{
+		{"without a client certificate", "", "", ""},
+		{"with a client certificate", filepath.Join(dir, "client.pem"), filepath.Join(dir, "client-key.pem"), "alice"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clientConfig, err := ClientConfig(filepath.Join(dir, "ca.pem"), tc.certFile, tc.keyFile)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			clientConfig.ServerName = "localhost"
+
+			state, err := handshake(reloader.ServerConfig(), clientConfig)
+			if err != nil {
+				t.Fatalf("handshake failed: %v", err)
+			}
+			if tc.expectedClientName == "" {
+				if len(state.VerifiedChains) != 0 {
+					t.Fatalf("expected no client certificate, got=%v", state.VerifiedChains)
+				}
+				return
+			}
+			if len(state.VerifiedChains) == 0 || state.VerifiedChains[0][0].Subject.CommonName != tc.expectedClientName {
+				t.Fatalf("expected a verified client certificate for %s, got=%v", tc.expectedClientName, state.VerifiedChains)
+			}
+		})
+	}
+}
+
+// the gateway's clients speak http/1.1 or http/2, gRPC's only http/2
+func TestHandshakeNextProtos(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	ca.writeCACert(t, filepath.Join(dir, "ca.pem"))
+	ca.writeCert(t, "server", filepath.Join(dir, "server.pem"), filepath.Join(dir, "server-key.pem"))
+
+	reloader, err := NewReloader(filepath.Join(dir, "server.pem"), filepath.Join(dir, "server-key.pem"), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	testCases := []struct {
+		name         string
+		serverConfig *tls.Config
+		clientProtos []string
+		expected     string
+	}{
+		{"gateway over http/1.1", reloader.HTTPServerConfig(), []string{"http/1.1"}, "http/1.1"},
+		{"gateway over http/2", reloader.HTTPServerConfig(), []string{"h2", "http/1.1"}, "h2"},
+		{"gRPC", reloader.ServerConfig(), []string{"h2"}, "h2"},
+		{"gRPC to an http/1.1 client", reloader.ServerConfig(), []string{"http/1.1"}, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clientConfig, err := ClientConfig(filepath.Join(dir, "ca.pem"), "", "")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			clientConfig.ServerName = "localhost"
+			clientConfig.NextProtos = tc.clientProtos
+
+			state, err := handshake(tc.serverConfig, clientConfig)
+			if err != nil {
+				t.Fatalf("handshake failed: %v", err)
+			}
+			if state.NegotiatedProtocol != tc.expected {
+				t.Fatalf("expected=%q, got=%q", tc.expected, state.NegotiatedProtocol)
+			}
+		})
+	}
+}
+
+func TestHandshakeUnknownCA(t *testing.T) {
+	dir := t.TempDir()
+	newTestCA(t).writeCert(t, "server", filepath.Join(dir, "server.pem"), filepath.Join(dir, "server-key.pem"))
+	newTestCA(t).writeCACert(t, filepath.Join(dir, "other-ca.pem"))
+
+	reloader, err := NewReloader(filepath.Join(dir, "server.pem"), filepath.Join(dir, "server-key.pem"), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	clientConfig, err := ClientConfig(filepath.Join(dir, "other-ca.pem"), "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	clientConfig.ServerName = "localhost"
+
+	if _, err := handshake(reloader.ServerConfig(), clientConfig); err == nil {
+		t.Fatalf("expected a server signed by another CA to be rejected")
+	}
+}
+
+func TestReloadIfChanged(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "server.pem"), filepath.Join(dir, "server-key.pem")
+	ca := newTestCA(t)
+	ca.writeCert(t, "first", certFile, keyFile)
+
+	reloader, err := NewReloader(certFile, keyFile, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	now := time.Now()
+	reloader.now = func() time.Time { return now }
+
+	servedName := func() string {
+		config, err := reloader.configForClient(nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		cert, err := x509.ParseCertificate(config.Certificates[0].Certificate[0])
+		if err != nil {
+			t.Fatalf("could not parse certificate: %v", err)
+		}
+		return cert.Subject.CommonName
+	}
+
+	// rotate the certificate, bumping the modification times so the test
+	// doesn't depend on the file system's timestamp resolution
+	rotate := func(commonName string, modTime time.Time) {
+		ca.writeCert(t, commonName, certFile, keyFile)
+		os.Chtimes(certFile, modTime, modTime)
+		os.Chtimes(keyFile, modTime, modTime)
+	}
+
+	rotate("second", now.Add(time.Minute))
+	if name := servedName(); name != "first" {
+		t.Fatalf("expected files not to be checked before the reload interval, got=%s", name)
+	}
+
+	now = now.Add(reloadInterval)
+	if name := servedName(); name != "second" {
+		t.Fatalf("expected the rotated certificate, got=%s", name)
+	}
+
+	// a half written rotation keeps the previous certificate
+	os.WriteFile(keyFile, []byte("not a key"), 0o600)
+	os.Chtimes(keyFile, now.Add(2*time.Minute), now.Add(2*time.Minute))
+	now = now.Add(reloadInterval)
+	if name := servedName(); name != "second" {
+		t.Fatalf("expected the previous certificate after a failed reload, got=%s", name)
+	}
+
+	rotate("third", now.Add(3*time.Minute))
+	now = now.Add(reloadInterval)
+	if name := servedName(); name != "third" {
+		t.Fatalf("expected the rotated certificate, got=%s", name)
+	}
+}
+
+func TestNewReloaderErrors(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "server.pem"), filepath.Join(dir, "server-key.pem")
+	newTestCA(t).writeCert(t, "server", certFile, keyFile)
+	os.WriteFile(filepath.Join(dir, "empty.pem"), []byte{}, 0o600)
+
+	testCases := []struct {
+		name         string
+		certFile     string
+		keyFile      string
+		clientCAFile string
+	}{
+		{"no files", "", "", ""},
+		{"missing key", certFile, filepath.Join(dir, "nope.pem"), ""},
+		{"missing client ca", certFile, keyFile, filepath.Join(dir, "nope.pem")},
+		{"empty client ca", certFile, keyFile, filepath.Join(dir, "empty.pem")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewReloader(tc.certFile, tc.keyFile, tc.clientCAFile); err == nil {
+				t.Fatalf("expected an error")
+			}
+		})
+	}
+}
@@ -1,105 +1,237 @@
 package main
 
 import (
-	"bufio"
-	"bytes"
 	"context"
-	"crypto/tls"
-	"crypto/x509"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"io"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/daneofmanythings/calcuroller/internal/grpc/certs"
 	pb "github.com/daneofmanythings/calcuroller/internal/grpc/proto"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/render"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
+// exit codes, so scripts can tell a bad dice string from a server that is
+// down
 const (
-	serverAddr string = "localhost:8080"
+	exitOK         = 0
+	exitRollFailed = 1 // the server rejected a dice string, or fmt could not format one
+	exitUsage      = 2 // the command line was invalid
+	exitRPCFailed  = 3 // a call failed, ex: the server is unavailable or the credentials are invalid
 )
 
+const defaultAddress = "localhost:8080"
+
+// usageError is an invalid command line
+type usageError struct {
+	message string
+}
+
+func (e *usageError) Error() string { return e.message }
+
+func usagef(format string, a ...any) error {
+	return &usageError{message: fmt.Sprintf(format, a...)}
+}
+
+// errRollFailed is returned once every dice string was sent, if any of them
+// were rejected
+var errRollFailed = errors.New("some dice strings were rejected")
+
+// cli is one run of the client. Commands write their results to stdout and
+// their errors to stderr.
+type cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(string) string
+	dial   func(address string, opts ...grpc.DialOption) (*grpc.ClientConn, error)
+
+	address   string
+	plaintext bool
+	caPath    string
+	certPath  string
+	keyPath   string
+	apiKey    string
+	token     string
+	timeout   time.Duration
+	callerID  string
+	json      bool
+	format    render.Format
+	explain   bool
+
+	conn   *grpc.ClientConn
+	client pb.RollerClient
+}
+
+type command struct {
+	summary string
+	run     func(c *cli, ctx context.Context, args []string) error
+	offline bool // runs without connecting to the server
+}
+
+var commands = map[string]command{
+	"roll":    {"roll dice strings given as arguments, read from files, or from stdin", (*cli).roll, false},
+	"batch":   {"roll dice strings in a single batch call", (*cli).batch, false},
+	"analyze": {"show the smallest, largest and average values of dice strings, without rolling them", (*cli).analyze, false},
+	"session": {"join a table, roll the dice strings read from stdin and print everyone's rolls", (*cli).session, false},
+	"history": {"list past rolls, newest first", (*cli).history, false},
+	"get":     {"show a past roll by its id", (*cli).get, false},
+	"stats":   {"show per caller dice statistics", (*cli).stats, false},
+	"ping":    {"check the server answers", (*cli).ping, false},
+	"health":  {"check the server's health, or one of its services'", (*cli).health, false},
+	"repl":    {"roll dice strings interactively", (*cli).repl, false},
+	"fmt":     {"rewrite dice strings in their canonical form, without calling the server", (*cli).fmt, true},
+}
+
 func main() {
-	tlsCredentials, err := loadTLSCredentials()
-	if err != nil {
-		log.Fatal("...could not load TLS credentials: ", err)
+	c := &cli{
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+		getenv: os.Getenv,
+		dial:   grpc.Dial,
 	}
+	os.Exit(c.run(os.Args[1:]))
+}
 
-	conn, err := grpc.Dial(
-		serverAddr,
-		grpc.WithTransportCredentials(tlsCredentials),
-	)
+// run parses the global flags, then runs the command after them. It returns
+// the exit code.
+func (c *cli) run(args []string) int {
+	fs := flag.NewFlagSet("calcuroller", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.StringVar(&c.address, "addr", envOr(c.getenv, "CALCUROLLER_ADDR", defaultAddress), "address of the server ($CALCUROLLER_ADDR)")
+	fs.BoolVar(&c.plaintext, "plaintext", false, "connect without TLS, to a server started with --plaintext")
+	fs.StringVar(&c.caPath, "ca", c.getenv("CALCUROLLER_CA"), "CA certificates to verify the server with. defaults to the system's CAs ($CALCUROLLER_CA)")
+	fs.StringVar(&c.certPath, "cert", "", "client certificate to authenticate with over mtls")
+	fs.StringVar(&c.keyPath, "key", "", "private key of the client certificate")
+	fs.StringVar(&c.apiKey, "api-key", c.getenv("CALCUROLLER_API_KEY"), "api key to authenticate with ($CALCUROLLER_API_KEY)")
+	fs.StringVar(&c.token, "token", c.getenv("CALCUROLLER_TOKEN"), "bearer token to authenticate with ($CALCUROLLER_TOKEN)")
+	fs.DurationVar(&c.timeout, "timeout", 10*time.Second, "how long to wait for each call")
+	fs.StringVar(&c.callerID, "caller", envOr(c.getenv, "CALCUROLLER_CALLER", c.getenv("USER")), "caller id to roll as, when not authenticated ($CALCUROLLER_CALLER)")
+	fs.BoolVar(&c.json, "json", false, "print responses as JSON, one per line")
+	fs.BoolVar(&c.explain, "explain", false, "print every step of how each roll's value was reached")
+	formatFlag := fs.String("format", string(render.TEXT), fmt.Sprintf("how rolls are printed, one of %v", render.Formats))
+	fs.Usage = func() { c.usage(fs) }
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	format, err := render.ParseFormat(*formatFlag)
 	if err != nil {
-		log.Fatalf("fail to dial: %v", err)
+		fmt.Fprintf(c.stderr, "calcuroller: %v\n", err)
+		return exitUsage
 	}
+	c.format = format
 
-	defer conn.Close()
+	if fs.NArg() == 0 {
+		c.usage(fs)
+		return exitUsage
+	}
+	name, cmd := fs.Arg(0), commands[fs.Arg(0)]
+	if cmd.run == nil {
+		fmt.Fprintf(c.stderr, "calcuroller: unknown command %q\n", name)
+		c.usage(fs)
+		return exitUsage
+	}
 
-	client := pb.NewRollerClient(conn)
+	if !cmd.offline {
+		if err := c.connect(); err != nil {
+			fmt.Fprintf(c.stderr, "calcuroller: %v\n", err)
+			return exitUsage
+		}
+		defer c.conn.Close()
+	}
 
-	runREPL(client)
+	return c.exitCode(name, cmd.run(c, c.outgoingContext(), fs.Args()[1:]))
 }
 
-func runREPL(client pb.RollerClient) {
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Println("Welcome to the calcuroller client REPL!")
-	fmt.Print("enter your name >> ")
-	callerId, err := reader.ReadString('\n')
-	if err != nil {
-		fmt.Printf("\nan error occurred reading input. err=%s", err)
+func (c *cli) usage(fs *flag.FlagSet) {
+	fmt.Fprintf(c.stderr, "usage: calcuroller [flags] <command> [command flags] [args]\n\ncommands:\n")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(c.stderr, "  %-8s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintf(c.stderr, "\nflags:\n")
+	fs.PrintDefaults()
+}
 
-	fmt.Print("(enter dice strings, ex: d4 + 1)\n\n")
-
-	for {
-		fmt.Print(">> ")
-		diceString, err := reader.ReadString('\n')
-		if err != nil {
-			fmt.Printf("\nan error occurred reading input. err=%s", err)
-			continue
+func (c *cli) exitCode(name string, err error) int {
+	var usageErr *usageError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, errRollFailed):
+		return exitRollFailed
+	case errors.As(err, &usageErr):
+		fmt.Fprintf(c.stderr, "calcuroller %s: %v\n", name, err)
+		return exitUsage
+	default:
+		if st, ok := status.FromError(err); ok {
+			fmt.Fprintf(c.stderr, "calcuroller %s: %s: %s\n", name, st.Code(), st.Message())
+		} else {
+			fmt.Fprintf(c.stderr, "calcuroller %s: %v\n", name, err)
 		}
-		response, err := client.Roll(context.Background(), &pb.RollRequest{
-			DiceString: diceString,
-			CallerId:   callerId,
-		})
+		return exitRPCFailed
+	}
+}
+
+func (c *cli) connect() error {
+	transportCredentials := insecure.NewCredentials()
+	if !c.plaintext {
+		tlsConfig, err := certs.ClientConfig(c.caPath, c.certPath, c.keyPath)
 		if err != nil {
-			log.Fatalf("Roll failed: err=%s", err)
-		}
-		switch response.Message.(type) {
-		case *pb.RollResponse_Data:
-			log.Println("Request string: " + response.GetData().GetRequestLiteral())
-			log.Printf("Value: %d\n", response.GetData().GetValue())
-			log.Println("Metadata:" + prettyStringifyMetadata(response.GetData().GetMetadata()))
-		case *pb.RollResponse_Status:
-			log.Println("(error) " + response.GetStatus().Message + "\n")
+			return fmt.Errorf("could not load TLS credentials: %w", err)
 		}
+		transportCredentials = credentials.NewTLS(tlsConfig)
 	}
-}
 
-func loadTLSCredentials() (credentials.TransportCredentials, error) {
-	certPool := x509.NewCertPool()
-	if !certPool.AppendCertsFromPEM(certs.CACertPEMBlock) {
-		return nil, fmt.Errorf("failed to add server CA's certificate")
+	conn, err := c.dial(c.address, grpc.WithTransportCredentials(transportCredentials))
+	if err != nil {
+		return fmt.Errorf("could not dial %s: %w", c.address, err)
 	}
+	c.conn = conn
+	c.client = pb.NewRollerClient(conn)
+	return nil
+}
 
-	// Create the credentials and return it
-	config := &tls.Config{
-		RootCAs: certPool,
+// outgoingContext carries the credentials on every call
+func (c *cli) outgoingContext() context.Context {
+	ctx := context.Background()
+	if c.apiKey != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-api-key", c.apiKey)
+	}
+	if c.token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+c.token)
 	}
+	return ctx
+}
 
-	return credentials.NewTLS(config), nil
+// call bounds a single unary call by the timeout
+func (c *cli) call(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, c.timeout)
 }
 
-func prettyStringifyMetadata(md []*pb.DiceRollMetadata) string {
-	var out bytes.Buffer
-	for _, data := range md {
-		out.WriteString("\n{\n" + data.ResponseLiteral + ": ")
-		out.WriteString(fmt.Sprintf("%d", data.Value) + "\n")
-		// TODO: make the metadata print prettier. low priority.
-		out.WriteString(data.String() + "\n}") // <- This is very ugly
+func envOr(getenv func(string) string, key, fallback string) string {
+	if value := strings.TrimSpace(getenv(key)); value != "" {
+		return value
 	}
-	out.WriteString("\n")
-
-	return out.String()
+	return fallback
 }
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	pb "github.com/daneofmanythings/calcuroller/internal/grpc/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// fakeRoller rolls every die as a 1, rejects the dice string "bad" and fails
+// every call when unavailable is set. Explained rolls have a single step, and
+// every dice string is analyzed as 1–6.
+type fakeRoller struct {
+	pb.UnimplementedRollerServer
+	unavailable bool
+	requests    []*pb.RollRequest
+	apiKeys     []string
+}
+
+func (f *fakeRoller) Roll(ctx context.Context, req *pb.RollRequest) (*pb.RollResponse, error) {
+	if f.unavailable {
+		return nil, status.Error(codes.Unavailable, "down for maintenance")
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	f.apiKeys = append(f.apiKeys, md.Get("x-api-key")...)
+	f.requests = append(f.requests, req)
+
+	if req.GetDiceString() == "bad" {
+		return &pb.RollResponse{Message: &pb.RollResponse_Status{Status: &pb.MyStatus{
+			Code:    int32(codes.InvalidArgument),
+			Message: "could not parse",
+		}}}, nil
+	}
+	data := &pb.RollData{
+		RequestLiteral: req.GetDiceString(),
+		Value:          1,
+		Rendered:       req.GetDiceString() + " = 1",
+	}
+	if req.GetExplain() {
+		data.Steps = []*pb.EvalStep{{Depth: 1, Kind: "DICE", Text: req.GetDiceString() + ": rolled 1"}}
+	}
+	return &pb.RollResponse{Message: &pb.RollResponse_Data{Data: data}}, nil
+}
+
+func (f *fakeRoller) Analyze(ctx context.Context, req *pb.AnalyzeRequest) (*pb.AnalyzeResponse, error) {
+	if req.GetDiceString() == "bad" {
+		return &pb.AnalyzeResponse{Message: &pb.AnalyzeResponse_Status{Status: &pb.MyStatus{
+			Code:    int32(codes.InvalidArgument),
+			Message: "could not parse",
+		}}}, nil
+	}
+	return &pb.AnalyzeResponse{Message: &pb.AnalyzeResponse_Data{Data: &pb.AnalyzeData{
+		RequestLiteral: req.GetDiceString(),
+		Min:            1,
+		Max:            6,
+		Mean:           3.5,
+		Summary:        "1–6, avg 3.5",
+	}}}, nil
+}
+
+func (f *fakeRoller) RollBatch(ctx context.Context, req *pb.RollBatchRequest) (*pb.RollBatchResponse, error) {
+	res := &pb.RollBatchResponse{}
+	for _, rollReq := range req.GetRequests() {
+		rollRes, err := f.Roll(ctx, rollReq)
+		if err != nil {
+			return nil, err
+		}
+		res.Responses = append(res.Responses, rollRes)
+	}
+	return res, nil
+}
+
+func (f *fakeRoller) GetHistory(ctx context.Context, req *pb.GetHistoryRequest) (*pb.GetHistoryResponse, error) {
+	// three pages of a single roll each
+	next := map[string]string{"": "2", "2": "3", "3": ""}
+	return &pb.GetHistoryResponse{
+		Records:       []*pb.RollRecord{{RollId: "roll-" + req.GetPageToken(), CallerId: req.GetCallerId()}},
+		NextPageToken: next[req.GetPageToken()],
+	}, nil
+}
+
+func runCLI(t *testing.T, server *fakeRoller, stdin string, args ...string) (int, string, string) {
+	lis := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	pb.RegisterRollerServer(grpcServer, server)
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	c := &cli{
+		stdin:  strings.NewReader(stdin),
+		stdout: stdout,
+		stderr: stderr,
+		getenv: func(key string) string {
+			return map[string]string{"CALCUROLLER_CALLER": "alice"}[key]
+		},
+		dial: func(address string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+			opts = append(opts, grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			}))
+			return grpc.Dial(address, opts...)
+		},
+	}
+	code := c.run(append([]string{"--plaintext"}, args...))
+	return code, stdout.String(), stderr.String()
+}
+
+func TestExitCodes(t *testing.T) {
+	testCases := []struct {
+		name         string
+		unavailable  bool
+		args         []string
+		expectedCode int
+	}{
+		{"roll", false, []string{"roll", "d6"}, exitOK},
+		{"rejected dice string", false, []string{"roll", "d6", "bad"}, exitRollFailed},
+		{"rejected in a batch", false, []string{"batch", "bad", "d6"}, exitRollFailed},
+		{"no command", false, []string{}, exitUsage},
+		{"unknown command", false, []string{"juggle"}, exitUsage},
+		{"unknown flag", false, []string{"--juggle", "roll", "d6"}, exitUsage},
+		{"unknown format", false, []string{"--format", "xml", "roll", "d6"}, exitUsage},
+		{"invalid time", false, []string{"history", "--since", "yesterday"}, exitUsage},
+		{"server unavailable", true, []string{"roll", "d6"}, exitRPCFailed},
+		{"unimplemented", false, []string{"ping"}, exitRPCFailed},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			code, _, stderr := runCLI(t, &fakeRoller{unavailable: tc.unavailable}, "", tc.args...)
+			if code != tc.expectedCode {
+				t.Fatalf("expected=%d, got=%d. stderr=%s", tc.expectedCode, code, stderr)
+			}
+		})
+	}
+}
+
+func TestRollKeepsGoing(t *testing.T) {
+	server := &fakeRoller{}
+	code, stdout, stderr := runCLI(t, server, "", "--api-key", "secret", "roll", "d4", "bad", "d8")
+	if code != exitRollFailed {
+		t.Fatalf("expected=%d, got=%d", exitRollFailed, code)
+	}
+	if stdout != "d4 = 1\nd8 = 1\n" {
+		t.Fatalf("expected both good rolls on stdout, got=%q", stdout)
+	}
+	if !strings.Contains(stderr, `"bad": could not parse`) {
+		t.Fatalf("expected the rejection on stderr, got=%q", stderr)
+	}
+	for _, req := range server.requests {
+		if req.GetCallerId() != "alice" {
+			t.Fatalf("expected caller=alice, got=%s", req.GetCallerId())
+		}
+	}
+	if len(server.apiKeys) != 3 || server.apiKeys[0] != "secret" {
+		t.Fatalf("expected the api key on every call, got=%v", server.apiKeys)
+	}
+}
+
+func TestRollReadsStdin(t *testing.T) {
+	testCases := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{"no arguments", []string{"roll"}, []string{"d4", "2d6 + 1"}},
+		{"dash", []string{"roll", "-f", "-"}, []string{"d4", "2d6 + 1"}},
+		{"arguments first", []string{"roll", "-f", "-", "d20"}, []string{"d20", "d4", "2d6 + 1"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := &fakeRoller{}
+			code, _, stderr := runCLI(t, server, "# a comment\nd4\n\n2d6 + 1\n", tc.args...)
+			if code != exitOK {
+				t.Fatalf("expected=%d, got=%d. stderr=%s", exitOK, code, stderr)
+			}
+			if len(server.requests) != len(tc.expected) {
+				t.Fatalf("expected=%d rolls, got=%d", len(tc.expected), len(server.requests))
+			}
+			for i, req := range server.requests {
+				if req.GetDiceString() != tc.expected[i] {
+					t.Fatalf("expected=%q, got=%q", tc.expected[i], req.GetDiceString())
+				}
+			}
+		})
+	}
+}
+
+func TestJSONOutput(t *testing.T) {
+	code, stdout, _ := runCLI(t, &fakeRoller{}, "", "--json", "roll", "d4", "d6")
+	if code != exitOK {
+		t.Fatalf("expected=%d, got=%d", exitOK, code)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected one line per roll, got=%q", stdout)
+	}
+	for _, line := range lines {
+		res := &pb.RollResponse{}
+		if err := protojson.Unmarshal([]byte(line), res); err != nil {
+			t.Fatalf("could not decode %q: %v", line, err)
+		}
+		if res.GetData().GetValue() != 1 {
+			t.Fatalf("expected value=1, got=%v", res)
+		}
+	}
+}
+
+func TestHistoryPages(t *testing.T) {
+	testCases := []struct {
+		limit    string
+		expected []string
+	}{
+		{"0", []string{"roll-", "roll-2", "roll-3"}},
+		{"2", []string{"roll-", "roll-2"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.limit, func(t *testing.T) {
+			code, stdout, _ := runCLI(t, &fakeRoller{}, "", "--json", "history", "--limit", tc.limit)
+			if code != exitOK {
+				t.Fatalf("expected=%d, got=%d", exitOK, code)
+			}
+			lines := strings.Split(strings.TrimSpace(stdout), "\n")
+			if len(lines) != len(tc.expected) {
+				t.Fatalf("expected=%d records, got=%q", len(tc.expected), stdout)
+			}
+			for i, line := range lines {
+				record := &pb.RollRecord{}
+				if err := protojson.Unmarshal([]byte(line), record); err != nil {
+					t.Fatalf("could not decode %q: %v", line, err)
+				}
+				if record.GetRollId() != tc.expected[i] {
+					t.Fatalf("expected=%s, got=%s", tc.expected[i], record.GetRollId())
+				}
+			}
+		})
+	}
+}
+
+func TestFmtIsOffline(t *testing.T) {
+	server := &fakeRoller{unavailable: true}
+	code, stdout, stderr := runCLI(t, server, "(d6kh3qu4)+ 2 *(3)\n", "fmt", "-f", "-", "d20", "1 +")
+	if code != exitRollFailed {
+		t.Fatalf("expected=%d, got=%d", exitRollFailed, code)
+	}
+	if stdout != "d20\nd6qu4kh3 + 2 * 3\n" {
+		t.Fatalf("expected the formatted dice string, got=%q", stdout)
+	}
+	if !strings.Contains(stderr, `"1 +": could not format`) {
+		t.Fatalf("expected the failure on stderr, got=%q", stderr)
+	}
+	if len(server.requests) != 0 {
+		t.Fatalf("expected no calls, got=%d", len(server.requests))
+	}
+}
+
+func TestRollExplain(t *testing.T) {
+	code, stdout, _ := runCLI(t, &fakeRoller{}, "", "--explain", "roll", "d4")
+	if code != exitOK {
+		t.Fatalf("expected=%d, got=%d", exitOK, code)
+	}
+	if stdout != "d4 = 1\n    d4: rolled 1\n" {
+		t.Fatalf("expected the steps under the roll, got=%q", stdout)
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	code, stdout, stderr := runCLI(t, &fakeRoller{}, "", "analyze", "d6", "bad", "d6 + 0")
+	if code != exitRollFailed {
+		t.Fatalf("expected=%d, got=%d", exitRollFailed, code)
+	}
+	if stdout != "d6: 1–6, avg 3.5\nd6 + 0: 1–6, avg 3.5\n" {
+		t.Fatalf("expected a line per dice string, got=%q", stdout)
+	}
+	if !strings.Contains(stderr, `"bad": could not parse`) {
+		t.Fatalf("expected the failure on stderr, got=%q", stderr)
+	}
+}
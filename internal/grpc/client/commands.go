@@ -0,0 +1,451 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	pb "github.com/daneofmanythings/calcuroller/internal/grpc/proto"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/repl"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (c *cli) roll(ctx context.Context, args []string) error {
+	diceStrings, err := c.diceStrings("roll", args)
+	if err != nil {
+		return err
+	}
+
+	failed := false
+	for _, diceString := range diceStrings {
+		callCtx, cancel := c.call(ctx)
+		res, err := c.client.Roll(callCtx, c.rollRequest(diceString))
+		cancel()
+		if err != nil {
+			return err
+		}
+		if !c.printRoll(diceString, res) {
+			failed = true
+		}
+	}
+
+	if failed {
+		return errRollFailed
+	}
+	return nil
+}
+
+func (c *cli) batch(ctx context.Context, args []string) error {
+	diceStrings, err := c.diceStrings("batch", args)
+	if err != nil {
+		return err
+	}
+
+	req := &pb.RollBatchRequest{}
+	for _, diceString := range diceStrings {
+		req.Requests = append(req.Requests, c.rollRequest(diceString))
+	}
+
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	res, err := c.client.RollBatch(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	failed := false
+	for i, rollRes := range res.GetResponses() {
+		if !c.printRoll(diceStrings[i], rollRes) {
+			failed = true
+		}
+	}
+
+	if failed {
+		return errRollFailed
+	}
+	return nil
+}
+
+// analyze prints the bounds of every dice string, a line each, ex:
+// d6qu3: 3–18, avg 10.5
+func (c *cli) analyze(ctx context.Context, args []string) error {
+	diceStrings, err := c.diceStrings("analyze", args)
+	if err != nil {
+		return err
+	}
+
+	failed := false
+	for _, diceString := range diceStrings {
+		callCtx, cancel := c.call(ctx)
+		res, err := c.client.Analyze(callCtx, &pb.AnalyzeRequest{DiceString: diceString})
+		cancel()
+		if err != nil {
+			return err
+		}
+		if !c.printAnalysis(diceString, res) {
+			failed = true
+		}
+	}
+
+	if failed {
+		return errRollFailed
+	}
+	return nil
+}
+
+func (c *cli) session(ctx context.Context, args []string) error {
+	fs := c.flagSet("session")
+	tableID := fs.String("table", "", "the table to join")
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+	if *tableID == "" {
+		return usagef("--table is required")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.client.Session(ctx)
+	if err != nil {
+		return err
+	}
+	join := &pb.SessionJoin{TableId: *tableID, CallerId: c.callerID}
+	if err := stream.Send(&pb.SessionRequest{Message: &pb.SessionRequest_Join{Join: join}}); err != nil {
+		return err
+	}
+
+	// rolls are sent as they are read, while everyone's rolls are printed as
+	// they arrive
+	sendErrs := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(c.stdin)
+		for scanner.Scan() {
+			diceString := strings.TrimSpace(scanner.Text())
+			if diceString == "" {
+				continue
+			}
+			roll := &pb.SessionRequest_Roll{Roll: c.rollRequest(diceString)}
+			if err := stream.Send(&pb.SessionRequest{Message: roll}); err != nil {
+				sendErrs <- err
+				return
+			}
+		}
+		sendErrs <- stream.CloseSend()
+	}()
+
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			return <-sendErrs
+		}
+		if err != nil {
+			return err
+		}
+		c.printEvent(event)
+	}
+}
+
+func (c *cli) history(ctx context.Context, args []string) error {
+	fs := c.flagSet("history")
+	callerID := fs.String("caller", "", "only rolls made by this caller")
+	tableID := fs.String("table", "", "only rolls made at this session table")
+	since := fs.String("since", "", "only rolls made at or after this time, RFC 3339 or a duration ago, ex: 24h")
+	until := fs.String("until", "", "only rolls made before this time, RFC 3339 or a duration ago")
+	limit := fs.Int("limit", 50, "most rolls to list. 0 lists them all")
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+
+	req := &pb.GetHistoryRequest{CallerId: *callerID, TableId: *tableID}
+	var err error
+	if req.Since, err = parseTime(*since); err != nil {
+		return usagef("--since: %v", err)
+	}
+	if req.Until, err = parseTime(*until); err != nil {
+		return usagef("--until: %v", err)
+	}
+
+	listed := 0
+	for {
+		req.PageSize = 100
+		if *limit > 0 {
+			req.PageSize = int32(min(100, *limit-listed))
+		}
+
+		callCtx, cancel := c.call(ctx)
+		res, err := c.client.GetHistory(callCtx, req)
+		cancel()
+		if err != nil {
+			return err
+		}
+
+		for _, record := range res.GetRecords() {
+			c.printRecord(record)
+		}
+		listed += len(res.GetRecords())
+
+		if res.GetNextPageToken() == "" || (*limit > 0 && listed >= *limit) {
+			return nil
+		}
+		req.PageToken = res.GetNextPageToken()
+	}
+}
+
+func (c *cli) get(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return usagef("expected a roll id, got %d arguments", len(args))
+	}
+
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	record, err := c.client.GetRoll(ctx, &pb.GetRollRequest{RollId: args[0]})
+	if err != nil {
+		return err
+	}
+
+	c.printRecord(record)
+	return nil
+}
+
+func (c *cli) stats(ctx context.Context, args []string) error {
+	fs := c.flagSet("stats")
+	callerID := fs.String("caller", "", "only rolls made by this caller")
+	tableID := fs.String("table", "", "only rolls made at this session table")
+	since := fs.String("since", "", "only rolls made at or after this time, RFC 3339 or a duration ago, ex: 24h")
+	until := fs.String("until", "", "only rolls made before this time, RFC 3339 or a duration ago")
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+
+	req := &pb.StatsRequest{CallerId: *callerID, TableId: *tableID}
+	var err error
+	if req.Since, err = parseTime(*since); err != nil {
+		return usagef("--since: %v", err)
+	}
+	if req.Until, err = parseTime(*until); err != nil {
+		return usagef("--until: %v", err)
+	}
+
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	res, err := c.client.Stats(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	c.printStats(res)
+	return nil
+}
+
+func (c *cli) ping(ctx context.Context, args []string) error {
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	res, err := c.client.Ping(ctx, &pb.PingRequest{})
+	if err != nil {
+		return err
+	}
+
+	if c.json {
+		c.printJSON(res)
+	} else {
+		fmt.Fprintln(c.stdout, res.GetPing())
+	}
+	return nil
+}
+
+func (c *cli) health(ctx context.Context, args []string) error {
+	if len(args) > 1 {
+		return usagef("expected at most one service, got %d arguments", len(args))
+	}
+	service := ""
+	if len(args) == 1 {
+		service = args[0]
+	}
+
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	res, err := healthpb.NewHealthClient(c.conn).Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		return err
+	}
+
+	if c.json {
+		c.printJSON(res)
+	} else {
+		fmt.Fprintln(c.stdout, res.GetStatus())
+	}
+	if res.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("%s is %s", orDefault(service, "the server"), res.GetStatus())
+	}
+	return nil
+}
+
+func (c *cli) repl(ctx context.Context, args []string) error {
+	fmt.Fprintln(c.stdout, "Welcome to the calcuroller client REPL!")
+	fmt.Fprint(c.stdout, "(enter dice strings, ex: d4 + 1)\n\n")
+
+	scanner := bufio.NewScanner(c.stdin)
+	for {
+		fmt.Fprint(c.stdout, ">> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(c.stdout)
+			return scanner.Err()
+		}
+		diceString := strings.TrimSpace(scanner.Text())
+		if diceString == "" {
+			continue
+		}
+
+		callCtx, cancel := c.call(ctx)
+		res, err := c.client.Roll(callCtx, c.rollRequest(diceString))
+		cancel()
+		if err != nil {
+			// the server may be back by the next roll
+			fmt.Fprintf(c.stderr, "(error) %s\n", status.Convert(err).Message())
+			continue
+		}
+		c.printRoll(diceString, res)
+		fmt.Fprintln(c.stdout)
+	}
+}
+
+// fmt prints every dice string in its canonical form, a line each. Dice
+// strings that don't parse are reported to stderr, and the rest are still
+// formatted.
+func (c *cli) fmt(ctx context.Context, args []string) error {
+	diceStrings, err := c.diceStrings("fmt", args)
+	if err != nil {
+		return err
+	}
+
+	failed := false
+	for _, diceString := range diceStrings {
+		formatted, err := repl.Format(diceString)
+		if err != nil {
+			fmt.Fprintf(c.stderr, "calcuroller: %q: %v\n", diceString, err)
+			failed = true
+			continue
+		}
+		fmt.Fprintln(c.stdout, formatted)
+	}
+
+	if failed {
+		return errRollFailed
+	}
+	return nil
+}
+
+func (c *cli) rollRequest(diceString string) *pb.RollRequest {
+	return &pb.RollRequest{
+		DiceString: diceString,
+		CallerId:   c.callerID,
+		Format:     string(c.format),
+		Explain:    c.explain,
+	}
+}
+
+// diceStrings are the dice strings given as arguments and in the files given
+// with -f, or read from stdin if there are none. Blank lines and lines
+// starting with # are skipped.
+func (c *cli) diceStrings(name string, args []string) ([]string, error) {
+	fs := c.flagSet(name)
+	var files stringsFlag
+	fs.Var(&files, "f", "file of dice strings, one per line. - reads stdin. can be repeated")
+	if err := c.parse(fs, args); err != nil {
+		return nil, err
+	}
+
+	diceStrings := fs.Args()
+	if len(diceStrings) == 0 && len(files) == 0 {
+		files = append(files, "-")
+	}
+	for _, path := range files {
+		lines, err := c.readLines(path)
+		if err != nil {
+			return nil, usagef("%v", err)
+		}
+		diceStrings = append(diceStrings, lines...)
+	}
+
+	if len(diceStrings) == 0 {
+		return nil, usagef("no dice strings to roll")
+	}
+	return diceStrings, nil
+}
+
+func (c *cli) readLines(path string) ([]string, error) {
+	r := c.stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		r = file
+	}
+
+	lines := []string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+func (c *cli) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("calcuroller "+name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	return fs
+}
+
+// parse parses a command's flags. Errors are already printed by the flag
+// package, along with the usage.
+func (c *cli) parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return usagef("%v", err)
+	}
+	return nil
+}
+
+// stringsFlag collects every value of a repeated flag
+type stringsFlag []string
+
+func (s *stringsFlag) String() string { return strings.Join(*s, ",") }
+
+func (s *stringsFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// parseTime reads an RFC 3339 time, or a duration meaning that long ago.
+func parseTime(value string) (*timestamppb.Timestamp, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return timestamppb.New(time.Now().Add(-d)), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("expected an RFC 3339 time or a duration, got=%q", value)
+	}
+	return timestamppb.New(t), nil
+}
+
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	pb "github.com/daneofmanythings/calcuroller/internal/grpc/proto"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// the same encoding as the gateway's response bodies
+var jsonMarshaler = protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}
+
+// printJSON prints a message on a single line
+func (c *cli) printJSON(m proto.Message) {
+	data, err := jsonMarshaler.Marshal(m)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "calcuroller: could not encode the response: %v\n", err)
+		return
+	}
+	fmt.Fprintln(c.stdout, string(data))
+}
+
+// printRoll prints a roll's result, or why the dice string was rejected to
+// stderr. It reports whether the roll succeeded.
+func (c *cli) printRoll(diceString string, res *pb.RollResponse) bool {
+	if c.json {
+		c.printJSON(res)
+	}
+
+	switch res.Message.(type) {
+	case *pb.RollResponse_Data:
+		if !c.json {
+			fmt.Fprintln(c.stdout, res.GetData().GetRendered())
+			c.printSteps(res.GetData().GetSteps())
+		}
+		return true
+	default:
+		fmt.Fprintf(c.stderr, "calcuroller: %q: %s\n", diceString, res.GetStatus().GetMessage())
+		return false
+	}
+}
+
+// printAnalysis prints a dice string's bounds, or why it was rejected to
+// stderr. It reports whether the dice string was analyzed.
+func (c *cli) printAnalysis(diceString string, res *pb.AnalyzeResponse) bool {
+	if c.json {
+		c.printJSON(res)
+	}
+
+	switch res.Message.(type) {
+	case *pb.AnalyzeResponse_Data:
+		if !c.json {
+			fmt.Fprintf(c.stdout, "%s: %s\n", diceString, res.GetData().GetSummary())
+		}
+		return true
+	default:
+		fmt.Fprintf(c.stderr, "calcuroller: %q: %s\n", diceString, res.GetStatus().GetMessage())
+		return false
+	}
+}
+
+// printSteps prints how a roll's value was reached, nested like its dice
+// string
+func (c *cli) printSteps(steps []*pb.EvalStep) {
+	for _, step := range steps {
+		fmt.Fprintf(c.stdout, "  %s%s\n", strings.Repeat("  ", int(step.GetDepth())), step.GetText())
+	}
+}
+
+func (c *cli) printEvent(event *pb.SessionEvent) {
+	if c.json {
+		c.printJSON(event)
+		return
+	}
+
+	prefix := event.GetCallerId()
+	if event.GetHistory() {
+		prefix += " (earlier)"
+	}
+	switch event.GetRoll().Message.(type) {
+	case *pb.RollResponse_Data:
+		fmt.Fprintf(c.stdout, "%s: %s\n", prefix, event.GetRoll().GetData().GetRendered())
+		c.printSteps(event.GetRoll().GetData().GetSteps())
+	default:
+		fmt.Fprintf(c.stdout, "%s: (error) %s\n", prefix, event.GetRoll().GetStatus().GetMessage())
+	}
+}
+
+func (c *cli) printRecord(record *pb.RollRecord) {
+	if c.json {
+		c.printJSON(record)
+		return
+	}
+
+	data := record.GetRoll().GetData()
+	result := data.GetRendered()
+	if result == "" {
+		// the history doesn't keep how the roll was rendered
+		result = fmt.Sprintf("%s = %d", data.GetRequestLiteral(), data.GetValue())
+	}
+	if status := record.GetRoll().GetStatus(); status != nil {
+		result = "(error) " + status.GetMessage()
+	}
+	fmt.Fprintf(c.stdout, "%s  %s  %s", record.GetRollId(), record.GetTimestamp().AsTime().Local().Format(time.DateTime), record.GetCallerId())
+	if record.GetTableId() != "" {
+		fmt.Fprintf(c.stdout, " @ %s", record.GetTableId())
+	}
+	fmt.Fprintf(c.stdout, "\n  %s\n", strings.ReplaceAll(result, "\n", "\n  "))
+}
+
+func (c *cli) printStats(res *pb.StatsResponse) {
+	if c.json {
+		c.printJSON(res)
+		return
+	}
+
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CALLER\tROLLS\tLUCK\tDIE\tMEAN\tEXPECTED\tCRITS\tFUMBLES\tP-VALUE")
+	callers := res.GetCallers()
+	if res.GetTotal() != nil {
+		callers = append(callers, res.GetTotal())
+	}
+	for _, caller := range callers {
+		name := caller.GetCallerId()
+		if caller == res.GetTotal() {
+			name = "(total)"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%+.2f\t\t\t\t\t\t\n", name, caller.GetRolls(), caller.GetLuck())
+		for _, die := range caller.GetDice() {
+			fmt.Fprintf(w, "\t%d\t%+.2f\td%d\t%.2f\t%.2f\t%.1f%%\t%.1f%%\t%.3f\n",
+				die.GetRolls(), die.GetLuck(), die.GetSize(), die.GetMean(), die.GetExpectedMean(),
+				100*die.GetCritRate(), 100*die.GetFumbleRate(), die.GetPValue())
+		}
+	}
+	w.Flush()
+}
@@ -24,6 +24,20 @@ const _ = grpc.SupportPackageIsVersion7
 type RollerClient interface {
 	Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error)
 	Roll(ctx context.Context, in *RollRequest, opts ...grpc.CallOption) (*RollResponse, error)
+	// Rolls every request concurrently. Responses are in the order of the
+	// requests, and a bad request only fails its own response.
+	RollBatch(ctx context.Context, in *RollBatchRequest, opts ...grpc.CallOption) (*RollBatchResponse, error)
+	// The first message of a session must join a table. Every roll sent after
+	// that is broadcast to everyone at the table.
+	Session(ctx context.Context, opts ...grpc.CallOption) (Roller_SessionClient, error)
+	// Lists recorded rolls, newest first.
+	GetHistory(ctx context.Context, in *GetHistoryRequest, opts ...grpc.CallOption) (*GetHistoryResponse, error)
+	GetRoll(ctx context.Context, in *GetRollRequest, opts ...grpc.CallOption) (*RollRecord, error)
+	// Summarizes the dice rolled in the history, per caller and die size.
+	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error)
+	// The smallest and largest values a dice string can roll, and its average,
+	// without rolling it.
+	Analyze(ctx context.Context, in *AnalyzeRequest, opts ...grpc.CallOption) (*AnalyzeResponse, error)
 }
 
 type rollerClient struct {
@@ -52,12 +66,102 @@ func (c *rollerClient) Roll(ctx context.Context, in *RollRequest, opts ...grpc.C
 	return out, nil
 }
 
+func (c *rollerClient) RollBatch(ctx context.Context, in *RollBatchRequest, opts ...grpc.CallOption) (*RollBatchResponse, error) {
+	out := new(RollBatchResponse)
+	err := c.cc.Invoke(ctx, "/google.rpc.Roller/RollBatch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rollerClient) Session(ctx context.Context, opts ...grpc.CallOption) (Roller_SessionClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Roller_ServiceDesc.Streams[0], "/google.rpc.Roller/Session", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &rollerSessionClient{stream}
+	return x, nil
+}
+
+type Roller_SessionClient interface {
+	Send(*SessionRequest) error
+	Recv() (*SessionEvent, error)
+	grpc.ClientStream
+}
+
+type rollerSessionClient struct {
+	grpc.ClientStream
+}
+
+func (x *rollerSessionClient) Send(m *SessionRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *rollerSessionClient) Recv() (*SessionEvent, error) {
+	m := new(SessionEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *rollerClient) GetHistory(ctx context.Context, in *GetHistoryRequest, opts ...grpc.CallOption) (*GetHistoryResponse, error) {
+	out := new(GetHistoryResponse)
+	err := c.cc.Invoke(ctx, "/google.rpc.Roller/GetHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rollerClient) GetRoll(ctx context.Context, in *GetRollRequest, opts ...grpc.CallOption) (*RollRecord, error) {
+	out := new(RollRecord)
+	err := c.cc.Invoke(ctx, "/google.rpc.Roller/GetRoll", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rollerClient) Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error) {
+	out := new(StatsResponse)
+	err := c.cc.Invoke(ctx, "/google.rpc.Roller/Stats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rollerClient) Analyze(ctx context.Context, in *AnalyzeRequest, opts ...grpc.CallOption) (*AnalyzeResponse, error) {
+	out := new(AnalyzeResponse)
+	err := c.cc.Invoke(ctx, "/google.rpc.Roller/Analyze", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // RollerServer is the server API for Roller service.
 // All implementations must embed UnimplementedRollerServer
 // for forward compatibility
 type RollerServer interface {
 	Ping(context.Context, *PingRequest) (*PingResponse, error)
 	Roll(context.Context, *RollRequest) (*RollResponse, error)
+	// Rolls every request concurrently. Responses are in the order of the
+	// requests, and a bad request only fails its own response.
+	RollBatch(context.Context, *RollBatchRequest) (*RollBatchResponse, error)
+	// The first message of a session must join a table. Every roll sent after
+	// that is broadcast to everyone at the table.
+	Session(Roller_SessionServer) error
+	// Lists recorded rolls, newest first.
+	GetHistory(context.Context, *GetHistoryRequest) (*GetHistoryResponse, error)
+	GetRoll(context.Context, *GetRollRequest) (*RollRecord, error)
+	// Summarizes the dice rolled in the history, per caller and die size.
+	Stats(context.Context, *StatsRequest) (*StatsResponse, error)
+	// The smallest and largest values a dice string can roll, and its average,
+	// without rolling it.
+	Analyze(context.Context, *AnalyzeRequest) (*AnalyzeResponse, error)
 	mustEmbedUnimplementedRollerServer()
 }
 
@@ -71,6 +175,24 @@ func (UnimplementedRollerServer) Ping(context.Context, *PingRequest) (*PingRespo
 func (UnimplementedRollerServer) Roll(context.Context, *RollRequest) (*RollResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Roll not implemented")
 }
+func (UnimplementedRollerServer) RollBatch(context.Context, *RollBatchRequest) (*RollBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RollBatch not implemented")
+}
+func (UnimplementedRollerServer) Session(Roller_SessionServer) error {
+	return status.Errorf(codes.Unimplemented, "method Session not implemented")
+}
+func (UnimplementedRollerServer) GetHistory(context.Context, *GetHistoryRequest) (*GetHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHistory not implemented")
+}
+func (UnimplementedRollerServer) GetRoll(context.Context, *GetRollRequest) (*RollRecord, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRoll not implemented")
+}
+func (UnimplementedRollerServer) Stats(context.Context, *StatsRequest) (*StatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stats not implemented")
+}
+func (UnimplementedRollerServer) Analyze(context.Context, *AnalyzeRequest) (*AnalyzeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Analyze not implemented")
+}
 func (UnimplementedRollerServer) mustEmbedUnimplementedRollerServer() {}
 
 // UnsafeRollerServer may be embedded to opt out of forward compatibility for this service.
@@ -120,6 +242,122 @@ func _Roller_Roll_Handler(srv interface{}, ctx context.Context, dec func(interfa
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Roller_RollBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RollBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RollerServer).RollBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.rpc.Roller/RollBatch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RollerServer).RollBatch(ctx, req.(*RollBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Roller_Session_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(RollerServer).Session(&rollerSessionServer{stream})
+}
+
+type Roller_SessionServer interface {
+	Send(*SessionEvent) error
+	Recv() (*SessionRequest, error)
+	grpc.ServerStream
+}
+
+type rollerSessionServer struct {
+	grpc.ServerStream
+}
+
+func (x *rollerSessionServer) Send(m *SessionEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *rollerSessionServer) Recv() (*SessionRequest, error) {
+	m := new(SessionRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Roller_GetHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RollerServer).GetHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.rpc.Roller/GetHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RollerServer).GetHistory(ctx, req.(*GetHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Roller_GetRoll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRollRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RollerServer).GetRoll(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.rpc.Roller/GetRoll",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RollerServer).GetRoll(ctx, req.(*GetRollRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Roller_Stats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RollerServer).Stats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.rpc.Roller/Stats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RollerServer).Stats(ctx, req.(*StatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Roller_Analyze_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AnalyzeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RollerServer).Analyze(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.rpc.Roller/Analyze",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RollerServer).Analyze(ctx, req.(*AnalyzeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Roller_ServiceDesc is the grpc.ServiceDesc for Roller service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -135,7 +373,34 @@ var Roller_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Roll",
 			Handler:    _Roller_Roll_Handler,
 		},
+		{
+			MethodName: "RollBatch",
+			Handler:    _Roller_RollBatch_Handler,
+		},
+		{
+			MethodName: "GetHistory",
+			Handler:    _Roller_GetHistory_Handler,
+		},
+		{
+			MethodName: "GetRoll",
+			Handler:    _Roller_GetRoll_Handler,
+		},
+		{
+			MethodName: "Stats",
+			Handler:    _Roller_Stats_Handler,
+		},
+		{
+			MethodName: "Analyze",
+			Handler:    _Roller_Analyze_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Session",
+			Handler:       _Roller_Session_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "internal/grpc/proto/roller.proto",
 }
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/daneofmanythings/calcuroller/internal/auth"
+	"github.com/daneofmanythings/calcuroller/internal/config"
+	"github.com/daneofmanythings/calcuroller/internal/telemetry"
+)
+
+// newAuthInterceptor builds the authenticators enabled in the config. mtls
+// is enabled by verifying client certificates against clientCA. With none
+// enabled, every request is anonymous.
+func newAuthInterceptor(conf config.Auth, clientCA string) (*auth.Interceptor, error) {
+	authenticators := []auth.Authenticator{}
+
+	if clientCA != "" {
+		mtls, err := auth.LoadMTLSAuthenticator(conf.ClientIdentities)
+		if err != nil {
+			return nil, fmt.Errorf("could not load client identities: %w", err)
+		}
+		authenticators = append(authenticators, mtls)
+	}
+	if conf.APIKeys != "" {
+		apiKeys, err := auth.LoadAPIKeyAuthenticator(conf.APIKeys)
+		if err != nil {
+			return nil, fmt.Errorf("could not load api keys: %w", err)
+		}
+		authenticators = append(authenticators, apiKeys)
+	}
+	if conf.TokenSecret != "" {
+		tokens, err := auth.LoadTokenAuthenticator(conf.TokenSecret)
+		if err != nil {
+			return nil, fmt.Errorf("could not load token secret: %w", err)
+		}
+		authenticators = append(authenticators, tokens)
+	}
+
+	if conf.Required && len(authenticators) == 0 {
+		return nil, fmt.Errorf("authentication is required but no authenticator is configured")
+	}
+
+	return auth.NewInterceptor(conf.Required, authenticators...), nil
+}
+
+// anonymousCallerID is every caller without credentials, once the server
+// checks them
+const anonymousCallerID = "(anonymous)"
+
+// callerID is the identity the caller authenticated as. Without credentials,
+// callers are trusted to report their own caller_id only when the server has
+// no authenticators; otherwise they could pass as someone who authenticated.
+func callerID(ctx context.Context, reported string) string {
+	caller := reported
+	if identity, ok := auth.FromContext(ctx); ok {
+		caller = identity.ID
+	} else if auth.Anonymous(ctx) {
+		caller = anonymousCallerID
+	}
+	telemetry.SetCaller(ctx, caller)
+	return caller
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/daneofmanythings/calcuroller/internal/auth"
+	"github.com/daneofmanythings/calcuroller/internal/config"
+	pb "github.com/daneofmanythings/calcuroller/internal/grpc/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func newAuthTestClient(t *testing.T, server *rollerServer, required bool) pb.RollerClient {
+	interceptor := auth.NewInterceptor(required, auth.NewAPIKeyAuthenticator(map[string]string{"s3cret": "alice"}))
+	return newTestClient(t, server,
+		grpc.UnaryInterceptor(interceptor.Unary()),
+		grpc.StreamInterceptor(interceptor.Stream()),
+	)
+}
+
+func TestAuthenticatedCallerID(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	server := newTestServer()
+	client := newAuthTestClient(t, server, false)
+
+	authenticated := metadata.AppendToOutgoingContext(ctx, auth.APIKeyHeader, "s3cret")
+
+	testCases := []struct {
+		name             string
+		ctx              context.Context
+		reportedCallerID string
+		expectedCallerID string
+	}{
+		{"authenticated", authenticated, "mallory", "alice"},
+		{"anonymous", ctx, "bob", anonymousCallerID},
+		{"anonymous claiming an identity", ctx, "alice", anonymousCallerID},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := client.Roll(tc.ctx, &pb.RollRequest{DiceString: "d4", CallerId: tc.reportedCallerID})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			rec, err := client.GetRoll(ctx, &pb.GetRollRequest{RollId: res.GetData().GetRollId()})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if rec.GetCallerId() != tc.expectedCallerID {
+				t.Fatalf("expected caller=%s, got=%s", tc.expectedCallerID, rec.GetCallerId())
+			}
+		})
+	}
+
+	// sessions broadcast the authenticated identity too
+	stream := joinTable(t, authenticated, client, "table", "mallory")
+	sendRoll(t, stream, "d1qu2")
+	expectEvent(t, stream, "alice", 2, false)
+
+	anonymous := joinTable(t, ctx, client, "other table", "alice")
+	sendRoll(t, anonymous, "d1qu2")
+	expectEvent(t, anonymous, anonymousCallerID, 2, false)
+}
+
+func TestAuthRequired(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client := newAuthTestClient(t, newTestServer(), true)
+
+	_, err := client.Roll(ctx, &pb.RollRequest{DiceString: "d4"})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated, got=%v", err)
+	}
+
+	stream, err := client.Session(ctx)
+	if err != nil {
+		t.Fatalf("could not open session: %v", err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated, got=%v", err)
+	}
+
+	_, err = client.Roll(metadata.AppendToOutgoingContext(ctx, auth.APIKeyHeader, "s3cret"), &pb.RollRequest{DiceString: "d4"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestNewAuthInterceptor(t *testing.T) {
+	if _, err := newAuthInterceptor(config.Auth{Required: true}, ""); err == nil {
+		t.Fatalf("expected requiring auth with no authenticators to fail")
+	}
+	if _, err := newAuthInterceptor(config.Auth{}, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	pb "github.com/daneofmanythings/calcuroller/internal/grpc/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *rollerServer) RollBatch(ctx context.Context, req *pb.RollBatchRequest) (*pb.RollBatchResponse, error) {
+	requests := req.GetRequests()
+	if len(requests) > s.conf.Limits.MaxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "batch of %d requests is larger than the maximum of %d", len(requests), s.conf.Limits.MaxBatchSize)
+	}
+
+	responses := make([]*pb.RollResponse, len(requests))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < min(s.conf.Limits.BatchWorkers, len(requests)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				responses[idx] = s.rollBatchItem(ctx, requests[idx])
+			}
+		}()
+	}
+
+	for idx := range requests {
+		select {
+		case jobs <- idx:
+		case <-ctx.Done():
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+
+	return &pb.RollBatchResponse{Responses: responses}, nil
+}
+
+// rollBatchItem rolls a single request of a batch. Errors, and panics, are
+// reported in the item's response so they can't fail the rest of the batch.
+func (s *rollerServer) rollBatchItem(ctx context.Context, req *pb.RollRequest) (res *pb.RollResponse) {
+	defer func() {
+		if r := recover(); r != nil {
+			res = newStatusResponse(codes.Internal, fmt.Sprintf("could not roll %q: %v", req.GetDiceString(), r))
+		}
+	}()
+
+	res, err := s.Roll(ctx, req)
+	if err != nil {
+		st := status.Convert(err)
+		return newStatusResponse(st.Code(), st.Message())
+	}
+	return res
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/daneofmanythings/calcuroller/internal/config"
+	pb "github.com/daneofmanythings/calcuroller/internal/grpc/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRollBatch(t *testing.T) {
+	requests := []*pb.RollRequest{}
+	for i := 1; i <= 40; i++ {
+		requests = append(requests, &pb.RollRequest{DiceString: fmt.Sprintf("d1qu%d", i)})
+	}
+	requests[10].DiceString = "5 + @"
+	requests[20].DiceString = "d0"
+
+	res, err := newTestServer().RollBatch(context.Background(), &pb.RollBatchRequest{Requests: requests})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.GetResponses()) != len(requests) {
+		t.Fatalf("expected %d responses, got=%d", len(requests), len(res.GetResponses()))
+	}
+
+	for i, response := range res.GetResponses() {
+		if i == 10 || i == 20 {
+			if response.GetStatus().GetCode() != int32(codes.InvalidArgument) {
+				t.Fatalf("responses[%d]: expected InvalidArgument, got=%v", i, response)
+			}
+			continue
+		}
+		if response.GetData().GetValue() != int64(i+1) {
+			t.Fatalf("responses[%d]: expected value=%d, got=%v", i, i+1, response)
+		}
+	}
+}
+
+func TestRollBatchTooLarge(t *testing.T) {
+	requests := make([]*pb.RollRequest, config.Default().Limits.MaxBatchSize+1)
+	_, err := newTestServer().RollBatch(context.Background(), &pb.RollBatchRequest{Requests: requests})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument, got=%v", err)
+	}
+}
+
+func TestRollBatchEmpty(t *testing.T) {
+	res, err := newTestServer().RollBatch(context.Background(), &pb.RollBatchRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.GetResponses()) != 0 {
+		t.Fatalf("expected no responses, got=%v", res)
+	}
+}
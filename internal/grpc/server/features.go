@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+
+	"github.com/daneofmanythings/calcuroller/internal/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// full names of the Roller methods, as interceptors see them
+const (
+	methodPing       = "/google.rpc.Roller/Ping"
+	methodRoll       = "/google.rpc.Roller/Roll"
+	methodRollBatch  = "/google.rpc.Roller/RollBatch"
+	methodSession    = "/google.rpc.Roller/Session"
+	methodGetHistory = "/google.rpc.Roller/GetHistory"
+	methodGetRoll    = "/google.rpc.Roller/GetRoll"
+	methodStats      = "/google.rpc.Roller/Stats"
+	methodAnalyze    = "/google.rpc.Roller/Analyze"
+)
+
+// disabledMethods are the full gRPC method names turned off in the config
+func disabledMethods(features config.Features) map[string]bool {
+	return map[string]bool{
+		methodSession:    !features.Sessions,
+		methodRollBatch:  !features.Batch,
+		methodGetHistory: !features.HistoryAPI,
+		methodGetRoll:    !features.HistoryAPI,
+		methodStats:      !features.HistoryAPI,
+	}
+}
+
+func featureUnaryInterceptor(features config.Features) grpc.UnaryServerInterceptor {
+	disabled := disabledMethods(features)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if disabled[info.FullMethod] {
+			return nil, status.Errorf(codes.Unimplemented, "%s is disabled on this server", info.FullMethod)
+		}
+		return handler(ctx, req)
+	}
+}
+
+func featureStreamInterceptor(features config.Features) grpc.StreamServerInterceptor {
+	disabled := disabledMethods(features)
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if disabled[info.FullMethod] {
+			return status.Errorf(codes.Unimplemented, "%s is disabled on this server", info.FullMethod)
+		}
+		return handler(srv, stream)
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/daneofmanythings/calcuroller/internal/config"
+	pb "github.com/daneofmanythings/calcuroller/internal/grpc/proto"
+	"github.com/daneofmanythings/calcuroller/internal/ratelimit"
+	"github.com/daneofmanythings/calcuroller/internal/telemetry"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	jsonMarshaler   = protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}
+	jsonUnmarshaler = protojson.UnmarshalOptions{DiscardUnknown: true}
+)
+
+// newGateway serves the Roller RPCs as HTTP/JSON endpoints. Request and
+// response bodies are the protojson encoding of the RPC's messages. Every
+// route is wrapped in middleware, the first outermost.
+func newGateway(server pb.RollerServer, conf *config.Config, middleware ...gatewayMiddleware) http.Handler {
+	mux := http.NewServeMux()
+	maxBytes := int64(conf.Limits.MaxRequestBytes)
+	handle := func(pattern, method string, handler http.Handler) {
+		for i := len(middleware) - 1; i >= 0; i-- {
+			handler = middleware[i](method, handler)
+		}
+		mux.Handle(pattern, handler)
+	}
+
+	handle("/v1/ping", methodPing, handleUnary(http.MethodGet, maxBytes, server.Ping))
+	handle("/v1/roll", methodRoll, handleUnary(http.MethodPost, maxBytes, server.Roll))
+	handle("/v1/analyze", methodAnalyze, handleUnary(http.MethodPost, maxBytes, server.Analyze))
+	if conf.Features.Batch {
+		handle("/v1/roll:batch", methodRollBatch, handleUnary(http.MethodPost, maxBytes, server.RollBatch))
+	}
+	if conf.Features.HistoryAPI {
+		handle("/v1/history", methodGetHistory, handleUnary(http.MethodGet, maxBytes, server.GetHistory))
+		handle("/v1/history/", methodGetRoll, withPathParam("/v1/history/", "roll_id", handleUnary(http.MethodGet, maxBytes, server.GetRoll)))
+		handle("/v1/stats", methodStats, handleUnary(http.MethodGet, maxBytes, server.Stats))
+	}
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, status.Errorf(codes.NotFound, "no endpoint for %s", r.URL.Path))
+	})
+
+	return mux
+}
+
+// handleUnary adapts a unary RPC into an http.Handler. GET requests carry no
+// body, so the request message is decoded from the query parameters instead.
+func handleUnary[Req any, Res proto.Message, PReq interface {
+	*Req
+	proto.Message
+}](method string, maxRequestBytes int64, rpc func(context.Context, PReq) (Res, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			writeMessage(w, http.StatusMethodNotAllowed, &pb.MyStatus{
+				Code:    int32(codes.Unimplemented),
+				Message: fmt.Sprintf("method %s not allowed on %s", r.Method, r.URL.Path),
+			})
+			return
+		}
+
+		req := PReq(new(Req))
+		if method == http.MethodGet {
+			if err := decodeQuery(r.URL.Query(), req); err != nil {
+				writeError(w, status.Errorf(codes.InvalidArgument, "could not decode query: %v", err))
+				return
+			}
+		} else {
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+			if err != nil {
+				writeError(w, status.Errorf(codes.InvalidArgument, "could not read request body: %v", err))
+				return
+			}
+			if err := jsonUnmarshaler.Unmarshal(body, req); err != nil {
+				writeError(w, status.Errorf(codes.InvalidArgument, "could not decode request body: %v", err))
+				return
+			}
+		}
+
+		telemetry.SetRequest(r.Context(), req)
+		res, err := rpc(r.Context(), req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeMessage(w, httpStatusFromResponse(res), res)
+	})
+}
+
+// decodeQuery fills the scalar fields of a request message from query
+// parameters named after the fields, e.g. ?caller_id=bob&page_size=10.
+// protojson accepts numbers and timestamps as strings, so every parameter is
+// passed through as a JSON string.
+func decodeQuery(query url.Values, req proto.Message) error {
+	fields := map[string]string{}
+	for key := range query {
+		fields[key] = query.Get(key)
+	}
+
+	body, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	return jsonUnmarshaler.Unmarshal(body, req)
+}
+
+// withPathParam passes the rest of the path after prefix to next as the
+// query parameter name, e.g. /v1/history/abc becomes ?roll_id=abc.
+func withPathParam(prefix, name string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.Clone(r.Context())
+		query := r.URL.Query()
+		query.Set(name, strings.TrimPrefix(r.URL.Path, prefix))
+		r.URL.RawQuery = query.Encode()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// httpStatusFromResponse maps responses that carry an in-band status, like a
+// RollResponse for an invalid dice string, to the matching http status.
+func httpStatusFromResponse(res proto.Message) int {
+	if res, ok := res.(interface{ GetStatus() *pb.MyStatus }); ok && res.GetStatus() != nil {
+		return httpStatusFromCode(codes.Code(res.GetStatus().GetCode()))
+	}
+	return http.StatusOK
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+	if delay, ok := ratelimit.RetryDelay(err); ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+	}
+	writeMessage(w, httpStatusFromCode(st.Code()), &pb.MyStatus{
+		Code:    int32(st.Code()),
+		Message: st.Message(),
+	})
+}
+
+func writeMessage(w http.ResponseWriter, httpStatus int, msg proto.Message) {
+	body, err := jsonMarshaler.Marshal(msg)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus)
+	w.Write(body)
+}
+
+func httpStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499 // client closed request
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
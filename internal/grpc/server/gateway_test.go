@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/daneofmanythings/calcuroller/internal/config"
+)
+
+func TestGateway(t *testing.T) {
+	testCases := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedBody   map[string]any
+	}{
+		{"ping", http.MethodGet, "/v1/ping", "", http.StatusOK, map[string]any{"ping": "pong"}},
+		{"roll", http.MethodPost, "/v1/roll", `{"dice_string": "d1qu2 + 3"}`, http.StatusOK, nil},
+		{"analyze", http.MethodPost, "/v1/analyze", `{"dice_string": "d6qu3"}`, http.StatusOK, nil},
+		{"invalid analyze", http.MethodPost, "/v1/analyze", `{"dice_string": "d0"}`, http.StatusBadRequest, nil},
+		{"invalid dice string", http.MethodPost, "/v1/roll", `{"dice_string": "5 + @"}`, http.StatusBadRequest, nil},
+		{"invalid body", http.MethodPost, "/v1/roll", `{"dice_string": 5}`, http.StatusBadRequest, map[string]any{"code": float64(3)}},
+		{"wrong method", http.MethodGet, "/v1/roll", "", http.StatusMethodNotAllowed, map[string]any{"code": float64(12)}},
+		{"unknown path", http.MethodGet, "/v1/nope", "", http.StatusNotFound, map[string]any{"code": float64(5)}},
+		{"history", http.MethodGet, "/v1/history?caller_id=alice&page_size=10&since=2024-01-01T00:00:00Z", "", http.StatusOK, map[string]any{"next_page_token": ""}},
+		{"history bad query", http.MethodGet, "/v1/history?page_size=ten", "", http.StatusBadRequest, map[string]any{"code": float64(3)}},
+		{"stats", http.MethodGet, "/v1/stats?caller_id=alice", "", http.StatusOK, nil},
+		{"unknown roll", http.MethodGet, "/v1/history/nope", "", http.StatusNotFound, map[string]any{"code": float64(5)}},
+	}
+
+	server := httptest.NewServer(newGateway(newTestServer(), config.Default()))
+	defer server.Close()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(tc.method, server.URL+tc.path, strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatalf("could not build request: %v", err)
+			}
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != tc.expectedStatus {
+				t.Fatalf("expected status=%d, got=%d", tc.expectedStatus, res.StatusCode)
+			}
+			if res.Header.Get("Content-Type") != "application/json" {
+				t.Fatalf("expected a json response, got=%s", res.Header.Get("Content-Type"))
+			}
+
+			raw, _ := io.ReadAll(res.Body)
+			body := map[string]any{}
+			if err := json.Unmarshal(raw, &body); err != nil {
+				t.Fatalf("could not decode response %s: %v", raw, err)
+			}
+			for key, expected := range tc.expectedBody {
+				if body[key] != expected {
+					t.Fatalf("expected %s=%v, got=%v. body=%s", key, expected, body[key], raw)
+				}
+			}
+		})
+	}
+}
+
+func TestGatewayRollValue(t *testing.T) {
+	server := httptest.NewServer(newGateway(newTestServer(), config.Default()))
+	defer server.Close()
+
+	res, err := http.Post(server.URL+"/v1/roll", "application/json", strings.NewReader(`{"dice_string": "d1qu2 + 3"}`))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer res.Body.Close()
+
+	body := struct {
+		Data struct {
+			RequestLiteral string `json:"request_literal"`
+			Value          string `json:"value"`
+		} `json:"data"`
+	}{}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		t.Fatalf("could not decode response: %v", err)
+	}
+	if body.Data.RequestLiteral != "d1qu2 + 3" || body.Data.Value != "5" {
+		t.Fatalf("unexpected response: %+v", body)
+	}
+}
+
+func TestGatewayAnalyze(t *testing.T) {
+	server := httptest.NewServer(newGateway(newTestServer(), config.Default()))
+	defer server.Close()
+
+	res, err := http.Post(server.URL+"/v1/analyze", "application/json", strings.NewReader(`{"dice_string": "d6qu3"}`))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer res.Body.Close()
+
+	body := struct {
+		Data struct {
+			Min     string  `json:"min"`
+			Max     string  `json:"max"`
+			Mean    float64 `json:"mean"`
+			Summary string  `json:"summary"`
+		} `json:"data"`
+	}{}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		t.Fatalf("could not decode response: %v", err)
+	}
+	if body.Data.Min != "3" || body.Data.Max != "18" || body.Data.Mean != 10.5 || body.Data.Summary != "3–18, avg 10.5" {
+		t.Fatalf("unexpected response: %+v", body)
+	}
+}
+
+func TestGatewayRateLimit(t *testing.T) {
+	conf := config.Default()
+	conf.RateLimit = config.RateLimit{Enabled: true, Rate: 0.1, Burst: 1, ExpensiveRate: 0.1, ExpensiveBurst: 1}
+	limiter := newRateLimiter(conf.RateLimit)
+	server := httptest.NewServer(newGateway(newTestServer(), conf, func(method string, next http.Handler) http.Handler {
+		return limiter.HTTP(method, next, writeError)
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		path           string
+		expectedStatus int
+	}{
+		{"/v1/ping", http.StatusOK},
+		{"/v1/ping", http.StatusTooManyRequests},
+		{"/v1/stats", http.StatusOK},
+		{"/v1/stats", http.StatusTooManyRequests},
+	}
+
+	for _, tc := range testCases {
+		res, err := http.Get(server.URL + tc.path)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		res.Body.Close()
+
+		if res.StatusCode != tc.expectedStatus {
+			t.Fatalf("%s: expected status=%d, got=%d", tc.path, tc.expectedStatus, res.StatusCode)
+		}
+		if tc.expectedStatus == http.StatusTooManyRequests && res.Header.Get("Retry-After") != "10" {
+			t.Fatalf("%s: expected Retry-After=10, got=%q", tc.path, res.Header.Get("Retry-After"))
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/object"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/repl"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// the services reported by the health service, besides "" for the server as
+// a whole. Subsystems are reported on their own so a probe can tell which
+// one failed.
+const (
+	healthRoller  = "google.rpc.Roller"
+	healthStorage = "calcuroller.storage"
+	healthRNG     = "calcuroller.rng"
+)
+
+// healthCheckTimeout bounds a single subsystem check
+const healthCheckTimeout = 5 * time.Second
+
+// healthChecker keeps the grpc.health.v1 statuses up to date by checking
+// every subsystem on an interval. The server is serving only while all of
+// them pass.
+type healthChecker struct {
+	server   *health.Server
+	interval time.Duration
+	checks   map[string]func(context.Context) error
+	failing  map[string]bool
+}
+
+func newHealthChecker(s *rollerServer, interval time.Duration) *healthChecker {
+	h := &healthChecker{
+		server:   health.NewServer(),
+		interval: interval,
+		checks: map[string]func(context.Context) error{
+			healthStorage: s.history.Ping,
+			healthRNG:     s.checkRNG,
+		},
+		failing: map[string]bool{},
+	}
+	h.check(context.Background())
+	return h
+}
+
+// run checks the subsystems every interval until ctx is done.
+func (h *healthChecker) run(ctx context.Context) {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			h.check(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (h *healthChecker) check(ctx context.Context) {
+	serving := true
+	for service, check := range h.checks {
+		checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+		err := check(checkCtx)
+		cancel()
+
+		if err != nil && !h.failing[service] {
+			log.Printf("...%s health check failed: %v", service, err)
+		} else if err == nil && h.failing[service] {
+			log.Printf("...%s health check passed again", service)
+		}
+		h.failing[service] = err != nil
+
+		h.server.SetServingStatus(service, servingStatus(err == nil))
+		serving = serving && err == nil
+	}
+
+	h.server.SetServingStatus("", servingStatus(serving))
+	h.server.SetServingStatus(healthRoller, servingStatus(serving))
+}
+
+// shutdown reports every service as not serving, for good.
+func (h *healthChecker) shutdown() {
+	h.server.Shutdown()
+}
+
+func servingStatus(serving bool) healthpb.HealthCheckResponse_ServingStatus {
+	if serving {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+	return healthpb.HealthCheckResponse_NOT_SERVING
+}
+
+// checkRNG rolls a die the way requests are rolled.
+func (s *rollerServer) checkRNG(ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("rolling panicked: %v", r)
+		}
+	}()
+
+	result := repl.RunWithMetadata("d20", s.newMetadata())
+	value, ok := result.(*object.Integer)
+	if !ok || value.Value < 1 || value.Value > 20 {
+		return fmt.Errorf("expected a d20 to roll from 1 to 20, got=%s", result.Inspect())
+	}
+	return nil
+}
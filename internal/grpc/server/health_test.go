@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/daneofmanythings/calcuroller/internal/auth"
+	pb "github.com/daneofmanythings/calcuroller/internal/grpc/proto"
+	"github.com/daneofmanythings/calcuroller/internal/history"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// brokenStore is a history store that can no longer be written to
+type brokenStore struct {
+	history.Store
+}
+
+func (brokenStore) Ping(ctx context.Context) error {
+	return errors.New("disk on fire")
+}
+
+func TestHealthChecker(t *testing.T) {
+	broken := newTestServer()
+	broken.history = brokenStore{Store: broken.history}
+
+	serving := healthpb.HealthCheckResponse_SERVING
+	notServing := healthpb.HealthCheckResponse_NOT_SERVING
+
+	testCases := []struct {
+		name     string
+		server   *rollerServer
+		expected map[string]healthpb.HealthCheckResponse_ServingStatus
+	}{
+		{"healthy", newTestServer(), map[string]healthpb.HealthCheckResponse_ServingStatus{
+			"": serving, healthRoller: serving, healthStorage: serving, healthRNG: serving,
+		}},
+		{"storage failing", broken, map[string]healthpb.HealthCheckResponse_ServingStatus{
+			"": notServing, healthRoller: notServing, healthStorage: notServing, healthRNG: serving,
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			checker := newHealthChecker(tc.server, time.Minute)
+			for service, expected := range tc.expected {
+				res, err := checker.server.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+				if err != nil {
+					t.Fatalf("%q: unexpected error: %v", service, err)
+				}
+				if res.GetStatus() != expected {
+					t.Fatalf("%q: expected=%v, got=%v", service, expected, res.GetStatus())
+				}
+			}
+
+			checker.shutdown()
+			res, _ := checker.server.Check(context.Background(), &healthpb.HealthCheckRequest{Service: ""})
+			if res.GetStatus() != notServing {
+				t.Fatalf("expected NOT_SERVING after shutdown, got=%v", res.GetStatus())
+			}
+		})
+	}
+}
+
+func TestHealthSkipsInterceptors(t *testing.T) {
+	server := newTestServer()
+	checker := newHealthChecker(server, time.Minute)
+
+	chain := &interceptorChain{}
+	required := auth.NewInterceptor(true)
+	chain.add(required.Unary(), required.Stream(), nil)
+
+	conn := newTestConn(t, func(grpcServer *grpc.Server) {
+		pb.RegisterRollerServer(grpcServer, server)
+		healthpb.RegisterHealthServer(grpcServer, checker.server)
+	}, chain.serverOptions()...)
+
+	ctx := context.Background()
+	res, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("expected probes without credentials to be answered, got=%v", err)
+	}
+	if res.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("expected SERVING, got=%v", res.GetStatus())
+	}
+
+	_, err = pb.NewRollerClient(conn).Ping(ctx, &pb.PingRequest{})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated, got=%v", err)
+	}
+}
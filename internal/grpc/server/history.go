@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	pb "github.com/daneofmanythings/calcuroller/internal/grpc/proto"
+	"github.com/daneofmanythings/calcuroller/internal/history"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *rollerServer) GetHistory(ctx context.Context, req *pb.GetHistoryRequest) (*pb.GetHistoryResponse, error) {
+	if req.GetPageSize() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "page size must not be negative, got=%d", req.GetPageSize())
+	}
+
+	filter := historyFilter(req.GetCallerId(), req.GetTableId(), req.GetSince(), req.GetUntil())
+	records, nextPageToken, err := s.history.List(ctx, filter, int(req.GetPageSize()), req.GetPageToken())
+	if err != nil {
+		return nil, historyError(err)
+	}
+
+	response := &pb.GetHistoryResponse{
+		Records:       []*pb.RollRecord{},
+		NextPageToken: nextPageToken,
+	}
+	for _, rec := range records {
+		response.Records = append(response.Records, rollRecordToProto(rec))
+	}
+
+	return response, nil
+}
+
+func (s *rollerServer) GetRoll(ctx context.Context, req *pb.GetRollRequest) (*pb.RollRecord, error) {
+	if req.GetRollId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing roll id")
+	}
+
+	rec, err := s.history.Get(ctx, req.GetRollId())
+	if err != nil {
+		return nil, historyError(err)
+	}
+
+	return rollRecordToProto(rec), nil
+}
+
+func rollRecordToProto(rec *history.Record) *pb.RollRecord {
+	return &pb.RollRecord{
+		RollId:    rec.ID,
+		CallerId:  rec.CallerID,
+		TableId:   rec.TableID,
+		Timestamp: timestamppb.New(rec.Timestamp),
+		Seed:      rec.Result.Metadata.Seed,
+		Roll:      rollResultToProto(rec.ID, rec.Result),
+	}
+}
+
+func historyFilter(callerID, tableID string, since, until *timestamppb.Timestamp) history.Filter {
+	filter := history.Filter{CallerID: callerID, TableID: tableID}
+	if since != nil {
+		filter.Since = since.AsTime()
+	}
+	if until != nil {
+		filter.Until = until.AsTime()
+	}
+	return filter
+}
+
+func historyError(err error) error {
+	switch {
+	case errors.Is(err, history.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, history.ErrInvalidPageToken):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Errorf(codes.Internal, "could not read history: %v", err)
+	}
+}
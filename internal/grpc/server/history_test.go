@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/daneofmanythings/calcuroller/internal/grpc/proto"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/evaluator"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/lexer"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/object"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/parser"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRollIsRecorded(t *testing.T) {
+	server := newTestServer()
+	ctx := context.Background()
+
+	res, err := server.Roll(ctx, &pb.RollRequest{DiceString: "d20qu4kh2 + 3", CallerId: "alice"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data := res.GetData()
+	if data.GetRollId() == "" {
+		t.Fatalf("expected a roll id, got=%v", res)
+	}
+
+	rec, err := server.GetRoll(ctx, &pb.GetRollRequest{RollId: data.GetRollId()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.GetCallerId() != "alice" || rec.GetTableId() != "" || rec.GetTimestamp() == nil {
+		t.Fatalf("unexpected record: %v", rec)
+	}
+	if rec.GetRoll().GetData().GetValue() != data.GetValue() || rec.GetSeed() != data.GetSeed() {
+		t.Fatalf("expected the recorded roll to match the response. expected=%v, got=%v", data, rec.GetRoll())
+	}
+
+	// the recorded seed reproduces the roll
+	program := parser.New(lexer.New(data.GetRequestLiteral())).ParseProgram()
+	value, _ := evaluator.EvalWithSeed(program, rec.GetSeed())
+	if value.(*object.Integer).Value != data.GetValue() {
+		t.Fatalf("expected seed %d to reproduce value=%d, got=%d", rec.GetSeed(), data.GetValue(), value.(*object.Integer).Value)
+	}
+}
+
+func TestFailedRollIsRecorded(t *testing.T) {
+	server := newTestServer()
+	ctx := context.Background()
+
+	res, err := server.Roll(ctx, &pb.RollRequest{DiceString: "5 + @", CallerId: "alice"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.GetStatus().GetCode() != int32(codes.InvalidArgument) {
+		t.Fatalf("expected InvalidArgument, got=%v", res)
+	}
+
+	page, err := server.GetHistory(ctx, &pb.GetHistoryRequest{CallerId: "alice"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page.GetRecords()) != 1 {
+		t.Fatalf("expected 1 record, got=%v", page)
+	}
+	if page.GetRecords()[0].GetRoll().GetStatus().GetMessage() != res.GetStatus().GetMessage() {
+		t.Fatalf("expected status=%v, got=%v", res.GetStatus(), page.GetRecords()[0].GetRoll())
+	}
+}
+
+func TestGetHistory(t *testing.T) {
+	server := newTestServer()
+	ctx := context.Background()
+
+	for _, caller := range []string{"alice", "bob", "alice", "alice"} {
+		if _, err := server.Roll(ctx, &pb.RollRequest{DiceString: "d1qu2", CallerId: caller}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	page, err := server.GetHistory(ctx, &pb.GetHistoryRequest{CallerId: "alice", PageSize: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page.GetRecords()) != 2 || page.GetNextPageToken() == "" {
+		t.Fatalf("expected a full first page, got=%v", page)
+	}
+
+	page, err = server.GetHistory(ctx, &pb.GetHistoryRequest{CallerId: "alice", PageSize: 2, PageToken: page.GetNextPageToken()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page.GetRecords()) != 1 || page.GetNextPageToken() != "" {
+		t.Fatalf("expected a last page with 1 record, got=%v", page)
+	}
+	for _, rec := range page.GetRecords() {
+		if rec.GetCallerId() != "alice" {
+			t.Fatalf("expected only alice's rolls, got=%v", rec)
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		req      *pb.GetHistoryRequest
+		expected codes.Code
+	}{
+		{"invalid page token", &pb.GetHistoryRequest{PageToken: "nope"}, codes.InvalidArgument},
+		{"negative page size", &pb.GetHistoryRequest{PageSize: -1}, codes.InvalidArgument},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := server.GetHistory(ctx, tc.req)
+			if status.Code(err) != tc.expected {
+				t.Fatalf("expected=%s, got=%v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestGetRollNotFound(t *testing.T) {
+	_, err := newTestServer().GetRoll(context.Background(), &pb.GetRollRequest{RollId: "nope"})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound, got=%v", err)
+	}
+}
+
+func TestSessionRollsRecordTheTable(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	server := newTestServer()
+	client := newTestClient(t, server)
+
+	alice := joinTable(t, ctx, client, "table", "alice")
+	sendRoll(t, alice, "d1qu3")
+	expectEvent(t, alice, "alice", 3, false)
+
+	res, err := client.GetHistory(ctx, &pb.GetHistoryRequest{TableId: "table"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.GetRecords()) != 1 || res.GetRecords()[0].GetCallerId() != "alice" {
+		t.Fatalf("expected alice's roll at the table, got=%v", res)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+)
+
+// exemptServices skip the chain. Health probes come from orchestrators that
+// don't authenticate, and would only be noise in the logs and rate limits.
+var exemptServices = []string{"/grpc.health.v1.Health/"}
+
+func isExempt(method string) bool {
+	for _, service := range exemptServices {
+		if strings.HasPrefix(method, service) {
+			return true
+		}
+	}
+	return false
+}
+
+// gatewayMiddleware wraps the gateway's handler for a route. method is the
+// full name of the gRPC method the route serves.
+type gatewayMiddleware func(method string, next http.Handler) http.Handler
+
+// interceptorChain is what every call goes through before reaching the
+// server, in the order added, over both gRPC and the gateway.
+type interceptorChain struct {
+	unary   []grpc.UnaryServerInterceptor
+	stream  []grpc.StreamServerInterceptor
+	gateway []gatewayMiddleware
+}
+
+// add appends a step to the chain. gateway may be nil for steps that only
+// apply to gRPC.
+func (c *interceptorChain) add(unary grpc.UnaryServerInterceptor, stream grpc.StreamServerInterceptor, gateway gatewayMiddleware) {
+	c.unary = append(c.unary, func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if isExempt(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		return unary(ctx, req, info, handler)
+	})
+	c.stream = append(c.stream, func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if isExempt(info.FullMethod) {
+			return handler(srv, ss)
+		}
+		return stream(srv, ss, info, handler)
+	})
+	if gateway != nil {
+		c.gateway = append(c.gateway, gateway)
+	}
+}
+
+func (c *interceptorChain) serverOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(c.unary...),
+		grpc.ChainStreamInterceptor(c.stream...),
+	}
+}
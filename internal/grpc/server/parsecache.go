@@ -0,0 +1,118 @@
+package main
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+
+	"github.com/daneofmanythings/calcuroller/internal/telemetry"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/ast"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/compiler"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/repl"
+)
+
+// parseCache keeps the programs of the dice strings rolled most recently,
+// along with their bytecode, so formulas rolled over and over are only parsed
+// and compiled once. Evaluating never changes a program, nor running its
+// bytecode, so a cached one is rolled by any number of calls at once.
+type parseCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	order   *list.List // of *parseEntry, most recently used first
+}
+
+type parseEntry struct {
+	key      string
+	program  *ast.Program
+	bytecode *compiler.Bytecode
+}
+
+func newParseCache(size int) *parseCache {
+	return &parseCache{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// parse parses a dice string like repl.Parse does, from the cache when it
+// was parsed before. Only programs without parse errors are cached, and
+// compiled: bytecode is nil for the others, which are evaluated instead.
+func (c *parseCache) parse(diceString string) (*ast.Program, *compiler.Bytecode, []string) {
+	key := normalizeDiceString(diceString)
+
+	c.mu.Lock()
+	if element, ok := c.entries[key]; ok {
+		c.order.MoveToFront(element)
+		telemetry.RecordParseCacheLookup(true, c.order.Len())
+		c.mu.Unlock()
+		entry := element.Value.(*parseEntry)
+		return entry.program, entry.bytecode, nil
+	}
+	c.mu.Unlock()
+
+	program, parseErrors := repl.Parse(diceString)
+	if len(parseErrors) > 0 || !wellFormed(program) {
+		telemetry.RecordParseCacheLookup(false, c.len())
+		return program, nil, parseErrors
+	}
+	bytecode, err := compiler.Compile(program)
+	if err != nil { // the parser never produces what can't be compiled
+		telemetry.RecordParseCacheLookup(false, c.len())
+		return program, nil, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; !ok { // another call may have parsed it meanwhile
+		c.entries[key] = c.order.PushFront(&parseEntry{key: key, program: program, bytecode: bytecode})
+		for c.order.Len() > c.size {
+			oldest := c.order.Back()
+			c.order.Remove(oldest)
+			delete(c.entries, oldest.Value.(*parseEntry).key)
+			telemetry.RecordParseCacheEviction()
+		}
+	}
+	telemetry.RecordParseCacheLookup(false, c.order.Len())
+	return program, bytecode, nil
+}
+
+func (c *parseCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// normalizeDiceString is the cache key of a dice string. Only trailing
+// whitespace is trimmed: the metadata of a roll locates each node by its
+// offset in the dice string, so two dice strings can only share a program
+// when every token is at the same offset in both.
+func normalizeDiceString(diceString string) string {
+	return strings.TrimRight(diceString, " \t\r\n")
+}
+
+// wellFormed reports whether a program has no illegal tokens or missing
+// expressions. The parser doesn't report those as errors, but they can
+// depend on the trailing whitespace, like the offset of an unexpected end of
+// input.
+func wellFormed(node ast.Node) bool {
+	switch node := node.(type) {
+	case *ast.Program:
+		for _, statement := range node.Statements {
+			if !wellFormed(statement) {
+				return false
+			}
+		}
+		return true
+	case *ast.ExpressionStatement:
+		return wellFormed(node.Expression)
+	case *ast.PrefixExpression:
+		return wellFormed(node.Right)
+	case *ast.InfixExpression:
+		return wellFormed(node.Left) && wellFormed(node.Right)
+	case *ast.DiceLiteral, *ast.IntegerLiteral, *ast.Identifier:
+		return true
+	}
+	return false
+}
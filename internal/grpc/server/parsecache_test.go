@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/daneofmanythings/calcuroller/internal/config"
+	pb "github.com/daneofmanythings/calcuroller/internal/grpc/proto"
+	"github.com/daneofmanythings/calcuroller/internal/history"
+)
+
+func TestParseCache(t *testing.T) {
+	cache := newParseCache(2)
+
+	first, bytecode, _ := cache.parse("d20 + 5")
+	if again, cached, _ := cache.parse("d20 + 5"); again != first || cached != bytecode {
+		t.Fatalf("expected the cached program")
+	}
+	if bytecode == nil {
+		t.Fatalf("expected the cached program to be compiled")
+	}
+	if trailing, _, _ := cache.parse("d20 + 5 \n"); trailing != first {
+		t.Fatalf("expected trailing whitespace to share the cached program")
+	}
+	if leading, _, _ := cache.parse(" d20 + 5"); leading == first {
+		t.Fatalf("expected leading whitespace to be parsed again, since it moves every node")
+	}
+
+	// " d20 + 5" is the most recent, then "d20 + 5". "d6" evicts "d20 + 5"
+	cache.parse("d6")
+	if _, ok := cache.entries["d20 + 5"]; ok {
+		t.Fatalf("expected the least recently used program to be evicted")
+	}
+	if cache.len() != 2 {
+		t.Fatalf("expected=2 entries, got=%d", cache.len())
+	}
+}
+
+func TestParseCacheSkipsInvalid(t *testing.T) {
+	testCases := []string{
+		"1 +",   // an unexpected end of input, at an offset that depends on the whitespace after it
+		"d6 @",  // an illegal token
+		"(d6",   // a parse error
+		"d6 / ", // a missing operand
+	}
+
+	for _, input := range testCases {
+		t.Run(input, func(t *testing.T) {
+			cache := newParseCache(10)
+			first, bytecode, _ := cache.parse(input)
+			if again, _, _ := cache.parse(input); again == first {
+				t.Fatalf("expected %q to be parsed again", input)
+			}
+			if bytecode != nil {
+				t.Fatalf("expected %q to be evaluated, not compiled", input)
+			}
+			if cache.len() != 0 {
+				t.Fatalf("expected an empty cache, got=%d entries", cache.len())
+			}
+		})
+	}
+}
+
+// a cached program is rolled by many calls at once, each getting the spans
+// and tags of its own dice string
+func TestParseCacheConcurrentRolls(t *testing.T) {
+	conf := config.Default()
+	conf.Limits.ParseCacheSize = 4
+	server := newServer(history.NewMemoryStore(0), conf)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 100)
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			diceString := []string{"d6qu4kh3[str] + 2", "d6qu4kh3[str] + 2   ", "d20[hit] - 1", "-d4"}[i%4]
+			res, err := server.Roll(context.Background(), &pb.RollRequest{DiceString: diceString})
+			if err != nil {
+				errs <- err
+				return
+			}
+			data := res.GetData()
+			if data.GetTree().GetEnd() != int32(len(normalizeDiceString(diceString))) {
+				errs <- fmt.Errorf("%q: expected the tree to span the dice string, got=%v", diceString, data.GetTree())
+				return
+			}
+			tags := data.GetMetadata()[0].GetTags()
+			if i%4 < 3 && len(tags) != 1 {
+				errs <- fmt.Errorf("%q: expected a single tag, got=%v", diceString, tags)
+				return
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+	if server.programs.len() != 3 {
+		t.Fatalf("expected=3 cached programs, got=%d", server.programs.len())
+	}
+}
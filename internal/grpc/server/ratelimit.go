@@ -0,0 +1,16 @@
+package main
+
+import (
+	"github.com/daneofmanythings/calcuroller/internal/config"
+	"github.com/daneofmanythings/calcuroller/internal/ratelimit"
+)
+
+// newRateLimiter charges batches and stats to the expensive budget, and
+// every other call to the default one. It is nil if rate limiting is off.
+func newRateLimiter(conf config.RateLimit) *ratelimit.Interceptor {
+	if !conf.Enabled {
+		return nil
+	}
+	return ratelimit.NewInterceptor(ratelimit.Budget{Rate: conf.Rate, Burst: conf.Burst}).
+		Limit(ratelimit.Budget{Rate: conf.ExpensiveRate, Burst: conf.ExpensiveBurst}, methodRollBatch, methodStats)
+}
@@ -3,114 +3,501 @@ package main
 import (
 	"context"
 	"crypto/tls"
-	_ "embed"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"log/slog"
 	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
+	"github.com/daneofmanythings/calcuroller/internal/auth"
+	"github.com/daneofmanythings/calcuroller/internal/config"
 	"github.com/daneofmanythings/calcuroller/internal/grpc/certs"
 	pb "github.com/daneofmanythings/calcuroller/internal/grpc/proto"
+	"github.com/daneofmanythings/calcuroller/internal/history"
+	"github.com/daneofmanythings/calcuroller/internal/telemetry"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/analyzer"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/evaluator"
 	"github.com/daneofmanythings/calcuroller/pkg/interpreter/object"
-	"github.com/daneofmanythings/calcuroller/pkg/interpreter/repl"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/render"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/vm"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 )
 
-var port int = 8080
-
 type rollerServer struct {
 	pb.UnimplementedRollerServer
+	conf     *config.Config
+	tables   *tables
+	history  history.Store
+	programs *parseCache
 }
 
-func newServer() *rollerServer {
-	return &rollerServer{}
+func newServer(store history.Store, conf *config.Config) *rollerServer {
+	return &rollerServer{
+		conf:     conf,
+		tables:   newTables(conf.Limits.SessionHistorySize, conf.Limits.SessionBufferSize),
+		history:  store,
+		programs: newParseCache(conf.Limits.ParseCacheSize),
+	}
 }
 
 func (s *rollerServer) Ping(ctx context.Context, req *pb.PingRequest) (*pb.PingResponse, error) {
 	return &pb.PingResponse{Ping: "pong"}, nil
 }
 
-// TODO: DO REAL ERROR CHECKING ON BOTH RETURN VALUES AND JSON ENCODING!
 func (s *rollerServer) Roll(ctx context.Context, req *pb.RollRequest) (*pb.RollResponse, error) {
+	return s.roll(ctx, req, "")
+}
+
+// roll evaluates a request and records it in the history, failed rolls
+// included. tableID is the session table the roll was made at, if any.
+func (s *rollerServer) roll(ctx context.Context, req *pb.RollRequest, tableID string) (*pb.RollResponse, error) {
+	var format render.Format
+	if req.GetFormat() != "" {
+		var err error
+		format, err = render.ParseFormat(req.GetFormat())
+		if err != nil {
+			return newStatusResponse(codes.InvalidArgument, err.Error()), nil
+		}
+	}
+
 	requestLiteral := req.GetDiceString()
-	result, metadata := repl.RunFromGRPC(requestLiteral)
-
-	// this is pure chaos
-	if result.Type() == object.ERROR_OBJ {
-		return &pb.RollResponse{
-			Message: &pb.RollResponse_Status{
-				Status: &pb.MyStatus{
-					Code:    int32(codes.InvalidArgument),
-					Message: result.Inspect(),
-				},
-			},
-		}, nil
+	if len(requestLiteral) > s.conf.Limits.MaxDiceStringLength {
+		return newStatusResponse(codes.InvalidArgument, fmt.Sprintf("dice string of %d bytes is longer than the maximum of %d", len(requestLiteral), s.conf.Limits.MaxDiceStringLength)), nil
 	}
 
-	value := result.(*object.Integer).Value
-	diceRollMetadata := []*pb.DiceRollMetadata{}
+	program, bytecode, parseErrors := s.programs.parse(requestLiteral)
+	metadata := s.newMetadata()
+	metadata.Trace = req.GetExplain()
+	var result object.Object
+	if bytecode != nil {
+		result = vm.Run(bytecode, metadata)
+	} else {
+		result = evaluator.Eval(program, metadata)
+	}
+	if err, ok := result.(*object.Error); len(parseErrors) > 0 || ok && err.Syntax {
+		telemetry.RecordParseError()
+	}
+	telemetry.RecordDice(metadata)
+	rollResult := object.NewRollResult(requestLiteral, result, metadata)
+
+	rec := &history.Record{
+		ID:        history.NewID(),
+		CallerID:  callerID(ctx, req.GetCallerId()),
+		TableID:   tableID,
+		Timestamp: time.Now().UTC(),
+		Result:    rollResult,
+	}
+	if err := s.history.Append(ctx, rec); err != nil {
+		return nil, status.Errorf(codes.Internal, "could not record roll: %v", err)
+	}
 
-	for _, rollData := range metadata.Store {
-		rollMetadata := &pb.DiceRollMetadata{
-			ResponseLiteral: rollData.Literal,
-			Tags:            rollData.Tags,
-			RawRolls:        rollData.RawRolls,
-			FinalRolls:      rollData.FinalRolls,
-			Value:           rollData.Value,
+	res := rollResultToProto(rec.ID, rollResult)
+	if res.GetData() != nil && format != "" {
+		rendered, err := render.Render(rollResult, format)
+		if err != nil {
+			return newStatusResponse(codes.InvalidArgument, err.Error()), nil
 		}
-		diceRollMetadata = append(diceRollMetadata, rollMetadata)
+		res.GetData().Rendered = rendered
+	}
+
+	return res, nil
+}
+
+// Analyze finds the bounds of a dice string without rolling it, so nothing
+// is recorded in the history.
+func (s *rollerServer) Analyze(ctx context.Context, req *pb.AnalyzeRequest) (*pb.AnalyzeResponse, error) {
+	requestLiteral := req.GetDiceString()
+	if len(requestLiteral) > s.conf.Limits.MaxDiceStringLength {
+		return newAnalyzeStatusResponse(codes.InvalidArgument, fmt.Sprintf("dice string of %d bytes is longer than the maximum of %d", len(requestLiteral), s.conf.Limits.MaxDiceStringLength)), nil
+	}
+
+	program, _, parseErrors := s.programs.parse(requestLiteral)
+	if len(parseErrors) > 0 {
+		telemetry.RecordParseError()
+	}
+	bounds, err := analyzer.Analyze(program, s.conf.Limits.MaxDice, s.conf.Limits.MaxDieSize)
+	if err != nil {
+		return newAnalyzeStatusResponse(codes.InvalidArgument, (&object.Error{Message: err.Error()}).Inspect()), nil
+	}
+
+	return &pb.AnalyzeResponse{
+		Message: &pb.AnalyzeResponse_Data{
+			Data: &pb.AnalyzeData{
+				RequestLiteral: requestLiteral,
+				Min:            bounds.Min,
+				Max:            bounds.Max,
+				Mean:           bounds.Mean,
+				Estimated:      bounds.Estimated,
+				Summary:        bounds.String(),
+			},
+		},
+	}, nil
+}
+
+// newMetadata is where a roll's dice come from, picked by the rng mode.
+func (s *rollerServer) newMetadata() *object.Metadata {
+	var md *object.Metadata
+	if s.conf.RNG.Mode == config.RNG_CRYPTO {
+		md = object.NewCryptoMetadata()
+	} else {
+		md = object.NewMetadata()
+	}
+	md.MaxDice = s.conf.Limits.MaxDice
+	md.MaxDieSize = s.conf.Limits.MaxDieSize
+	return md
+}
+
+// rollResultToProto converts a roll into its response. Failed rolls become a
+// status response.
+func rollResultToProto(rollID string, result *object.RollResult) *pb.RollResponse {
+	if result.Error != "" {
+		return newStatusResponse(codes.InvalidArgument, (&object.Error{Message: result.Error}).Inspect())
+	}
+
+	diceRollMetadata := []*pb.DiceRollMetadata{}
+	for _, rollData := range result.Metadata.Dice() {
+		diceRollMetadata = append(diceRollMetadata, diceDataToProto(rollData))
 	}
 
-	// and this is pure chaos
 	return &pb.RollResponse{
 		Message: &pb.RollResponse_Data{
 			Data: &pb.RollData{
-				RequestLiteral: requestLiteral,
-				Value:          value,
+				RequestLiteral: result.Literal,
+				Value:          result.Value,
 				Metadata:       diceRollMetadata,
+				Tree:           metadataNodeToProto(result.Metadata.Root),
+				RollId:         rollID,
+				Seed:           result.Metadata.Seed,
+				Steps:          stepsToProto(result.Metadata.Steps),
 			},
 		},
-	}, nil
+	}
+}
+
+func newStatusResponse(code codes.Code, message string) *pb.RollResponse {
+	return &pb.RollResponse{
+		Message: &pb.RollResponse_Status{
+			Status: &pb.MyStatus{
+				Code:    int32(code),
+				Message: message,
+			},
+		},
+	}
+}
+
+func newAnalyzeStatusResponse(code codes.Code, message string) *pb.AnalyzeResponse {
+	return &pb.AnalyzeResponse{
+		Message: &pb.AnalyzeResponse_Status{
+			Status: &pb.MyStatus{
+				Code:    int32(code),
+				Message: message,
+			},
+		},
+	}
+}
+
+func diceDataToProto(rollData object.DiceData) *pb.DiceRollMetadata {
+	dice := make([]*pb.Die, 0, len(rollData.Dice))
+	for _, die := range rollData.Dice {
+		dice = append(dice, &pb.Die{
+			Index:   int32(die.Index),
+			Roll:    die.Roll,
+			Value:   die.Value,
+			Clamped: die.Clamped,
+			Dropped: die.Dropped,
+		})
+	}
+
+	return &pb.DiceRollMetadata{
+		ResponseLiteral: rollData.Literal,
+		Tags:            rollData.Tags,
+		Size:            rollData.Size,
+		Dice:            dice,
+		Value:           rollData.Value,
+	}
+}
+
+func metadataNodeToProto(node *object.MetadataNode) *pb.MetadataNode {
+	if node == nil {
+		return nil
+	}
+
+	result := &pb.MetadataNode{
+		Kind:     string(node.Kind),
+		Operator: node.Operator,
+		Start:    int32(node.Start),
+		End:      int32(node.End),
+		Value:    node.Value,
+		Children: []*pb.MetadataNode{},
+	}
+	if node.Data != nil {
+		result.Dice = diceDataToProto(*node.Data)
+	}
+	for _, child := range node.Children {
+		result.Children = append(result.Children, metadataNodeToProto(child))
+	}
+
+	return result
+}
+
+func stepsToProto(steps []object.Step) []*pb.EvalStep {
+	result := []*pb.EvalStep{}
+	for _, step := range steps {
+		result = append(result, &pb.EvalStep{
+			Depth: int32(step.Depth),
+			Kind:  string(step.Kind),
+			Start: int32(step.Start),
+			End:   int32(step.End),
+			Text:  step.Text,
+		})
+	}
+	return result
 }
 
-func loadTLSCredentials() (credentials.TransportCredentials, error) {
-	// Load server's certificate and private key
-	serverCert, err := tls.X509KeyPair(certs.ServerCertPEMBlock, certs.ServerKeyPEMBlock)
+// loadTLSCertificates serves the certificate and key from disk, reloading
+// them when they change or the server receives a SIGHUP.
+func loadTLSCertificates(certFile, keyFile, clientCAFile string) (*certs.Reloader, error) {
+	reloader, err := certs.NewReloader(certFile, keyFile, clientCAFile)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create the credentials and return it
-	config := &tls.Config{
-		Certificates: []tls.Certificate{serverCert},
-		ClientAuth:   tls.NoClientCert,
-	}
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+	go func() {
+		for range hangups {
+			if err := reloader.Reload(); err != nil {
+				log.Printf("...could not reload TLS credentials: %v", err)
+				continue
+			}
+			log.Println("...reloaded TLS credentials")
+		}
+	}()
+
+	return reloader, nil
+}
+
+// setupLogging sends the log package, and slog, through a handler at the
+// configured level and format.
+func setupLogging(conf config.Log) {
+	var level slog.Level
+	level.UnmarshalText([]byte(conf.Level)) // validated with the config
 
-	return credentials.NewTLS(config), nil
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler = slog.NewTextHandler(os.Stderr, opts)
+	if conf.Format == "json" {
+		handler = slog.NewJSONHandler(os.Stderr, opts)
+	}
+	slog.SetDefault(slog.New(handler))
 }
 
 func main() {
-	lis, err := net.Listen("tcp", fmt.Sprintf("localhost:%d", port))
+	configPath := flag.String("config", os.Getenv("CALCUROLLER_CONFIG"), "YAML or TOML config file ($CALCUROLLER_CONFIG)")
+	printConfig := flag.Bool("print-config", false, "print the effective config and exit")
+	configFlags := config.RegisterFlags(flag.CommandLine)
+	issueToken := flag.String("issue-token", "", "print a bearer token for this identity and exit")
+	tokenTTL := flag.Duration("token-ttl", 30*24*time.Hour, "how long issued tokens are valid for")
+	flag.Parse()
+
+	conf, err := config.Load(*configPath, os.LookupEnv, configFlags)
 	if err != nil {
-		log.Fatalf("...could not listen: %v", err)
+		log.Fatalf("...invalid config: %v", err)
 	}
 
-	tlsCredentials, err := loadTLSCredentials()
-	if err != nil {
-		log.Fatal("...could not load TLS credentials: ", err)
+	if *printConfig {
+		out, err := conf.YAML()
+		if err != nil {
+			log.Fatalf("...could not print config: %v", err)
+		}
+		fmt.Print(string(out))
+		return
 	}
 
-	grpcServer := grpc.NewServer(
-		grpc.Creds(tlsCredentials),
-	)
+	if *issueToken != "" {
+		tokens, err := auth.LoadTokenAuthenticator(conf.Auth.TokenSecret)
+		if err != nil {
+			log.Fatalf("...could not load token secret: %v", err)
+		}
+		token, err := tokens.Issue(*issueToken, *tokenTTL)
+		if err != nil {
+			log.Fatalf("...could not issue token: %v", err)
+		}
+		fmt.Println(token)
+		return
+	}
+
+	setupLogging(conf.Log)
+
+	authInterceptor, err := newAuthInterceptor(conf.Auth, conf.TLS.ClientCA)
+	if err != nil {
+		log.Fatalf("...could not set up authentication: %v", err)
+	}
 
-	pb.RegisterRollerServer(grpcServer, newServer())
-	reflection.Register(grpcServer)
+	store, err := history.Open(conf.History.Backend, conf.History.Path, conf.History.MemoryMaxRecords)
+	if err != nil {
+		log.Fatalf("...could not open history: %v", err)
+	}
+	defer store.Close()
 
-	err = grpcServer.Serve(lis)
+	lis, err := net.Listen("tcp", conf.Listen.GRPCAddress)
 	if err != nil {
+		log.Fatalf("...could not listen: %v", err)
+	}
+
+	chain := &interceptorChain{}
+	if conf.Tracing.Exporter != telemetry.TRACING_NONE {
+		provider, err := telemetry.NewTracerProvider(conf.Tracing.Exporter, conf.Tracing.SampleRatio, os.Stdout)
+		if err != nil {
+			log.Fatalf("...could not set up tracing: %v", err)
+		}
+		defer provider.Shutdown(context.Background())
+
+		tracer := telemetry.NewTracer(provider)
+		chain.add(tracer.Unary(), tracer.Stream(), tracer.HTTP)
+	}
+	requests := telemetry.NewInterceptor(slog.Default())
+	chain.add(requests.Unary(), requests.Stream(), requests.HTTP)
+	chain.add(featureUnaryInterceptor(conf.Features), featureStreamInterceptor(conf.Features), nil)
+	chain.add(authInterceptor.Unary(), authInterceptor.Stream(), func(_ string, next http.Handler) http.Handler {
+		return authInterceptor.HTTP(next, writeError)
+	})
+	if limiter := newRateLimiter(conf.RateLimit); limiter != nil {
+		chain.add(limiter.Unary(), limiter.Stream(), func(method string, next http.Handler) http.Handler {
+			return limiter.HTTP(method, next, writeError)
+		})
+	}
+
+	// the gateway is served with the same certificates as gRPC, so
+	// credentials are never sent to it in the clear when gRPC is served
+	// with TLS
+	opts := chain.serverOptions()
+	var gatewayTLS *tls.Config
+	if conf.TLS.Plaintext {
+		log.Println("...serving gRPC and the HTTP gateway without TLS")
+	} else {
+		reloader, err := loadTLSCertificates(conf.TLS.Cert, conf.TLS.Key, conf.TLS.ClientCA)
+		if err != nil {
+			log.Fatalf("...could not load TLS credentials: %v", err)
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(reloader.ServerConfig())))
+		gatewayTLS = reloader.HTTPServerConfig()
+	}
+
+	grpcServer := grpc.NewServer(opts...)
+
+	server := newServer(store, conf)
+	pb.RegisterRollerServer(grpcServer, server)
+	if conf.Features.Reflection {
+		reflection.Register(grpcServer)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	healthChecker := newHealthChecker(server, conf.Health.CheckInterval)
+	healthpb.RegisterHealthServer(grpcServer, healthChecker.server)
+	go healthChecker.run(ctx)
+
+	httpServers := []*http.Server{}
+	if conf.Features.HTTPGateway {
+		httpServers = append(httpServers, serveHTTP("http", conf.Listen.HTTPAddress, newGateway(server, conf, chain.gateway...), gatewayTLS))
+	}
+	if conf.Metrics.Enabled {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", telemetry.MetricsHandler())
+		httpServers = append(httpServers, serveHTTP("metrics", conf.Metrics.Address, mux, nil))
+	}
+
+	serveErrs := make(chan error, 1)
+	go func() {
+		log.Printf("...serving gRPC on %s", lis.Addr())
+		serveErrs <- grpcServer.Serve(lis)
+	}()
+
+	select {
+	case err := <-serveErrs:
 		log.Fatalf("...could not serve: %v", err)
+	case <-ctx.Done():
+	}
+
+	log.Printf("...shutting down, giving in-flight calls up to %v to finish", conf.Shutdown.Timeout)
+	shutdown(grpcServer, httpServers, healthChecker, server.tables, conf.Shutdown.Timeout)
+}
+
+// serveHTTP serves handler on address in the background, over TLS when
+// tlsConfig is set. name is what the server is called in the logs.
+func serveHTTP(name, address string, handler http.Handler, tlsConfig *tls.Config) *http.Server {
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		log.Fatalf("...could not listen for %s: %v", name, err)
+	}
+
+	httpServer := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		TLSConfig:         tlsConfig,
+	}
+	go func() {
+		log.Printf("...serving %s on %s", name, lis.Addr())
+		var err error
+		if tlsConfig != nil {
+			err = httpServer.ServeTLS(lis, "", "") // the certificates are in tlsConfig
+		} else {
+			err = httpServer.Serve(lis)
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("...could not serve %s: %v", name, err)
+		}
+	}()
+
+	return httpServer
+}
+
+// shutdown stops taking new calls and waits up to timeout for the ones in
+// flight. Probes see the server as not serving right away, and sessions are
+// ended, since players can stay at a table indefinitely. Calls still running
+// after the timeout are cut off.
+func shutdown(grpcServer *grpc.Server, httpServers []*http.Server, healthChecker *healthChecker, tables *tables, timeout time.Duration) {
+	healthChecker.shutdown()
+	tables.close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, httpServer := range httpServers {
+		wg.Add(1)
+		go func(httpServer *http.Server) {
+			defer wg.Done()
+			if err := httpServer.Shutdown(ctx); err != nil {
+				log.Printf("...could not shut down http cleanly: %v", err)
+			}
+		}(httpServer)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		log.Printf("...calls still running after %v, stopping anyway", timeout)
+		grpcServer.Stop()
 	}
+	wg.Wait()
 }
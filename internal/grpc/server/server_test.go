@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/daneofmanythings/calcuroller/internal/config"
+	pb "github.com/daneofmanythings/calcuroller/internal/grpc/proto"
+	"github.com/daneofmanythings/calcuroller/internal/history"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newTestServer() *rollerServer {
+	return newServer(history.NewMemoryStore(0), config.Default())
+}
+
+func TestRollLimits(t *testing.T) {
+	conf := config.Default()
+	conf.Limits.MaxDiceStringLength = 20
+	conf.Limits.MaxDice = 10
+	server := newServer(history.NewMemoryStore(0), conf)
+
+	testCases := []struct {
+		name         string
+		diceString   string
+		expectedCode codes.Code
+	}{
+		{"within limits", "d6qu10", codes.OK},
+		{"too many dice", "d6qu11", codes.InvalidArgument},
+		{"too many dice across rolls", "d6qu5 + d6qu6", codes.InvalidArgument},
+		{"dice string too long", strings.Repeat("1+", 10) + "1", codes.InvalidArgument},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := server.Roll(context.Background(), &pb.RollRequest{DiceString: tc.diceString})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if code := codes.Code(res.GetStatus().GetCode()); code != tc.expectedCode {
+				t.Fatalf("expected=%v, got=%v (%s)", tc.expectedCode, code, res.GetStatus().GetMessage())
+			}
+		})
+	}
+}
+
+func TestRollCryptoRNG(t *testing.T) {
+	conf := config.Default()
+	conf.RNG.Mode = config.RNG_CRYPTO
+	server := newServer(history.NewMemoryStore(0), conf)
+
+	res, err := server.Roll(context.Background(), &pb.RollRequest{DiceString: "d20qu10"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.GetData() == nil {
+		t.Fatalf("expected a roll, got=%v", res.GetStatus())
+	}
+	if res.GetData().GetSeed() != 0 {
+		t.Fatalf("expected crypto rolls to have no seed, got=%d", res.GetData().GetSeed())
+	}
+}
+
+func TestRollExplain(t *testing.T) {
+	server := newTestServer()
+
+	for _, explain := range []bool{false, true} {
+		res, err := server.Roll(context.Background(), &pb.RollRequest{DiceString: "d12qu4kh2 + 3", Explain: explain})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		steps := res.GetData().GetSteps()
+		if !explain {
+			if len(steps) != 0 {
+				t.Fatalf("expected no steps, got=%v", steps)
+			}
+			continue
+		}
+
+		// rolled, kept, the total, 3 and the sum
+		if len(steps) != 5 {
+			t.Fatalf("expected=5 steps, got=%v", steps)
+		}
+		if !strings.HasPrefix(steps[1].GetText(), "kh2: kept") || steps[1].GetDepth() != 1 {
+			t.Fatalf("expected the kept rolls under the sum, got=%v", steps[1])
+		}
+		last := steps[len(steps)-1]
+		if last.GetKind() != "INFIX" || last.GetDepth() != 0 || !strings.HasSuffix(last.GetText(), fmt.Sprintf("= %d", res.GetData().GetValue())) {
+			t.Fatalf("expected the sum last, got=%v", last)
+		}
+	}
+}
+
+// every die is in the metadata, in roll order, with what the modifiers did
+// to it
+func TestRollDice(t *testing.T) {
+	res, err := newTestServer().Roll(context.Background(), &pb.RollRequest{DiceString: "d1qu4mi2kh3"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	metadata := res.GetData().GetMetadata()
+	if len(metadata) != 1 || metadata[0].GetValue() != 6 {
+		t.Fatalf("expected a single roll of 6, got=%v", metadata)
+	}
+
+	dice := metadata[0].GetDice()
+	if len(dice) != 4 {
+		t.Fatalf("expected=4 dice, got=%v", dice)
+	}
+	for i, die := range dice {
+		dropped := i == 3 // of four equal dice, the last rolled is dropped
+		if int(die.GetIndex()) != i || die.GetRoll() != 1 || die.GetValue() != 2 || !die.GetClamped() || die.GetDropped() != dropped {
+			t.Fatalf("die %d: expected a 1 raised to 2, dropped=%v, got=%v", i, dropped, die)
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	conf := config.Default()
+	conf.Limits.MaxDiceStringLength = 20
+	conf.Limits.MaxDice = 10
+	server := newServer(history.NewMemoryStore(0), conf)
+
+	testCases := []struct {
+		diceString      string
+		expectedCode    codes.Code
+		expectedSummary string
+	}{
+		{"d6qu3", codes.OK, "3–18, avg 10.5"},
+		{"d20qu2kh1 + 5", codes.OK, "6–25, avg 18.83"},
+		{"d6 / (d2 - 1)", codes.OK, "1–6, avg 3.5"},
+		{"d6qu11", codes.InvalidArgument, ""},
+		{"d0", codes.InvalidArgument, ""},
+		{"5 + @", codes.InvalidArgument, ""},
+		{strings.Repeat("1+", 10) + "1", codes.InvalidArgument, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.diceString, func(t *testing.T) {
+			res, err := server.Analyze(context.Background(), &pb.AnalyzeRequest{DiceString: tc.diceString})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if code := codes.Code(res.GetStatus().GetCode()); code != tc.expectedCode {
+				t.Fatalf("expected=%v, got=%v (%s)", tc.expectedCode, code, res.GetStatus().GetMessage())
+			}
+			if summary := res.GetData().GetSummary(); summary != tc.expectedSummary {
+				t.Fatalf("expected=%q, got=%q", tc.expectedSummary, summary)
+			}
+		})
+	}
+
+	// nothing analyzed is rolled
+	records, _, err := server.history.List(context.Background(), history.Filter{}, 0, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(records) != 0 {
+		t.Fatalf("expected an empty history, got=%v", records)
+	}
+}
+
+func TestDisabledFeatures(t *testing.T) {
+	features := config.Features{}
+	server := newTestServer()
+	client := newTestClient(t, server,
+		grpc.UnaryInterceptor(featureUnaryInterceptor(features)),
+		grpc.StreamInterceptor(featureStreamInterceptor(features)),
+	)
+	ctx := context.Background()
+
+	if _, err := client.Roll(ctx, &pb.RollRequest{DiceString: "d4"}); err != nil {
+		t.Fatalf("expected rolls to stay enabled, got=%v", err)
+	}
+
+	testCases := []struct {
+		name string
+		call func() error
+	}{
+		{"batch", func() error {
+			_, err := client.RollBatch(ctx, &pb.RollBatchRequest{})
+			return err
+		}},
+		{"history", func() error {
+			_, err := client.GetHistory(ctx, &pb.GetHistoryRequest{})
+			return err
+		}},
+		{"stats", func() error {
+			_, err := client.Stats(ctx, &pb.StatsRequest{})
+			return err
+		}},
+		{"session", func() error {
+			stream, err := client.Session(ctx)
+			if err != nil {
+				return err
+			}
+			_, err = stream.Recv()
+			return err
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.call(); status.Code(err) != codes.Unimplemented {
+				t.Fatalf("expected Unimplemented, got=%v", err)
+			}
+		})
+	}
+}
+
+// BenchmarkRoll is a whole Roll call over an in-memory connection: encoding,
+// the parse cache, rolling and recording the roll in the history
+func BenchmarkRoll(b *testing.B) {
+	benchmarkInputs := []struct {
+		name  string
+		input string
+	}{
+		{"attack", "d20 + 5 + d4[bless]"},
+		{"stats", "d6qu4kh3"},
+		{"keep half of 1000", "d100qu1000kh500"},
+	}
+
+	client := newTestClient(b, newTestServer())
+	ctx := context.Background()
+	for _, bc := range benchmarkInputs {
+		b.Run(bc.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				res, err := client.Roll(ctx, &pb.RollRequest{DiceString: bc.input})
+				if err != nil || res.GetData() == nil {
+					b.Fatalf("could not roll %q: %v %v", bc.input, err, res.GetStatus())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"io"
+	"sync"
+
+	pb "github.com/daneofmanythings/calcuroller/internal/grpc/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// tables fans the rolls made at a table out to everyone sitting at it
+type tables struct {
+	mu          sync.Mutex
+	tables      map[string]*table
+	historySize int // events replayed to late joiners
+	bufferSize  int // events buffered per player before they are dropped
+
+	closeOnce sync.Once
+	done      chan struct{} // closed when the server shuts down
+}
+
+type table struct {
+	subscribers map[*subscriber]struct{}
+	history     []*pb.SessionEvent
+}
+
+type subscriber struct {
+	events chan *pb.SessionEvent
+}
+
+func newTables(historySize, bufferSize int) *tables {
+	return &tables{
+		tables:      make(map[string]*table),
+		historySize: historySize,
+		bufferSize:  bufferSize,
+		done:        make(chan struct{}),
+	}
+}
+
+// close ends every session, so a graceful stop doesn't wait on players that
+// are still at a table.
+func (t *tables) close() {
+	t.closeOnce.Do(func() { close(t.done) })
+}
+
+// join subscribes to a table, returning the table's recent history. No
+// event is both in the history and sent to the subscriber.
+func (t *tables) join(tableID string) (*subscriber, []*pb.SessionEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tbl, ok := t.tables[tableID]
+	if !ok {
+		tbl = &table{subscribers: make(map[*subscriber]struct{})}
+		t.tables[tableID] = tbl
+	}
+
+	sub := &subscriber{events: make(chan *pb.SessionEvent, t.bufferSize)}
+	tbl.subscribers[sub] = struct{}{}
+
+	history := make([]*pb.SessionEvent, len(tbl.history))
+	copy(history, tbl.history)
+
+	return sub, history
+}
+
+// leave unsubscribes from a table. Tables are forgotten, history included,
+// once the last player leaves.
+func (t *tables) leave(tableID string, sub *subscriber) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tbl, ok := t.tables[tableID]
+	if !ok {
+		return
+	}
+	if _, ok := tbl.subscribers[sub]; ok {
+		delete(tbl.subscribers, sub)
+		close(sub.events)
+	}
+	if len(tbl.subscribers) == 0 {
+		delete(t.tables, tableID)
+	}
+}
+
+// publish records an event in the table's history and sends it to every
+// subscriber. Subscribers too far behind to take the event are dropped
+// instead of blocking the table.
+func (t *tables) publish(tableID string, event *pb.SessionEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tbl, ok := t.tables[tableID]
+	if !ok {
+		return
+	}
+
+	tbl.history = append(tbl.history, event)
+	if len(tbl.history) > t.historySize {
+		tbl.history = tbl.history[len(tbl.history)-t.historySize:]
+	}
+
+	for sub := range tbl.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			delete(tbl.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+func (s *rollerServer) Session(stream pb.Roller_SessionServer) error {
+	req, err := stream.Recv()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	join := req.GetJoin()
+	if join == nil || join.GetTableId() == "" {
+		return status.Error(codes.FailedPrecondition, "the first message of a session must join a table")
+	}
+	join = &pb.SessionJoin{
+		TableId:  join.GetTableId(),
+		CallerId: callerID(stream.Context(), join.GetCallerId()),
+	}
+
+	sub, history := s.tables.join(join.GetTableId())
+	defer s.tables.leave(join.GetTableId(), sub)
+
+	for _, event := range history {
+		replayed := &pb.SessionEvent{
+			TableId:   event.GetTableId(),
+			CallerId:  event.GetCallerId(),
+			Timestamp: event.GetTimestamp(),
+			History:   true,
+			Roll:      event.GetRoll(),
+		}
+		if err := stream.Send(replayed); err != nil {
+			return err
+		}
+	}
+
+	// stream.Send is not safe to call concurrently, so rolls are received
+	// here and every event is sent from the loop below.
+	recvErrs := make(chan error, 1)
+	go func() {
+		recvErrs <- s.receiveSessionRolls(stream, join)
+	}()
+
+	for {
+		select {
+		case event, ok := <-sub.events:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "fell too far behind the table")
+			}
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		case err := <-recvErrs:
+			return err
+		case <-s.tables.done:
+			return status.Error(codes.Unavailable, "the server is shutting down")
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		}
+	}
+}
+
+// receiveSessionRolls rolls every request sent on the stream and publishes
+// the results to the table until the player closes their side of the stream.
+func (s *rollerServer) receiveSessionRolls(stream pb.Roller_SessionServer, join *pb.SessionJoin) error {
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		rollReq := req.GetRoll()
+		if rollReq == nil {
+			return status.Error(codes.FailedPrecondition, "already joined a table")
+		}
+
+		res, err := s.roll(stream.Context(), &pb.RollRequest{
+			DiceString: rollReq.GetDiceString(),
+			CallerId:   join.GetCallerId(),
+			Format:     rollReq.GetFormat(),
+			Explain:    rollReq.GetExplain(),
+		}, join.GetTableId())
+		if err != nil {
+			return err
+		}
+
+		s.tables.publish(join.GetTableId(), &pb.SessionEvent{
+			TableId:   join.GetTableId(),
+			CallerId:  join.GetCallerId(),
+			Timestamp: timestamppb.Now(),
+			Roll:      res,
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	pb "github.com/daneofmanythings/calcuroller/internal/grpc/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newTestClient(t testing.TB, server *rollerServer, opts ...grpc.ServerOption) pb.RollerClient {
+	conn := newTestConn(t, func(grpcServer *grpc.Server) {
+		pb.RegisterRollerServer(grpcServer, server)
+	}, opts...)
+	return pb.NewRollerClient(conn)
+}
+
+// newTestConn connects to a server with the services added by register
+func newTestConn(t testing.TB, register func(*grpc.Server), opts ...grpc.ServerOption) *grpc.ClientConn {
+	lis := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer(opts...)
+	register(grpcServer)
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.Dial(
+		"bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("could not dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+func joinTable(t *testing.T, ctx context.Context, client pb.RollerClient, tableID, callerID string) pb.Roller_SessionClient {
+	stream, err := client.Session(ctx)
+	if err != nil {
+		t.Fatalf("could not open session: %v", err)
+	}
+	err = stream.Send(&pb.SessionRequest{
+		Message: &pb.SessionRequest_Join{Join: &pb.SessionJoin{TableId: tableID, CallerId: callerID}},
+	})
+	if err != nil {
+		t.Fatalf("could not join: %v", err)
+	}
+	return stream
+}
+
+func sendRoll(t *testing.T, stream pb.Roller_SessionClient, diceString string) {
+	err := stream.Send(&pb.SessionRequest{
+		Message: &pb.SessionRequest_Roll{Roll: &pb.RollRequest{DiceString: diceString}},
+	})
+	if err != nil {
+		t.Fatalf("could not roll: %v", err)
+	}
+}
+
+func expectEvent(t *testing.T, stream pb.Roller_SessionClient, callerID string, value int64, history bool) {
+	event, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("could not receive: %v", err)
+	}
+	if event.GetCallerId() != callerID || event.GetHistory() != history {
+		t.Fatalf("expected caller=%s history=%t, got=%v", callerID, history, event)
+	}
+	if event.GetRoll().GetData().GetValue() != value {
+		t.Fatalf("expected value=%d, got=%v", value, event.GetRoll())
+	}
+	if event.GetTimestamp() == nil {
+		t.Fatalf("event has no timestamp: %v", event)
+	}
+}
+
+func TestSessionBroadcastsRolls(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	server := newTestServer()
+	client := newTestClient(t, server)
+
+	alice := joinTable(t, ctx, client, "table", "alice")
+	bob := joinTable(t, ctx, client, "table", "bob")
+	other := joinTable(t, ctx, client, "other table", "eve")
+
+	// both joins must be registered before rolling, or bob could miss the roll
+	waitForPlayers(t, server, "table", 2)
+
+	sendRoll(t, alice, "d1qu3")
+	expectEvent(t, alice, "alice", 3, false)
+	expectEvent(t, bob, "alice", 3, false)
+
+	sendRoll(t, bob, "2 + 2")
+	expectEvent(t, alice, "bob", 4, false)
+	expectEvent(t, bob, "bob", 4, false)
+
+	carol := joinTable(t, ctx, client, "table", "carol")
+	expectEvent(t, carol, "alice", 3, true)
+	expectEvent(t, carol, "bob", 4, true)
+
+	other.CloseSend()
+	if _, err := other.Recv(); err != io.EOF {
+		t.Fatalf("expected the other table to see no rolls, got err=%v", err)
+	}
+}
+
+func TestSessionRequiresJoin(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client := newTestClient(t, newTestServer())
+
+	stream, err := client.Session(ctx)
+	if err != nil {
+		t.Fatalf("could not open session: %v", err)
+	}
+	sendRoll(t, stream, "d20")
+
+	_, err = stream.Recv()
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected FailedPrecondition, got=%v", err)
+	}
+}
+
+func TestSessionEndsOnShutdown(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	server := newTestServer()
+	client := newTestClient(t, server)
+
+	alice := joinTable(t, ctx, client, "table", "alice")
+	waitForPlayers(t, server, "table", 1)
+
+	server.tables.close()
+	if _, err := alice.Recv(); status.Code(err) != codes.Unavailable {
+		t.Fatalf("expected Unavailable, got=%v", err)
+	}
+}
+
+func waitForPlayers(t *testing.T, server *rollerServer, tableID string, players int) {
+	for i := 0; i < 100; i++ {
+		server.tables.mu.Lock()
+		tbl, ok := server.tables.tables[tableID]
+		joined := ok && len(tbl.subscribers) == players
+		server.tables.mu.Unlock()
+		if joined {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("expected %d players at table %s", players, tableID)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+
+	pb "github.com/daneofmanythings/calcuroller/internal/grpc/proto"
+	"github.com/daneofmanythings/calcuroller/internal/stats"
+)
+
+func (s *rollerServer) Stats(ctx context.Context, req *pb.StatsRequest) (*pb.StatsResponse, error) {
+	filter := historyFilter(req.GetCallerId(), req.GetTableId(), req.GetSince(), req.GetUntil())
+	report, err := stats.Collect(ctx, s.history, filter)
+	if err != nil {
+		return nil, historyError(err)
+	}
+
+	response := &pb.StatsResponse{
+		Callers: []*pb.CallerStats{},
+		Total:   callerStatsToProto(report.Total),
+	}
+	for _, caller := range report.Callers {
+		response.Callers = append(response.Callers, callerStatsToProto(caller))
+	}
+
+	return response, nil
+}
+
+func callerStatsToProto(caller *stats.CallerStats) *pb.CallerStats {
+	result := &pb.CallerStats{
+		CallerId: caller.CallerID,
+		Rolls:    caller.Rolls(),
+		Luck:     caller.Luck(),
+		Dice:     []*pb.DieStats{},
+	}
+	for _, die := range caller.SortedDice() {
+		chi := die.ChiSquare()
+		result.Dice = append(result.Dice, &pb.DieStats{
+			Size:             die.Size,
+			Rolls:            die.Rolls,
+			Mean:             die.Mean(),
+			ExpectedMean:     die.ExpectedMean(),
+			FaceCounts:       die.FaceCounts,
+			CritRate:         die.CritRate(),
+			FumbleRate:       die.FumbleRate(),
+			ChiSquare:        chi.Statistic,
+			DegreesOfFreedom: int32(chi.DegreesOfFreedom),
+			PValue:           chi.PValue,
+			Luck:             die.Luck(),
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/daneofmanythings/calcuroller/internal/grpc/proto"
+)
+
+func TestStats(t *testing.T) {
+	server := newTestServer()
+	ctx := context.Background()
+
+	requests := []*pb.RollRequest{
+		{DiceString: "d1qu3 + d4", CallerId: "alice"},
+		{DiceString: "d1qu2", CallerId: "bob"},
+		{DiceString: "5 + @", CallerId: "bob"},
+	}
+	for _, req := range requests {
+		if _, err := server.Roll(ctx, req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	res, err := server.Stats(ctx, &pb.StatsRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.GetCallers()) != 2 {
+		t.Fatalf("expected stats for 2 callers, got=%v", res.GetCallers())
+	}
+	if res.GetTotal().GetRolls() != 6 {
+		t.Fatalf("expected 6 dice rolled in total, got=%d", res.GetTotal().GetRolls())
+	}
+
+	dice := res.GetTotal().GetDice()
+	if len(dice) != 2 || dice[0].GetSize() != 1 || dice[1].GetSize() != 4 {
+		t.Fatalf("expected stats for a d1 and a d4, got=%v", dice)
+	}
+	if dice[0].GetRolls() != 5 || dice[0].GetFaceCounts()[0] != 5 || dice[0].GetMean() != 1 {
+		t.Fatalf("unexpected d1 stats: %v", dice[0])
+	}
+	if len(dice[1].GetFaceCounts()) != 4 || dice[1].GetExpectedMean() != 2.5 {
+		t.Fatalf("unexpected d4 stats: %v", dice[1])
+	}
+
+	res, err = server.Stats(ctx, &pb.StatsRequest{CallerId: "bob"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.GetCallers()) != 1 || res.GetCallers()[0].GetCallerId() != "bob" || res.GetTotal().GetRolls() != 2 {
+		t.Fatalf("expected only bob's 2 rolls, got=%v", res)
+	}
+}
@@ -0,0 +1,123 @@
+package history
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/object"
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 500
+)
+
+var (
+	ErrNotFound         = errors.New("roll not found")
+	ErrInvalidPageToken = errors.New("invalid page token")
+)
+
+// Record is a single roll as it was made. The seed in the result's metadata
+// is enough to reproduce the roll from its literal.
+type Record struct {
+	ID        string             `json:"id"`
+	CallerID  string             `json:"caller_id"`
+	TableID   string             `json:"table_id"`
+	Timestamp time.Time          `json:"timestamp"`
+	Result    *object.RollResult `json:"result"`
+}
+
+// Filter narrows the records returned by List. Zero fields match everything.
+// Since is inclusive and Until is exclusive.
+type Filter struct {
+	CallerID string
+	TableID  string
+	Since    time.Time
+	Until    time.Time
+}
+
+func (f Filter) Matches(rec *Record) bool {
+	if f.CallerID != "" && rec.CallerID != f.CallerID {
+		return false
+	}
+	if f.TableID != "" && rec.TableID != f.TableID {
+		return false
+	}
+	if !f.Since.IsZero() && rec.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !rec.Timestamp.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// Store is an append-only log of rolls.
+type Store interface {
+	// Append records a roll. Records must have a unique ID. A store may drop
+	// its oldest records to make room, see Open.
+	Append(ctx context.Context, rec *Record) error
+	// Get returns the record with the given ID, or ErrNotFound.
+	Get(ctx context.Context, id string) (*Record, error)
+	// List returns the records matching the filter, newest first. An empty
+	// page token starts from the newest record, and an empty next page token
+	// means there are no more records.
+	List(ctx context.Context, filter Filter, pageSize int, pageToken string) ([]*Record, string, error)
+	// Ping checks that the store can still be written to.
+	Ping(ctx context.Context) error
+	Close() error
+}
+
+// Open creates the store for a backend: "memory", "jsonl" or "sqlite". The
+// path is the file the jsonl and sqlite backends write to. The memory backend
+// keeps the newest memoryMaxRecords records, or every record if it is 0. The
+// jsonl and sqlite backends are audit logs, and keep every record.
+func Open(backend, path string, memoryMaxRecords int) (Store, error) {
+	switch backend {
+	case "memory":
+		return NewMemoryStore(memoryMaxRecords), nil
+	case "jsonl":
+		return OpenJSONLStore(path)
+	case "sqlite":
+		return OpenSQLiteStore(path)
+	default:
+		return nil, fmt.Errorf("unknown history backend %q. expected one of memory, jsonl, sqlite", backend)
+	}
+}
+
+// NewID returns a random ID for a record.
+func NewID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err) // crypto/rand never fails on supported platforms
+	}
+	return hex.EncodeToString(b)
+}
+
+func clampPageSize(pageSize int) int {
+	if pageSize <= 0 {
+		return DefaultPageSize
+	}
+	return min(pageSize, MaxPageSize)
+}
+
+// page tokens are the sequence number of the last record of the previous page
+func encodePageToken(seq int64) string {
+	return strconv.FormatInt(seq, 10)
+}
+
+func decodePageToken(token string) (int64, error) {
+	if token == "" {
+		return -1, nil
+	}
+	seq, err := strconv.ParseInt(token, 10, 64)
+	if err != nil || seq < 0 {
+		return 0, ErrInvalidPageToken
+	}
+	return seq, nil
+}
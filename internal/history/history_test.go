@@ -0,0 +1,283 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/object"
+)
+
+// every store implementation is run through the same tests
+var testStoreOpeners = map[string]func(t *testing.T) Store{
+	"memory": func(t *testing.T) Store {
+		return NewMemoryStore(0)
+	},
+	"jsonl": func(t *testing.T) Store {
+		store, err := OpenJSONLStore(filepath.Join(t.TempDir(), "history.jsonl"))
+		if err != nil {
+			t.Fatalf("could not open store: %v", err)
+		}
+		return store
+	},
+}
+
+var testEpoch = time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestRecord(i int, callerID, tableID string) *Record {
+	md := object.NewSeededMetadata(int64(i))
+	md.Add(object.INTEGER_NODE, 0, 1, object.DiceData{Literal: fmt.Sprintf("%d", i), Value: int64(i)})
+	return &Record{
+		ID:        fmt.Sprintf("roll-%d", i),
+		CallerID:  callerID,
+		TableID:   tableID,
+		Timestamp: testEpoch.Add(time.Duration(i) * time.Minute),
+		Result:    &object.RollResult{Literal: fmt.Sprintf("%d", i), Value: int64(i), Metadata: md},
+	}
+}
+
+// appends 10 records: even ones by alice at table a, odd ones by bob at table b
+func appendTestRecords(t *testing.T, store Store) {
+	for i := 0; i < 10; i++ {
+		rec := newTestRecord(i, "alice", "a")
+		if i%2 == 1 {
+			rec = newTestRecord(i, "bob", "b")
+		}
+		if err := store.Append(context.Background(), rec); err != nil {
+			t.Fatalf("could not append: %v", err)
+		}
+	}
+}
+
+func recordIDs(records []*Record) []string {
+	ids := []string{}
+	for _, rec := range records {
+		ids = append(ids, rec.ID)
+	}
+	return ids
+}
+
+func TestStoreGet(t *testing.T) {
+	for name, open := range testStoreOpeners {
+		t.Run(name, func(t *testing.T) {
+			store := open(t)
+			defer store.Close()
+			appendTestRecords(t, store)
+
+			rec, err := store.Get(context.Background(), "roll-3")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if rec.CallerID != "bob" || rec.TableID != "b" || rec.Result.Value != 3 || rec.Result.Metadata.Seed != 3 {
+				t.Fatalf("unexpected record: %+v", rec)
+			}
+			if !rec.Timestamp.Equal(testEpoch.Add(3 * time.Minute)) {
+				t.Fatalf("expected timestamp=%s, got=%s", testEpoch.Add(3*time.Minute), rec.Timestamp)
+			}
+
+			_, err = store.Get(context.Background(), "nope")
+			if !errors.Is(err, ErrNotFound) {
+				t.Fatalf("expected ErrNotFound, got=%v", err)
+			}
+		})
+	}
+}
+
+func TestStorePing(t *testing.T) {
+	for name, open := range testStoreOpeners {
+		t.Run(name, func(t *testing.T) {
+			store := open(t)
+			if err := store.Ping(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			store.Close()
+		})
+	}
+}
+
+func TestStoreRejectsDuplicateIDs(t *testing.T) {
+	for name, open := range testStoreOpeners {
+		t.Run(name, func(t *testing.T) {
+			store := open(t)
+			defer store.Close()
+
+			if err := store.Append(context.Background(), newTestRecord(1, "alice", "a")); err != nil {
+				t.Fatalf("could not append: %v", err)
+			}
+			if err := store.Append(context.Background(), newTestRecord(1, "alice", "a")); err == nil {
+				t.Fatalf("expected an error appending a duplicate id")
+			}
+		})
+	}
+}
+
+func TestStoreList(t *testing.T) {
+	testCases := []struct {
+		name     string
+		filter   Filter
+		pageSize int
+		expected [][]string
+	}{
+		{"everything", Filter{}, 0, [][]string{
+			{"roll-9", "roll-8", "roll-7", "roll-6", "roll-5", "roll-4", "roll-3", "roll-2", "roll-1", "roll-0"},
+		}},
+		{"pages", Filter{}, 4, [][]string{
+			{"roll-9", "roll-8", "roll-7", "roll-6"},
+			{"roll-5", "roll-4", "roll-3", "roll-2"},
+			{"roll-1", "roll-0"},
+		}},
+		{"exact pages", Filter{}, 5, [][]string{
+			{"roll-9", "roll-8", "roll-7", "roll-6", "roll-5"},
+			{"roll-4", "roll-3", "roll-2", "roll-1", "roll-0"},
+		}},
+		{"caller", Filter{CallerID: "alice"}, 2, [][]string{
+			{"roll-8", "roll-6"},
+			{"roll-4", "roll-2"},
+			{"roll-0"},
+		}},
+		{"table", Filter{TableID: "b"}, 0, [][]string{
+			{"roll-9", "roll-7", "roll-5", "roll-3", "roll-1"},
+		}},
+		{"time range", Filter{Since: testEpoch.Add(2 * time.Minute), Until: testEpoch.Add(5 * time.Minute)}, 0, [][]string{
+			{"roll-4", "roll-3", "roll-2"},
+		}},
+		{"no matches", Filter{CallerID: "carol"}, 0, [][]string{{}}},
+	}
+
+	for name, open := range testStoreOpeners {
+		t.Run(name, func(t *testing.T) {
+			store := open(t)
+			defer store.Close()
+			appendTestRecords(t, store)
+
+			for _, tc := range testCases {
+				t.Run(tc.name, func(t *testing.T) {
+					token := ""
+					for i, expected := range tc.expected {
+						records, next, err := store.List(context.Background(), tc.filter, tc.pageSize, token)
+						if err != nil {
+							t.Fatalf("page %d: unexpected error: %v", i, err)
+						}
+						ids := recordIDs(records)
+						if fmt.Sprint(ids) != fmt.Sprint(expected) {
+							t.Fatalf("page %d: expected=%v, got=%v", i, expected, ids)
+						}
+						isLast := i == len(tc.expected)-1
+						if isLast != (next == "") {
+							t.Fatalf("page %d: unexpected next page token %q", i, next)
+						}
+						token = next
+					}
+				})
+			}
+
+			_, _, err := store.List(context.Background(), Filter{}, 0, "not a token")
+			if !errors.Is(err, ErrInvalidPageToken) {
+				t.Fatalf("expected ErrInvalidPageToken, got=%v", err)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreRetention(t *testing.T) {
+	store := NewMemoryStore(4)
+
+	// a page token from before the oldest records are dropped
+	for i := 0; i < 4; i++ {
+		if err := store.Append(context.Background(), newTestRecord(i, "alice", "a")); err != nil {
+			t.Fatalf("could not append: %v", err)
+		}
+	}
+	_, token, err := store.List(context.Background(), Filter{}, 2, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := store.Append(context.Background(), newTestRecord(4, "alice", "a")); err != nil {
+		t.Fatalf("could not append: %v", err)
+	}
+
+	records, _, err := store.List(context.Background(), Filter{}, 0, token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fmt.Sprint(recordIDs(records)) != "[roll-1]" {
+		t.Fatalf("expected=[roll-1], got=%v", recordIDs(records))
+	}
+	records, _, err = store.List(context.Background(), Filter{}, 0, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fmt.Sprint(recordIDs(records)) != "[roll-4 roll-3 roll-2 roll-1]" {
+		t.Fatalf("expected=[roll-4 roll-3 roll-2 roll-1], got=%v", recordIDs(records))
+	}
+	if _, err := store.Get(context.Background(), "roll-0"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a dropped record, got=%v", err)
+	}
+}
+
+func TestJSONLStoreReopens(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+
+	store, err := OpenJSONLStore(path)
+	if err != nil {
+		t.Fatalf("could not open store: %v", err)
+	}
+	appendTestRecords(t, store)
+	store.Close()
+
+	// simulate a write interrupted halfway through a line
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatalf("could not open file: %v", err)
+	}
+	file.WriteString(`{"id": "roll-10", "calle`)
+	file.Close()
+
+	store, err = OpenJSONLStore(path)
+	if err != nil {
+		t.Fatalf("could not reopen store: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.Append(context.Background(), newTestRecord(10, "carol", "c")); err != nil {
+		t.Fatalf("could not append: %v", err)
+	}
+	records, _, err := store.List(context.Background(), Filter{}, 3, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fmt.Sprint(recordIDs(records)) != "[roll-10 roll-9 roll-8]" {
+		t.Fatalf("unexpected records after reopening: %v", recordIDs(records))
+	}
+}
+
+func TestOpen(t *testing.T) {
+	if _, err := Open("memory", "", 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := Open("jsonl", "", 0); err == nil {
+		t.Fatalf("expected an error opening jsonl without a path")
+	}
+	if _, err := Open("postgres", "db", 0); err == nil {
+		t.Fatalf("expected an error opening an unknown backend")
+	}
+
+	// the jsonl audit log keeps every record, however few the memory backend keeps
+	store, err := Open("jsonl", filepath.Join(t.TempDir(), "history.jsonl"), 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer store.Close()
+	appendTestRecords(t, store)
+	records, _, err := store.List(context.Background(), Filter{}, 0, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(records) != 10 {
+		t.Fatalf("expected 10 records, got=%v", recordIDs(records))
+	}
+}
@@ -0,0 +1,120 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// JSONLStore appends every record as a line of JSON to a file. The whole file
+// is read into memory on open, and reads are served from memory.
+type JSONLStore struct {
+	mu     sync.Mutex
+	file   *os.File
+	memory *MemoryStore
+}
+
+func OpenJSONLStore(path string) (*JSONLStore, error) {
+	if path == "" {
+		return nil, fmt.Errorf("the jsonl history backend needs a path")
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	memory := NewMemoryStore(0)
+	if err := loadJSONL(file, memory); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("could not load history from %s: %w", path, err)
+	}
+
+	return &JSONLStore{file: file, memory: memory}, nil
+}
+
+// loadJSONL reads every record in the file and leaves the file positioned
+// for appending. A final line without a newline is the remains of an
+// interrupted write, so it is truncated instead of failing the load.
+func loadJSONL(file *os.File, memory *MemoryStore) error {
+	reader := bufio.NewReader(file)
+	var offset int64
+
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) > 0 {
+				if err := file.Truncate(offset); err != nil {
+					return err
+				}
+			}
+			break
+		}
+		if err != nil {
+			return err
+		}
+		offset += int64(len(line))
+
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		rec := &Record{}
+		if err := json.Unmarshal(line, rec); err != nil {
+			return fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		if err := memory.append(rec); err != nil {
+			return fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+	}
+
+	_, err := file.Seek(offset, io.SeekStart)
+	return err
+}
+
+func (j *JSONLStore) Append(ctx context.Context, rec *Record) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, err := j.memory.Get(ctx, rec.ID); err == nil {
+		return fmt.Errorf("duplicate record id %q", rec.ID)
+	}
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return j.memory.Append(ctx, rec)
+}
+
+func (j *JSONLStore) Get(ctx context.Context, id string) (*Record, error) {
+	return j.memory.Get(ctx, id)
+}
+
+func (j *JSONLStore) List(ctx context.Context, filter Filter, pageSize int, pageToken string) ([]*Record, string, error) {
+	return j.memory.List(ctx, filter, pageSize, pageToken)
+}
+
+func (j *JSONLStore) Ping(ctx context.Context) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	_, err := j.file.Stat()
+	return err
+}
+
+func (j *JSONLStore) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.file.Close()
+}
@@ -0,0 +1,100 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// MemoryStore keeps the newest maxRecords records in memory, or every record
+// if it is 0. History is lost on restart.
+type MemoryStore struct {
+	mu         sync.RWMutex
+	maxRecords int
+	records    []*Record
+	first      int64 // the sequence number of records[0]
+	index      map[string]int64
+}
+
+func NewMemoryStore(maxRecords int) *MemoryStore {
+	return &MemoryStore{
+		maxRecords: maxRecords,
+		records:    []*Record{},
+		index:      make(map[string]int64),
+	}
+}
+
+func (m *MemoryStore) Append(ctx context.Context, rec *Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.append(rec)
+}
+
+func (m *MemoryStore) append(rec *Record) error {
+	if _, ok := m.index[rec.ID]; ok {
+		return fmt.Errorf("duplicate record id %q", rec.ID)
+	}
+	m.index[rec.ID] = m.first + int64(len(m.records))
+	m.records = append(m.records, rec)
+
+	// sequence numbers keep counting up, so page tokens stay valid as the
+	// oldest records are dropped
+	if m.maxRecords > 0 && len(m.records) > m.maxRecords {
+		delete(m.index, m.records[0].ID)
+		m.records[0] = nil
+		m.records = m.records[1:]
+		m.first++
+	}
+	return nil
+}
+
+func (m *MemoryStore) Get(ctx context.Context, id string) (*Record, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	seq, ok := m.index[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return m.records[seq-m.first], nil
+}
+
+func (m *MemoryStore) List(ctx context.Context, filter Filter, pageSize int, pageToken string) ([]*Record, string, error) {
+	pageSize = clampPageSize(pageSize)
+	start, err := decodePageToken(pageToken)
+	if err != nil {
+		return nil, "", err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	end := m.first + int64(len(m.records))
+	if start < 0 || start > end {
+		start = end
+	}
+
+	// one record past the page is collected to know if there is a next page
+	records := []*Record{}
+	seqs := []int64{}
+	for seq := start - 1; seq >= m.first && len(records) <= pageSize; seq-- {
+		if rec := m.records[seq-m.first]; filter.Matches(rec) {
+			records = append(records, rec)
+			seqs = append(seqs, seq)
+		}
+	}
+
+	if len(records) > pageSize {
+		return records[:pageSize], encodePageToken(seqs[pageSize-1]), nil
+	}
+	return records, "", nil
+}
+
+func (m *MemoryStore) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (m *MemoryStore) Close() error {
+	return nil
+}
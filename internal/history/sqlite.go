@@ -0,0 +1,173 @@
+//go:build cgo
+
+package history
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/object"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS rolls (
+	seq       INTEGER PRIMARY KEY AUTOINCREMENT,
+	id        TEXT NOT NULL UNIQUE,
+	caller_id TEXT NOT NULL,
+	table_id  TEXT NOT NULL,
+	timestamp INTEGER NOT NULL,
+	result    TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS rolls_caller_id ON rolls (caller_id, seq);
+CREATE INDEX IF NOT EXISTS rolls_table_id ON rolls (table_id, seq);
+`
+
+// SQLiteStore keeps records in an embedded SQLite database. It is only
+// available in builds with cgo enabled.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+func OpenSQLiteStore(path string) (Store, error) {
+	if path == "" {
+		return nil, fmt.Errorf("the sqlite history backend needs a path")
+	}
+
+	db, err := sql.Open("sqlite3", path+"?_journal_mode=WAL&_busy_timeout=5000")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("could not create the history schema in %s: %w", path, err)
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+func (s *SQLiteStore) Append(ctx context.Context, rec *Record) error {
+	result, err := json.Marshal(rec.Result)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx,
+		`INSERT INTO rolls (id, caller_id, table_id, timestamp, result) VALUES (?, ?, ?, ?, ?)`,
+		rec.ID, rec.CallerID, rec.TableID, rec.Timestamp.UnixNano(), string(result),
+	)
+	return err
+}
+
+func (s *SQLiteStore) Get(ctx context.Context, id string) (*Record, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT seq, id, caller_id, table_id, timestamp, result FROM rolls WHERE id = ?`, id,
+	)
+
+	rec, _, err := scanRecord(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return rec, err
+}
+
+func (s *SQLiteStore) List(ctx context.Context, filter Filter, pageSize int, pageToken string) ([]*Record, string, error) {
+	pageSize = clampPageSize(pageSize)
+	start, err := decodePageToken(pageToken)
+	if err != nil {
+		return nil, "", err
+	}
+
+	conditions := []string{}
+	args := []any{}
+	if start >= 0 {
+		conditions = append(conditions, "seq < ?")
+		args = append(args, start)
+	}
+	if filter.CallerID != "" {
+		conditions = append(conditions, "caller_id = ?")
+		args = append(args, filter.CallerID)
+	}
+	if filter.TableID != "" {
+		conditions = append(conditions, "table_id = ?")
+		args = append(args, filter.TableID)
+	}
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, "timestamp >= ?")
+		args = append(args, filter.Since.UnixNano())
+	}
+	if !filter.Until.IsZero() {
+		conditions = append(conditions, "timestamp < ?")
+		args = append(args, filter.Until.UnixNano())
+	}
+
+	query := `SELECT seq, id, caller_id, table_id, timestamp, result FROM rolls`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	// one record past the page is selected to know if there is a next page
+	query += " ORDER BY seq DESC LIMIT ?"
+	args = append(args, pageSize+1)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	records := []*Record{}
+	seqs := []int64{}
+	for rows.Next() {
+		rec, seq, err := scanRecord(rows)
+		if err != nil {
+			return nil, "", err
+		}
+		records = append(records, rec)
+		seqs = append(seqs, seq)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	if len(records) > pageSize {
+		return records[:pageSize], encodePageToken(seqs[pageSize-1]), nil
+	}
+	return records, "", nil
+}
+
+func (s *SQLiteStore) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanRecord(row scanner) (*Record, int64, error) {
+	var (
+		seq       int64
+		timestamp int64
+		result    string
+	)
+	rec := &Record{Result: &object.RollResult{}}
+
+	err := row.Scan(&seq, &rec.ID, &rec.CallerID, &rec.TableID, &timestamp, &result)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := json.Unmarshal([]byte(result), rec.Result); err != nil {
+		return nil, 0, fmt.Errorf("could not decode roll %s: %w", rec.ID, err)
+	}
+	rec.Timestamp = time.Unix(0, timestamp).UTC()
+
+	return rec, seq, nil
+}
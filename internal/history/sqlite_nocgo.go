@@ -0,0 +1,9 @@
+//go:build !cgo
+
+package history
+
+import "fmt"
+
+func OpenSQLiteStore(path string) (Store, error) {
+	return nil, fmt.Errorf("the sqlite history backend is only available in builds with cgo enabled")
+}
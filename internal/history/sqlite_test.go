@@ -0,0 +1,18 @@
+//go:build cgo
+
+package history
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func init() {
+	testStoreOpeners["sqlite"] = func(t *testing.T) Store {
+		store, err := OpenSQLiteStore(filepath.Join(t.TempDir(), "history.db"))
+		if err != nil {
+			t.Fatalf("could not open store: %v", err)
+		}
+		return store
+	}
+}
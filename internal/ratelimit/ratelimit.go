@@ -0,0 +1,218 @@
+// Package ratelimit limits how often each caller can call the gRPC service
+// and its HTTP gateway. Every caller gets a token bucket per budget: a call
+// takes a token, and tokens refill at a sustained rate up to a burst.
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/daneofmanythings/calcuroller/internal/auth"
+	"golang.org/x/time/rate"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// sweepInterval is how often buckets that have refilled are dropped, so
+// callers that went away don't use memory forever.
+const sweepInterval = time.Minute
+
+// Budget is how many calls a caller is allowed.
+type Budget struct {
+	Rate  float64 // calls per second, sustained
+	Burst int     // calls allowed at once
+}
+
+// Limiter keeps a bucket per key, all with the same budget.
+type Limiter struct {
+	budget Budget
+	now    func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*rate.Limiter
+	lastSweep time.Time
+}
+
+func NewLimiter(budget Budget) *Limiter {
+	return &Limiter{
+		budget:  budget,
+		now:     time.Now,
+		buckets: make(map[string]*rate.Limiter),
+	}
+}
+
+// Allow takes a token from key's bucket. If the bucket is empty, no token is
+// taken and Allow returns how long until one is available.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	now := l.now()
+	reservation := l.bucket(key, now).ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+// Wait blocks until a token can be taken from key's bucket, or ctx is done.
+func (l *Limiter) Wait(ctx context.Context, key string) error {
+	return l.bucket(key, l.now()).Wait(ctx)
+}
+
+func (l *Limiter) bucket(key string, now time.Time) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) > sweepInterval {
+		// a full bucket is no different from a new one
+		for key, bucket := range l.buckets {
+			if bucket.TokensAt(now) >= float64(l.budget.Burst) {
+				delete(l.buckets, key)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = rate.NewLimiter(rate.Limit(l.budget.Rate), l.budget.Burst)
+		l.buckets[key] = bucket
+	}
+	return bucket
+}
+
+// Interceptor charges every call to its caller: the identity it was
+// authenticated as, or otherwise the address it came from. It has to run
+// after authentication.
+type Interceptor struct {
+	limiter *Limiter
+	methods map[string]*Limiter
+}
+
+func NewInterceptor(budget Budget) *Interceptor {
+	return &Interceptor{limiter: NewLimiter(budget), methods: make(map[string]*Limiter)}
+}
+
+// Limit gives expensive methods a budget of their own, shared between them
+// and separate from the default one. methods are full gRPC method names, ex:
+// /google.rpc.Roller/RollBatch.
+func (i *Interceptor) Limit(budget Budget, methods ...string) *Interceptor {
+	limiter := NewLimiter(budget)
+	for _, method := range methods {
+		i.methods[method] = limiter
+	}
+	return i
+}
+
+func (i *Interceptor) limiterFor(method string) *Limiter {
+	if limiter, ok := i.methods[method]; ok {
+		return limiter
+	}
+	return i.limiter
+}
+
+func (i *Interceptor) allow(ctx context.Context, method string, addr string) error {
+	ok, delay := i.limiterFor(method).Allow(callerKey(ctx, addr))
+	if ok {
+		return nil
+	}
+
+	st := status.Newf(codes.ResourceExhausted, "rate limit exceeded for %s, retry in %v", method, delay.Round(time.Millisecond))
+	detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(delay)})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+func (i *Interceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := i.allow(ctx, info.FullMethod, peerAddr(ctx)); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Stream charges opening a stream like a unary call. Every message received
+// on the stream is charged too, but waits for a token instead of failing, so
+// a chatty stream is slowed down rather than cut off.
+func (i *Interceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := stream.Context()
+		addr := peerAddr(ctx)
+		if err := i.allow(ctx, info.FullMethod, addr); err != nil {
+			return err
+		}
+		return handler(srv, &limitedStream{
+			ServerStream: stream,
+			limiter:      i.limiterFor(info.FullMethod),
+			key:          callerKey(ctx, addr),
+		})
+	}
+}
+
+type limitedStream struct {
+	grpc.ServerStream
+	limiter *Limiter
+	key     string
+}
+
+func (s *limitedStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if err := s.limiter.Wait(s.Context(), s.key); err != nil {
+		return status.FromContextError(err).Err()
+	}
+	return nil
+}
+
+// HTTP charges requests to an http.Handler serving method, so the gateway
+// and gRPC share a caller's budget. onError writes the response for
+// requests over the limit.
+func (i *Interceptor) HTTP(method string, next http.Handler, onError func(http.ResponseWriter, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := i.allow(r.Context(), method, r.RemoteAddr); err != nil {
+			onError(w, err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RetryDelay is how long a rate limited call should wait before retrying.
+func RetryDelay(err error) (time.Duration, bool) {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok {
+			return info.GetRetryDelay().AsDuration(), true
+		}
+	}
+	return 0, false
+}
+
+// callerKey is who a call is charged to. addr is the host:port the call came
+// from. Its port is dropped, so reconnecting doesn't get a caller a new bucket.
+func callerKey(ctx context.Context, addr string) string {
+	if identity, ok := auth.FromContext(ctx); ok {
+		return "identity:" + identity.ID
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	return "address:" + host
+}
+
+func peerAddr(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return ""
+}
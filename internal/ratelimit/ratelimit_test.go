@@ -0,0 +1,140 @@
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/daneofmanythings/calcuroller/internal/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func newTestLimiter(budget Budget) (*Limiter, *time.Time) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewLimiter(budget)
+	limiter.now = func() time.Time { return now }
+	return limiter, &now
+}
+
+func TestLimiter(t *testing.T) {
+	limiter, now := newTestLimiter(Budget{Rate: 2, Burst: 3})
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := limiter.Allow("alice"); !ok {
+			t.Fatalf("expected call %d of the burst to be allowed", i)
+		}
+	}
+
+	ok, delay := limiter.Allow("alice")
+	if ok {
+		t.Fatalf("expected the call after the burst to be limited")
+	}
+	if delay != 500*time.Millisecond {
+		t.Fatalf("expected=%v, got=%v", 500*time.Millisecond, delay)
+	}
+
+	if ok, _ := limiter.Allow("bob"); !ok {
+		t.Fatalf("expected callers to have their own buckets")
+	}
+
+	*now = now.Add(delay)
+	if ok, _ := limiter.Allow("alice"); !ok {
+		t.Fatalf("expected a token to refill after %v", delay)
+	}
+	if ok, _ := limiter.Allow("alice"); ok {
+		t.Fatalf("expected only one token to refill")
+	}
+}
+
+func TestLimiterSweep(t *testing.T) {
+	limiter, now := newTestLimiter(Budget{Rate: 1, Burst: 1})
+
+	limiter.Allow("alice")
+	limiter.Allow("bob")
+
+	*now = now.Add(2 * sweepInterval)
+	limiter.Allow("bob")
+
+	if _, ok := limiter.buckets["alice"]; ok {
+		t.Fatalf("expected alice's refilled bucket to be swept")
+	}
+	if _, ok := limiter.buckets["bob"]; !ok {
+		t.Fatalf("expected bob's bucket to be kept")
+	}
+}
+
+func TestInterceptorUnary(t *testing.T) {
+	interceptor := NewInterceptor(Budget{Rate: 1, Burst: 2}).Limit(Budget{Rate: 1, Burst: 1}, "/expensive")
+	unary := interceptor.Unary()
+	handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+
+	fromAddr := func(addr string) context.Context {
+		return peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(addr), Port: 1234}})
+	}
+	alice := auth.NewContext(fromAddr("10.0.0.1"), &auth.Identity{ID: "alice"})
+	bot := fromAddr("10.0.0.2")
+
+	testCases := []struct {
+		name         string
+		ctx          context.Context
+		method       string
+		expectedCode codes.Code
+	}{
+		{"first", bot, "/cheap", codes.OK},
+		{"second", bot, "/cheap", codes.OK},
+		{"over the burst", bot, "/cheap", codes.ResourceExhausted},
+		{"separate budget", bot, "/expensive", codes.OK},
+		{"separate budget over the burst", bot, "/expensive", codes.ResourceExhausted},
+		{"other caller", fromAddr("10.0.0.3"), "/cheap", codes.OK},
+		{"identity, not address", alice, "/expensive", codes.OK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := unary(tc.ctx, nil, &grpc.UnaryServerInfo{FullMethod: tc.method}, handler)
+			if status.Code(err) != tc.expectedCode {
+				t.Fatalf("expected=%v, got=%v", tc.expectedCode, err)
+			}
+			if tc.expectedCode != codes.ResourceExhausted {
+				return
+			}
+			delay, ok := RetryDelay(err)
+			if !ok || delay <= 0 || delay > time.Second {
+				t.Fatalf("expected a retry delay of at most 1s, got=%v", delay)
+			}
+		})
+	}
+}
+
+func TestInterceptorHTTP(t *testing.T) {
+	interceptor := NewInterceptor(Budget{Rate: 1, Burst: 1})
+	handler := interceptor.HTTP("/roll", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), func(w http.ResponseWriter, err error) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	// a new connection from the same address is the same caller
+	requests := []struct {
+		remoteAddr   string
+		expectedCode int
+	}{
+		{"10.0.0.1:1000", http.StatusOK},
+		{"10.0.0.1:2000", http.StatusTooManyRequests},
+		{"10.0.0.2:1000", http.StatusOK},
+	}
+
+	for _, req := range requests {
+		r := httptest.NewRequest(http.MethodGet, "/roll", nil)
+		r.RemoteAddr = req.remoteAddr
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != req.expectedCode {
+			t.Fatalf("%s: expected=%d, got=%d", req.remoteAddr, req.expectedCode, w.Code)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package stats
+
+import "math"
+
+// chiSquarePValue is the probability of a chi-square statistic at least as
+// large as x with df degrees of freedom, Q(df/2, x/2).
+func chiSquarePValue(x float64, df int) float64 {
+	if df <= 0 {
+		return 1
+	}
+	if x <= 0 {
+		return 1
+	}
+	return regularizedGammaQ(float64(df)/2, x/2)
+}
+
+const (
+	gammaIterations = 500
+	gammaEpsilon    = 1e-14
+)
+
+// regularizedGammaQ is the regularized upper incomplete gamma function. The
+// series converges quickly for x < a+1 and the continued fraction for the
+// rest. See Numerical Recipes, section 6.2.
+func regularizedGammaQ(a, x float64) float64 {
+	if x < a+1 {
+		return 1 - gammaSeries(a, x)
+	}
+	return gammaContinuedFraction(a, x)
+}
+
+// gammaSeries is the lower regularized incomplete gamma function P(a, x).
+func gammaSeries(a, x float64) float64 {
+	lgamma, _ := math.Lgamma(a)
+	term := 1 / a
+	sum := term
+	for n := 1; n < gammaIterations; n++ {
+		term *= x / (a + float64(n))
+		sum += term
+		if math.Abs(term) < math.Abs(sum)*gammaEpsilon {
+			break
+		}
+	}
+	return sum * math.Exp(-x+a*math.Log(x)-lgamma)
+}
+
+// gammaContinuedFraction is Q(a, x) evaluated with Lentz's method.
+func gammaContinuedFraction(a, x float64) float64 {
+	const tiny = 1e-300
+
+	lgamma, _ := math.Lgamma(a)
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for n := 1; n < gammaIterations; n++ {
+		an := -float64(n) * (float64(n) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < gammaEpsilon {
+			break
+		}
+	}
+	return math.Exp(-x+a*math.Log(x)-lgamma) * h
+}
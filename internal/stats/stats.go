@@ -0,0 +1,239 @@
+// Package stats summarizes the dice rolled in the roll history: how often
+// each face came up, how lucky each caller has been, and whether the rolls
+// look like they came from fair dice.
+package stats
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/daneofmanythings/calcuroller/internal/history"
+)
+
+// MaxFaceCounts is the most faces a die has its faces counted for. Larger
+// dice, which the history may hold however large, keep only their sum, crits
+// and fumbles, and skip the chi-square test.
+const MaxFaceCounts = 1000
+
+// DieStats summarizes every raw roll of a single die size. Raw rolls are
+// used because they are uniform draws, before any tag clamps or drops them.
+type DieStats struct {
+	Size    uint32
+	Rolls   int64
+	Sum     int64
+	Crits   int64
+	Fumbles int64
+	// FaceCounts[i] is how many times face i+1 was rolled. nil for dice with
+	// more than MaxFaceCounts faces
+	FaceCounts []int64
+}
+
+func newDieStats(size uint32) *DieStats {
+	d := &DieStats{Size: size}
+	if size <= MaxFaceCounts {
+		d.FaceCounts = make([]int64, size)
+	}
+	return d
+}
+
+func (d *DieStats) add(roll uint32) {
+	if roll < 1 || roll > d.Size {
+		return
+	}
+	d.Rolls++
+	d.Sum += int64(roll)
+	if roll == d.Size {
+		d.Crits++
+	}
+	if roll == 1 {
+		d.Fumbles++
+	}
+	if d.FaceCounts != nil {
+		d.FaceCounts[roll-1]++
+	}
+}
+
+func (d *DieStats) Mean() float64 {
+	if d.Rolls == 0 {
+		return 0
+	}
+	return float64(d.Sum) / float64(d.Rolls)
+}
+
+func (d *DieStats) ExpectedMean() float64 {
+	return float64(d.Size+1) / 2
+}
+
+// variance of a single roll of a fair die
+func (d *DieStats) variance() float64 {
+	return (float64(d.Size)*float64(d.Size) - 1) / 12
+}
+
+// CritRate is the fraction of rolls that came up the highest face. Like the
+// renderer, a d1 never crits or fumbles.
+func (d *DieStats) CritRate() float64 {
+	if d.Rolls == 0 || d.Size < 2 {
+		return 0
+	}
+	return float64(d.Crits) / float64(d.Rolls)
+}
+
+// FumbleRate is the fraction of rolls that came up 1.
+func (d *DieStats) FumbleRate() float64 {
+	if d.Rolls == 0 || d.Size < 2 {
+		return 0
+	}
+	return float64(d.Fumbles) / float64(d.Rolls)
+}
+
+// Luck is how many standard deviations the sum of the rolls is above what
+// fair dice would have rolled on average. Negative is unlucky.
+func (d *DieStats) Luck() float64 {
+	return luck(d.Sum, d.Rolls, d.ExpectedMean(), d.variance())
+}
+
+// ChiSquare tests the face counts against a fair die. A small p-value means
+// the rolls are unlikely to come from a fair die. The test is unreliable
+// until every face is expected at least 5 times, ex: 100 rolls of a d20.
+// Dice whose faces aren't counted are not tested.
+func (d *DieStats) ChiSquare() ChiSquare {
+	if d.Rolls == 0 || d.Size < 2 || d.FaceCounts == nil {
+		return ChiSquare{PValue: 1}
+	}
+
+	expected := float64(d.Rolls) / float64(d.Size)
+	statistic := 0.0
+	for _, count := range d.FaceCounts {
+		diff := float64(count) - expected
+		statistic += diff * diff / expected
+	}
+
+	df := int(d.Size) - 1
+	return ChiSquare{
+		Statistic:        statistic,
+		DegreesOfFreedom: df,
+		PValue:           chiSquarePValue(statistic, df),
+	}
+}
+
+type ChiSquare struct {
+	Statistic        float64
+	DegreesOfFreedom int
+	PValue           float64
+}
+
+// CallerStats summarizes the rolls of one caller, by die size.
+type CallerStats struct {
+	CallerID string
+	Dice     map[uint32]*DieStats
+}
+
+func newCallerStats(callerID string) *CallerStats {
+	return &CallerStats{CallerID: callerID, Dice: make(map[uint32]*DieStats)}
+}
+
+func (c *CallerStats) add(size, roll uint32) {
+	die, ok := c.Dice[size]
+	if !ok {
+		die = newDieStats(size)
+		c.Dice[size] = die
+	}
+	die.add(roll)
+}
+
+func (c *CallerStats) Rolls() int64 {
+	var rolls int64
+	for _, die := range c.Dice {
+		rolls += die.Rolls
+	}
+	return rolls
+}
+
+// SortedDice returns the caller's dice from the smallest size to the largest.
+func (c *CallerStats) SortedDice() []*DieStats {
+	dice := []*DieStats{}
+	for _, die := range c.Dice {
+		dice = append(dice, die)
+	}
+	sort.Slice(dice, func(i, j int) bool { return dice[i].Size < dice[j].Size })
+	return dice
+}
+
+// Luck combines the luck of every die size into a single score, so callers
+// rolling different dice can be compared.
+func (c *CallerStats) Luck() float64 {
+	var deviation, variance float64
+	for _, die := range c.Dice {
+		deviation += float64(die.Sum) - float64(die.Rolls)*die.ExpectedMean()
+		variance += float64(die.Rolls) * die.variance()
+	}
+	if variance == 0 {
+		return 0
+	}
+	return deviation / math.Sqrt(variance)
+}
+
+// Report is the stats of every caller in a slice of the history.
+type Report struct {
+	Callers []*CallerStats // unluckiest first
+	Total   *CallerStats   // every caller's rolls together, with an empty CallerID
+}
+
+// Collect builds a report from the records matching the filter. Failed
+// rolls are left out.
+func Collect(ctx context.Context, store history.Store, filter history.Filter) (*Report, error) {
+	callers := map[string]*CallerStats{}
+	total := newCallerStats("")
+
+	pageToken := ""
+	for {
+		records, next, err := store.List(ctx, filter, history.MaxPageSize, pageToken)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rec := range records {
+			if rec.Result == nil || rec.Result.Error != "" || rec.Result.Metadata == nil {
+				continue
+			}
+			caller, ok := callers[rec.CallerID]
+			if !ok {
+				caller = newCallerStats(rec.CallerID)
+				callers[rec.CallerID] = caller
+			}
+			for _, data := range rec.Result.Metadata.Dice() {
+				for _, roll := range data.RawRolls() {
+					caller.add(data.Size, roll)
+					total.add(data.Size, roll)
+				}
+			}
+		}
+
+		if next == "" {
+			break
+		}
+		pageToken = next
+	}
+
+	report := &Report{Callers: []*CallerStats{}, Total: total}
+	for _, caller := range callers {
+		report.Callers = append(report.Callers, caller)
+	}
+	sort.Slice(report.Callers, func(i, j int) bool {
+		a, b := report.Callers[i], report.Callers[j]
+		if a.Luck() != b.Luck() {
+			return a.Luck() < b.Luck()
+		}
+		return a.CallerID < b.CallerID
+	})
+
+	return report, nil
+}
+
+func luck(sum, rolls int64, mean, variance float64) float64 {
+	if rolls == 0 || variance == 0 {
+		return 0
+	}
+	return (float64(sum) - float64(rolls)*mean) / math.Sqrt(float64(rolls)*variance)
+}
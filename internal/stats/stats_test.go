@@ -0,0 +1,190 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/daneofmanythings/calcuroller/internal/history"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/object"
+)
+
+func TestChiSquarePValue(t *testing.T) {
+	testCases := []struct {
+		name     string
+		x        float64
+		df       int
+		expected float64
+	}{
+		{"zero statistic", 0, 5, 1},
+		{"no degrees of freedom", 3, 0, 1},
+		{"df 1 at 0.05", 3.841, 1, 0.05},
+		{"df 5 at 0.5", 4.351, 5, 0.5},
+		{"df 10 at 0.05", 18.307, 10, 0.05},
+		{"df 19 at 0.01", 36.191, 19, 0.01},
+		{"df 19 at 0.99", 7.633, 19, 0.99},
+		{"far tail", 200, 19, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := chiSquarePValue(tc.x, tc.df)
+			if math.Abs(p-tc.expected) > 1e-3 {
+				t.Fatalf("expected=%f, got=%f", tc.expected, p)
+			}
+		})
+	}
+}
+
+func TestDieStats(t *testing.T) {
+	die := newDieStats(4)
+	for _, roll := range []uint32{1, 1, 2, 4, 4, 4, 3, 1} {
+		die.add(roll)
+	}
+	die.add(5) // impossible faces are ignored
+
+	if die.Rolls != 8 || die.Sum != 20 {
+		t.Fatalf("expected rolls=8 sum=20, got rolls=%d sum=%d", die.Rolls, die.Sum)
+	}
+	if fmt.Sprint(die.FaceCounts) != "[3 1 1 3]" {
+		t.Fatalf("expected face counts=[3 1 1 3], got=%v", die.FaceCounts)
+	}
+	if die.Mean() != 2.5 || die.ExpectedMean() != 2.5 {
+		t.Fatalf("expected mean=2.5 expected mean=2.5, got mean=%f expected mean=%f", die.Mean(), die.ExpectedMean())
+	}
+	if die.CritRate() != 0.375 || die.FumbleRate() != 0.375 {
+		t.Fatalf("expected crit rate=0.375 fumble rate=0.375, got crit rate=%f fumble rate=%f", die.CritRate(), die.FumbleRate())
+	}
+	if die.Luck() != 0 {
+		t.Fatalf("expected luck=0, got=%f", die.Luck())
+	}
+
+	chi := die.ChiSquare()
+	if chi.Statistic != 2 || chi.DegreesOfFreedom != 3 {
+		t.Fatalf("expected statistic=2 df=3, got=%+v", chi)
+	}
+}
+
+func TestDieStatsD1(t *testing.T) {
+	die := newDieStats(1)
+	die.add(1)
+	die.add(1)
+
+	if die.CritRate() != 0 || die.FumbleRate() != 0 || die.Luck() != 0 {
+		t.Fatalf("expected a d1 to have no crits, fumbles or luck, got=%+v", die)
+	}
+	if die.ChiSquare().PValue != 1 {
+		t.Fatalf("expected a d1 to be fair, got=%+v", die.ChiSquare())
+	}
+}
+
+func TestDieStatsLargeDie(t *testing.T) {
+	die := newDieStats(4000000000)
+	for _, roll := range []uint32{1, 4000000000, 2000000000, 1} {
+		die.add(roll)
+	}
+
+	if die.FaceCounts != nil {
+		t.Fatalf("expected no face counts above %d faces, got=%d", MaxFaceCounts, len(die.FaceCounts))
+	}
+	if die.Rolls != 4 || die.Sum != 6000000002 {
+		t.Fatalf("expected rolls=4 sum=6000000002, got rolls=%d sum=%d", die.Rolls, die.Sum)
+	}
+	if die.CritRate() != 0.25 || die.FumbleRate() != 0.5 {
+		t.Fatalf("expected crit rate=0.25 fumble rate=0.5, got crit rate=%f fumble rate=%f", die.CritRate(), die.FumbleRate())
+	}
+	if chi := die.ChiSquare(); chi.PValue != 1 || chi.DegreesOfFreedom != 0 {
+		t.Fatalf("expected no chi-square test, got=%+v", chi)
+	}
+}
+
+func newTestRecord(i int, callerID string, value int64, err string, dice ...object.DiceData) *history.Record {
+	md := object.NewSeededMetadata(int64(i))
+	for _, data := range dice {
+		md.Add(object.DICE_NODE, 0, 1, data)
+	}
+	return &history.Record{
+		ID:        fmt.Sprintf("roll-%d", i),
+		CallerID:  callerID,
+		Timestamp: time.Date(2024, 4, 1, 12, i, 0, 0, time.UTC),
+		Result:    &object.RollResult{Value: value, Error: err, Metadata: md},
+	}
+}
+
+func newTestDice(rolls ...uint32) []object.Die {
+	dice := []object.Die{}
+	for i, roll := range rolls {
+		dice = append(dice, object.Die{Index: i, Roll: roll, Value: roll})
+	}
+	return dice
+}
+
+func TestCollect(t *testing.T) {
+	store := history.NewMemoryStore(0)
+	records := []*history.Record{
+		newTestRecord(0, "alice", 21, "",
+			object.DiceData{Size: 20, Dice: newTestDice(20, 1)},
+			object.DiceData{Size: 6, Dice: newTestDice(6, 5)},
+		),
+		newTestRecord(1, "bob", 3, "", object.DiceData{Size: 20, Dice: newTestDice(1, 2)}),
+		newTestRecord(2, "carol", 15, "", object.DiceData{Size: 20, Dice: newTestDice(15)}),
+		// the dice of failed rolls don't count
+		newTestRecord(3, "carol", 0, "boom", object.DiceData{Size: 20, Dice: newTestDice(1, 1, 1)}),
+	}
+	for _, rec := range records {
+		if err := store.Append(context.Background(), rec); err != nil {
+			t.Fatalf("could not append: %v", err)
+		}
+	}
+
+	report, err := Collect(context.Background(), store, history.Filter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	order := []string{}
+	for _, caller := range report.Callers {
+		order = append(order, caller.CallerID)
+	}
+	if fmt.Sprint(order) != "[bob alice carol]" {
+		t.Fatalf("expected callers unluckiest first=[bob alice carol], got=%v", order)
+	}
+
+	alice := report.Callers[1]
+	if alice.Rolls() != 4 || len(alice.SortedDice()) != 2 || alice.SortedDice()[0].Size != 6 {
+		t.Fatalf("unexpected stats for alice: %+v", alice.SortedDice())
+	}
+	if d20 := alice.Dice[20]; d20.CritRate() != 0.5 || d20.FumbleRate() != 0.5 {
+		t.Fatalf("expected alice's d20 to crit and fumble half the time, got=%+v", d20)
+	}
+
+	if report.Total.Rolls() != 7 || report.Total.Dice[20].Rolls != 5 {
+		t.Fatalf("expected 7 rolls in total and 5 d20 rolls, got=%d and %d", report.Total.Rolls(), report.Total.Dice[20].Rolls)
+	}
+}
+
+func TestCollectFilters(t *testing.T) {
+	store := history.NewMemoryStore(0)
+	for i := 0; i < history.MaxPageSize+10; i++ {
+		rec := newTestRecord(i, "alice", 1, "", object.DiceData{Size: 6, Dice: newTestDice(1)})
+		if i%2 == 1 {
+			rec.CallerID = "bob"
+		}
+		if err := store.Append(context.Background(), rec); err != nil {
+			t.Fatalf("could not append: %v", err)
+		}
+	}
+
+	report, err := Collect(context.Background(), store, history.Filter{CallerID: "bob"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Callers) != 1 || report.Callers[0].CallerID != "bob" {
+		t.Fatalf("expected only bob, got=%v", report.Callers)
+	}
+	if report.Total.Rolls() != (history.MaxPageSize+10)/2 {
+		t.Fatalf("expected every one of bob's rolls across pages=%d, got=%d", (history.MaxPageSize+10)/2, report.Total.Rolls())
+	}
+}
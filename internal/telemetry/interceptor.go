@@ -0,0 +1,143 @@
+package telemetry
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	pb "github.com/daneofmanythings/calcuroller/internal/grpc/proto"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Interceptor gives every call a request ID, then logs and counts it once it
+// is done.
+type Interceptor struct {
+	logger *slog.Logger
+}
+
+func NewInterceptor(logger *slog.Logger) *Interceptor {
+	return &Interceptor{logger: logger}
+}
+
+func (i *Interceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, c := i.start(ctx, incomingRequestID(ctx))
+		c.req = req
+		grpc.SetHeader(ctx, metadata.Pairs(RequestIDHeader, c.requestID))
+
+		start := time.Now()
+		res, err := handler(ctx, req)
+		i.finish(ctx, c, "grpc", info.FullMethod, outcome(res, err).String(), time.Since(start), err)
+
+		return res, err
+	}
+}
+
+func (i *Interceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, c := i.start(stream.Context(), incomingRequestID(stream.Context()))
+		stream.SetHeader(metadata.Pairs(RequestIDHeader, c.requestID))
+
+		start := time.Now()
+		err := handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+		i.finish(ctx, c, "grpc", info.FullMethod, status.Code(err).String(), time.Since(start), err)
+
+		return err
+	}
+}
+
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// HTTP logs and counts requests to an http.Handler serving method, the full
+// gRPC name of the method, so both transports share the same metrics.
+func (i *Interceptor) HTTP(method string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, c := i.start(r.Context(), r.Header.Get(RequestIDHeader))
+		w.Header().Set(RequestIDHeader, c.requestID)
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+		i.finish(ctx, c, "http", method, strconv.Itoa(recorder.status), time.Since(start), nil)
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (i *Interceptor) start(ctx context.Context, requestID string) (context.Context, *call) {
+	c := &call{requestID: requestIDOrNew(requestID)}
+	return newContext(ctx, c), c
+}
+
+func incomingRequestID(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(RequestIDHeader); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (i *Interceptor) finish(ctx context.Context, c *call, transport, method string, code string, latency time.Duration, err error) {
+	rpcs.WithLabelValues(transport, method, code).Inc()
+	rpcDuration.WithLabelValues(transport, method).Observe(latency.Seconds())
+
+	caller, req := c.get()
+	if req, ok := req.(interface{ GetCallerId() string }); ok && caller == "" {
+		caller = req.GetCallerId()
+	}
+
+	attrs := []slog.Attr{
+		slog.String("request_id", c.requestID),
+		slog.String("transport", transport),
+		slog.String("method", method),
+		slog.String("caller", caller),
+		slog.Duration("latency", latency),
+		slog.String("code", code),
+	}
+	if req, ok := req.(interface{ GetDiceString() string }); ok {
+		attrs = append(attrs, slog.String("dice_hash", diceHash(req.GetDiceString())))
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		attrs = append(attrs, slog.String("trace_id", spanContext.TraceID().String()))
+	}
+	level := slog.LevelInfo
+	if err != nil {
+		attrs = append(attrs, slog.String("error", status.Convert(err).Message()))
+		level = slog.LevelWarn
+	}
+
+	i.logger.LogAttrs(ctx, level, "rpc", attrs...)
+}
+
+// outcome is the code a unary call ended with. Rolls of invalid dice strings
+// succeed at the gRPC level, with the failure in the response.
+func outcome(res any, err error) codes.Code {
+	if err != nil {
+		return status.Code(err)
+	}
+	if res, ok := res.(interface{ GetStatus() *pb.MyStatus }); ok && res.GetStatus() != nil {
+		return codes.Code(res.GetStatus().GetCode())
+	}
+	return codes.OK
+}
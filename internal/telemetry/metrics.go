@@ -0,0 +1,97 @@
+package telemetry
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/object"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	// Registry holds every metric served by MetricsHandler
+	Registry = prometheus.NewRegistry()
+
+	rpcs = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "calcuroller_rpcs_total",
+		Help: "Calls handled, by transport, method and outcome.",
+	}, []string{"transport", "method", "code"})
+
+	rpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "calcuroller_rpc_duration_seconds",
+		Help:    "How long calls took to handle, by transport and method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"transport", "method"})
+
+	diceRolled = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "calcuroller_dice_rolled_total",
+		Help: "Dice rolled, by number of sides.",
+	}, []string{"sides"})
+
+	parseErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "calcuroller_parse_errors_total",
+		Help: "Dice strings that could not be parsed.",
+	})
+
+	parseCacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "calcuroller_parse_cache_lookups_total",
+		Help: "Dice strings looked up in the parse cache, by result: hit or miss.",
+	}, []string{"result"})
+
+	parseCacheEvictions = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "calcuroller_parse_cache_evictions_total",
+		Help: "Parsed dice strings dropped from the parse cache to make room.",
+	})
+
+	parseCacheEntries = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "calcuroller_parse_cache_entries",
+		Help: "Parsed dice strings in the parse cache.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		rpcs,
+		rpcDuration,
+		diceRolled,
+		parseErrors,
+		parseCacheLookups,
+		parseCacheEvictions,
+		parseCacheEntries,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// MetricsHandler serves the metrics in the Prometheus text format.
+func MetricsHandler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// RecordDice counts the dice rolled for a roll.
+func RecordDice(md *object.Metadata) {
+	for _, dice := range md.Dice() {
+		diceRolled.WithLabelValues(strconv.Itoa(int(dice.Size))).Add(float64(len(dice.Dice)))
+	}
+}
+
+func RecordParseError() {
+	parseErrors.Inc()
+}
+
+// RecordParseCacheLookup counts a lookup in the parse cache, and how many
+// entries it holds after it.
+func RecordParseCacheLookup(hit bool, entries int) {
+	if hit {
+		parseCacheLookups.WithLabelValues("hit").Inc()
+	} else {
+		parseCacheLookups.WithLabelValues("miss").Inc()
+	}
+	parseCacheEntries.Set(float64(entries))
+}
+
+func RecordParseCacheEviction() {
+	parseCacheEvictions.Inc()
+}
@@ -0,0 +1,89 @@
+// Package telemetry logs, counts and traces calls to the gRPC service and its
+// HTTP gateway.
+package telemetry
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+)
+
+// RequestIDHeader is the metadata key, or http header, a request ID is read
+// from and sent back in. Requests without one are given one.
+const RequestIDHeader = "x-request-id"
+
+// maxRequestIDLength bounds request IDs given by callers, since they end up
+// in every log line.
+const maxRequestIDLength = 64
+
+// call is what is known about a call in flight. The interceptor logs it once
+// the call is done.
+type call struct {
+	requestID string
+
+	mu     sync.Mutex
+	caller string
+	req    any
+}
+
+type callKey struct{}
+
+func newContext(ctx context.Context, c *call) context.Context {
+	return context.WithValue(ctx, callKey{}, c)
+}
+
+func fromContext(ctx context.Context) (*call, bool) {
+	c, ok := ctx.Value(callKey{}).(*call)
+	return c, ok
+}
+
+// RequestID is the ID of the request in ctx, or "" outside of a request.
+func RequestID(ctx context.Context) string {
+	if c, ok := fromContext(ctx); ok {
+		return c.requestID
+	}
+	return ""
+}
+
+// SetCaller records who the request in ctx was made by, once it is known.
+func SetCaller(ctx context.Context, caller string) {
+	if c, ok := fromContext(ctx); ok {
+		c.mu.Lock()
+		c.caller = caller
+		c.mu.Unlock()
+	}
+}
+
+// SetRequest records the request message of the request in ctx, for
+// transports that decode it after the interceptor has run.
+func SetRequest(ctx context.Context, req any) {
+	if c, ok := fromContext(ctx); ok {
+		c.mu.Lock()
+		c.req = req
+		c.mu.Unlock()
+	}
+}
+
+func (c *call) get() (caller string, req any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.caller, c.req
+}
+
+func requestIDOrNew(requestID string) string {
+	if requestID != "" && len(requestID) <= maxRequestIDLength {
+		return requestID
+	}
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// diceHash identifies a dice string in the logs without logging it, since it
+// is caller input of any length.
+func diceHash(diceString string) string {
+	sum := sha256.Sum256([]byte(diceString))
+	return hex.EncodeToString(sum[:6])
+}
@@ -0,0 +1,152 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	pb "github.com/daneofmanythings/calcuroller/internal/grpc/proto"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/object"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/repl"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestInterceptorUnary(t *testing.T) {
+	var logs bytes.Buffer
+	unary := NewInterceptor(slog.New(slog.NewJSONHandler(&logs, nil))).Unary()
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Roll"}
+
+	testCases := []struct {
+		name              string
+		requestID         string
+		res               any
+		err               error
+		expectedCode      string
+		expectedLevel     string
+		expectedRequestID string
+	}{
+		{"ok", "abc", &pb.RollResponse{}, nil, "OK", "INFO", "abc"},
+		{"in-band status", "", &pb.RollResponse{Message: &pb.RollResponse_Status{Status: &pb.MyStatus{Code: int32(codes.InvalidArgument)}}}, nil, "InvalidArgument", "INFO", ""},
+		{"error", strings.Repeat("x", maxRequestIDLength+1), nil, status.Error(codes.Internal, "boom"), "Internal", "WARN", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			logs.Reset()
+			before := testutil.ToFloat64(rpcs.WithLabelValues("grpc", info.FullMethod, tc.expectedCode))
+
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(RequestIDHeader, tc.requestID))
+			req := &pb.RollRequest{DiceString: "d20", CallerId: "bob"}
+			unary(ctx, req, info, func(ctx context.Context, req any) (any, error) {
+				SetCaller(ctx, "alice")
+				return tc.res, tc.err
+			})
+
+			if after := testutil.ToFloat64(rpcs.WithLabelValues("grpc", info.FullMethod, tc.expectedCode)); after != before+1 {
+				t.Fatalf("expected the call to be counted as %s", tc.expectedCode)
+			}
+
+			line := map[string]any{}
+			if err := json.Unmarshal(logs.Bytes(), &line); err != nil {
+				t.Fatalf("could not decode log line %q: %v", logs.String(), err)
+			}
+			expected := map[string]any{
+				"level":     tc.expectedLevel,
+				"method":    info.FullMethod,
+				"caller":    "alice",
+				"code":      tc.expectedCode,
+				"dice_hash": diceHash("d20"),
+			}
+			for key, value := range expected {
+				if line[key] != value {
+					t.Fatalf("expected %s=%v, got=%v", key, value, line[key])
+				}
+			}
+			requestID, _ := line["request_id"].(string)
+			if tc.expectedRequestID != "" && requestID != tc.expectedRequestID {
+				t.Fatalf("expected request_id=%s, got=%s", tc.expectedRequestID, requestID)
+			}
+			if requestID == "" || len(requestID) > maxRequestIDLength {
+				t.Fatalf("expected a request ID to be generated, got=%q", requestID)
+			}
+			if _, ok := line["latency"]; !ok {
+				t.Fatalf("expected the latency to be logged")
+			}
+		})
+	}
+}
+
+func TestInterceptorHTTP(t *testing.T) {
+	var logs bytes.Buffer
+	handler := NewInterceptor(slog.New(slog.NewJSONHandler(&logs, nil))).HTTP("/test.Service/Ping", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if RequestID(r.Context()) != "abc" {
+			t.Errorf("expected the request ID in the handler's context, got=%q", RequestID(r.Context()))
+		}
+		SetRequest(r.Context(), &pb.RollRequest{DiceString: "d20", CallerId: "bob"})
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/ping", nil)
+	r.Header.Set(RequestIDHeader, "abc")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	if w.Header().Get(RequestIDHeader) != "abc" {
+		t.Fatalf("expected the request ID to be sent back, got=%q", w.Header().Get(RequestIDHeader))
+	}
+	for _, expected := range []string{`"code":"418"`, `"caller":"bob"`, `"dice_hash":"` + diceHash("d20") + `"`} {
+		if !strings.Contains(logs.String(), expected) {
+			t.Fatalf("expected %s to be logged, got=%s", expected, logs.String())
+		}
+	}
+}
+
+func TestRecordDice(t *testing.T) {
+	before := testutil.ToFloat64(diceRolled.WithLabelValues("6"))
+
+	md := object.NewMetadata()
+	repl.RunWithMetadata("d6qu3 + d20", md)
+	RecordDice(md)
+
+	if after := testutil.ToFloat64(diceRolled.WithLabelValues("6")); after != before+3 {
+		t.Fatalf("expected=%v, got=%v", before+3, after)
+	}
+}
+
+func TestTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	unary := NewTracer(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))).Unary()
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Roll"}
+
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("traceparent", traceparent))
+	unary(ctx, nil, info, func(ctx context.Context, req any) (any, error) {
+		return nil, status.Error(codes.NotFound, "nope")
+	})
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got=%d", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "test.Service/Roll" {
+		t.Fatalf("expected name=test.Service/Roll, got=%s", span.Name())
+	}
+	if span.Parent().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("expected the caller's trace to be continued, got=%s", span.Parent().TraceID())
+	}
+	if span.Status().Description != "nope" {
+		t.Fatalf("expected the error in the span status, got=%+v", span.Status())
+	}
+}
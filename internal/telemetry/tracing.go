@@ -0,0 +1,151 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	TRACING_NONE   = "none"
+	TRACING_STDOUT = "stdout"
+)
+
+const tracerName = "github.com/daneofmanythings/calcuroller"
+
+// NewTracerProvider exports a sampleRatio of the traces started here to w.
+// Traces that callers started are sampled if the caller sampled them.
+func NewTracerProvider(exporter string, sampleRatio float64, w io.Writer) (*sdktrace.TracerProvider, error) {
+	if exporter != TRACING_STDOUT {
+		return nil, fmt.Errorf("unknown trace exporter %q", exporter)
+	}
+
+	exp, err := stdouttrace.New(stdouttrace.WithWriter(w))
+	if err != nil {
+		return nil, err
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", "calcuroller"))),
+	), nil
+}
+
+// Tracer starts a span for every call, continuing the caller's trace if the
+// call carries a W3C traceparent.
+type Tracer struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+func NewTracer(provider trace.TracerProvider) *Tracer {
+	return &Tracer{
+		tracer:     provider.Tracer(tracerName),
+		propagator: propagation.TraceContext{},
+	}
+}
+
+func (t *Tracer) start(ctx context.Context, carrier propagation.TextMapCarrier, transport, method string) (context.Context, trace.Span) {
+	ctx = t.propagator.Extract(ctx, carrier)
+	service, name, _ := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+	return t.tracer.Start(ctx, strings.TrimPrefix(method, "/"),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "grpc"),
+			attribute.String("rpc.service", service),
+			attribute.String("rpc.method", name),
+			attribute.String("calcuroller.transport", transport),
+		),
+	)
+}
+
+func endSpan(span trace.Span, code codes.Code, err error) {
+	span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(code)))
+	if code != codes.OK {
+		message := code.String()
+		if err != nil {
+			message = status.Convert(err).Message()
+		}
+		span.SetStatus(otelcodes.Error, message)
+	}
+	span.End()
+}
+
+func (t *Tracer) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		ctx, span := t.start(ctx, metadataCarrier(md), "grpc", info.FullMethod)
+
+		res, err := handler(ctx, req)
+		endSpan(span, outcome(res, err), err)
+
+		return res, err
+	}
+}
+
+func (t *Tracer) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		md, _ := metadata.FromIncomingContext(stream.Context())
+		ctx, span := t.start(stream.Context(), metadataCarrier(md), "grpc", info.FullMethod)
+
+		err := handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+		endSpan(span, status.Code(err), err)
+
+		return err
+	}
+}
+
+// HTTP traces requests to an http.Handler serving method, the full gRPC name
+// of the method.
+func (t *Tracer) HTTP(method string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := t.start(r.Context(), propagation.HeaderCarrier(r.Header), "http", method)
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		span.SetAttributes(attribute.Int("http.response.status_code", recorder.status))
+		if recorder.status >= http.StatusInternalServerError {
+			span.SetStatus(otelcodes.Error, http.StatusText(recorder.status))
+		}
+		span.End()
+	})
+}
+
+// metadataCarrier lets trace context be read from gRPC metadata
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -1,7 +1,69 @@
 package main
 
-import "github.com/daneofmanythings/calcuroller/pkg/interpreter/repl"
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/render"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/repl"
+)
 
 func main() {
-	repl.RunFromTerminal()
+	formatFlag := flag.String("format", string(render.TEXT), fmt.Sprintf("output format, one of %v", render.Formats))
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags]                 start the REPL\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s [flags] run [--seed n] <file>...  run dice scripts\n\nflags:\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	format, err := render.ParseFormat(*formatFlag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if flag.Arg(0) == "run" {
+		os.Exit(runScripts(format, flag.Args()[1:]))
+	}
+	repl.RunFromTerminal(format)
+}
+
+// runScripts runs every script in turn, stopping at the first error. It
+// returns the exit code.
+func runScripts(format render.Format, args []string) int {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	seed := fs.Int64("seed", 0, "roll from this seed, so every run makes the same rolls. 0 rolls randomly")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "expected a script to run. - reads it from stdin")
+		return 2
+	}
+
+	script := repl.NewScript(os.Stdout, format)
+	if *seed != 0 {
+		script.SetSeed(*seed)
+	}
+	for _, path := range fs.Args() {
+		if err := runScript(script, path); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	return 0
+}
+
+func runScript(script *repl.Script, path string) error {
+	if path == "-" {
+		return script.Run("stdin", os.Stdin)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return script.Run(path, file)
 }
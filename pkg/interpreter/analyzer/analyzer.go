@@ -0,0 +1,526 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/ast"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/evaluator"
+)
+
+const (
+	// maxSupport is how many distinct values a distribution is kept for
+	maxSupport = 10000
+	// maxWork bounds the outcomes enumerated to compute a distribution
+	maxWork = 1000000
+	// maxFaces is how many faces the mean of kept dice is summed over exactly.
+	// Dice with more are summed over evenly spaced faces instead.
+	maxFaces = 10000
+)
+
+// Bounds are the values a dice string can evaluate to, found without rolling
+// it. Every roll is between Min and Max, and Mean is its expected value.
+//
+// Min, Max and Mean are exact for dice and for +, - and *. Dividing, taking
+// the modulus or raising to a power needs the distributions of both sides,
+// which are only computed while they are small. Past that Mean is estimated,
+// and Min and Max may be wider than the values actually rolled.
+type Bounds struct {
+	Min       int64
+	Max       int64
+	Mean      float64
+	Estimated bool // Mean is an estimate
+}
+
+// String reads like "3–18, avg 10.5", with the mean rounded to 2 decimals
+// and marked with a ~ when it is estimated
+func (b Bounds) String() string {
+	mean := "avg "
+	if b.Estimated {
+		mean += "~"
+	}
+	mean += strconv.FormatFloat(math.Round(b.Mean*100)/100, 'f', -1, 64)
+	if b.Min == b.Max {
+		return fmt.Sprintf("%d, %s", b.Min, mean)
+	}
+	return fmt.Sprintf("%d–%d, %s", b.Min, b.Max, mean)
+}
+
+// analysis is the bounds of a node, along with the probability of each value
+// it can take when there are few enough of them
+type analysis struct {
+	Bounds
+	dist map[int64]float64 // nil when too large to compute
+}
+
+type analyzer struct {
+	maxDice    int
+	maxDieSize int
+	dice       int
+}
+
+// Analyze finds the bounds of a program, which are those of its last
+// statement. maxDice limits how many dice the program may roll and maxDieSize
+// how many faces a die may have, like Metadata.MaxDice and
+// Metadata.MaxDieSize do when it is evaluated. 0 is no limit.
+//
+// The errors are the ones evaluating the program would return, except for
+// the dice strings that may fail depending on the roll.
+func Analyze(node ast.Node, maxDice, maxDieSize int) (Bounds, error) {
+	a := &analyzer{maxDice: maxDice, maxDieSize: maxDieSize}
+	result, err := a.analyze(node)
+	if err != nil {
+		return Bounds{}, err
+	}
+	return result.Bounds, nil
+}
+
+// Distribution is the chance of each value a program can evaluate to, found
+// without rolling it. It is nil when it is too large to compute exactly: when
+// the program can evaluate to more than 10000 values, or when more than a
+// million outcomes would be enumerated for a single dice literal or operator.
+//
+// maxDice, maxDieSize and the errors are those of Analyze.
+func Distribution(node ast.Node, maxDice, maxDieSize int) (map[int64]float64, error) {
+	a := &analyzer{maxDice: maxDice, maxDieSize: maxDieSize}
+	result, err := a.analyze(node)
+	if err != nil {
+		return nil, err
+	}
+	return result.dist, nil
+}
+
+func (a *analyzer) analyze(node ast.Node) (*analysis, error) {
+	switch node := node.(type) {
+	case *ast.Program:
+		var result *analysis
+		for _, statement := range node.Statements {
+			var err error
+			if result, err = a.analyze(statement); err != nil {
+				return nil, err
+			}
+		}
+		if result == nil {
+			return nil, fmt.Errorf("empty dice string")
+		}
+		return result, nil
+
+	case *ast.ExpressionStatement:
+		return a.analyze(node.Expression)
+
+	case *ast.IntegerLiteral:
+		return fromDistribution(map[int64]float64{node.Value: 1}), nil
+
+	case *ast.DiceLiteral:
+		return a.analyzeDice(node)
+
+	case *ast.PrefixExpression:
+		right, err := a.analyze(node.Right)
+		if err != nil {
+			return nil, err
+		}
+		if node.Operator != "-" {
+			return nil, fmt.Errorf("unknown operator: %sINTEGER", node.Operator)
+		}
+		return negate(right), nil
+
+	case *ast.InfixExpression:
+		left, err := a.analyze(node.Left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := a.analyze(node.Right)
+		if err != nil {
+			return nil, err
+		}
+		return analyzeInfix(node.Operator, left, right)
+
+	case *ast.Identifier:
+		return nil, fmt.Errorf("unknown identifier: %s", node.Value)
+
+	case *ast.IllegalLiteral:
+		return nil, fmt.Errorf("illegal token: %s", node.Literal)
+
+	case nil:
+		return nil, fmt.Errorf("missing expression")
+	}
+
+	return nil, fmt.Errorf("could not analyze %T", node)
+}
+
+func (a *analyzer) analyzeDice(dice *ast.DiceLiteral) (*analysis, error) {
+	if dice.Size == 0 {
+		return nil, fmt.Errorf("dice size must be at least 1, got=%s", dice.String())
+	}
+	if a.maxDieSize > 0 && int64(dice.Size) > int64(a.maxDieSize) {
+		return nil, fmt.Errorf("die too large: %s has more than %d faces", dice.String(), a.maxDieSize)
+	}
+	quantity := int(max(dice.Quantity, 1))
+	a.dice += quantity
+	if a.maxDice > 0 && a.dice > a.maxDice {
+		return nil, fmt.Errorf("too many dice: %s would roll more than %d dice", dice.String(), a.maxDice)
+	}
+
+	d := newDie(dice)
+	first, last := keptRanks(dice, quantity)
+	kept := int64(last - first + 1)
+
+	result := &analysis{Bounds: Bounds{Min: kept * d.low, Max: kept * d.high}}
+	if kept == int64(quantity) {
+		result.Mean = float64(quantity) * d.mean()
+		result.dist = d.sumDistribution(quantity)
+	} else {
+		result.Mean, result.Estimated = d.keptMean(quantity, first, last)
+		result.dist = d.keptDistribution(quantity, first, last)
+	}
+	return result, nil
+}
+
+// keptRanks are the ranks of the dice a literal keeps, counted from 1 for the
+// lowest roll. kh is applied before kl, like the evaluator does.
+func keptRanks(dice *ast.DiceLiteral, quantity int) (first, last int) {
+	first, last = 1, quantity
+	if dice.KeepHighest > 0 && int(dice.KeepHighest) < quantity {
+		first = quantity - int(dice.KeepHighest) + 1
+	}
+	if dice.KeepLowest > 0 && int(dice.KeepLowest) < last-first+1 {
+		last = first + int(dice.KeepLowest) - 1
+	}
+	return first, last
+}
+
+// die is a single die of a literal, once mi and ma are applied. Every face
+// between low and high is rolled as itself, and the faces past them are
+// rolled as low or high.
+type die struct {
+	size      int64
+	low, high int64
+}
+
+func newDie(dice *ast.DiceLiteral) die {
+	clamp := func(roll int64) int64 {
+		if dice.MaxValue > 0 && roll > int64(dice.MaxValue) {
+			roll = int64(dice.MaxValue)
+		}
+		if dice.MinValue > 0 && roll < int64(dice.MinValue) {
+			roll = int64(dice.MinValue)
+		}
+		return roll
+	}
+	return die{size: int64(dice.Size), low: clamp(1), high: clamp(int64(dice.Size))}
+}
+
+// atLeast is the probability of rolling value or more, for values above low
+func (d die) atLeast(value int64) float64 {
+	if value > d.high {
+		return 0
+	}
+	return float64(d.size-value+1) / float64(d.size)
+}
+
+func (d die) mean() float64 {
+	// low, and then the chance of rolling at least each value above it
+	faces := d.high - d.low
+	return float64(d.low) + float64(faces)*float64(2*d.size-d.high-d.low+1)/2/float64(d.size)
+}
+
+// keptMean is the expected sum of the dice ranked first to last out of
+// quantity. The sum is low for every kept die, plus for each value above low
+// the expected number of kept dice rolling at least that value.
+func (d die) keptMean(quantity, first, last int) (mean float64, estimated bool) {
+	kept := last - first + 1
+	faces := d.high - d.low
+	step := int64(1)
+	if faces > maxFaces {
+		step, estimated = (faces+maxFaces-1)/maxFaces, true
+	}
+
+	mean = float64(kept) * float64(d.low)
+	for value := d.low + 1; value <= d.high; value += step {
+		width := min(step, d.high-value+1)
+		p := d.atLeast(value + width/2)
+		// the kept dice rolling at least value are the ones past rank
+		// quantity - count, where count is how many rolled at least value
+		atLeast := expectedMin(quantity, quantity-first+1, p) - expectedMin(quantity, quantity-last, p)
+		mean += float64(width) * atLeast
+	}
+	return mean, estimated
+}
+
+// expectedMin is E[min(B, c)] for B binomially distributed over n trials with
+// probability p. The terms are summed outwards from the mode until they no
+// longer matter.
+func expectedMin(n, c int, p float64) float64 {
+	switch {
+	case c <= 0 || p <= 0:
+		return 0
+	case p >= 1:
+		return float64(min(n, c))
+	case c >= n:
+		return float64(n) * p
+	}
+
+	mode := min(int(float64(n+1)*p), n)
+	lgN, _ := math.Lgamma(float64(n + 1))
+	lgK, _ := math.Lgamma(float64(mode + 1))
+	lgNK, _ := math.Lgamma(float64(n - mode + 1))
+	peak := math.Exp(lgN - lgK - lgNK + float64(mode)*math.Log(p) + float64(n-mode)*math.Log1p(-p))
+
+	sum := float64(min(mode, c)) * peak
+	for k, term := mode+1, peak; k <= n; k++ {
+		term *= float64(n-k+1) / float64(k) * p / (1 - p)
+		if term < peak*1e-17 {
+			break
+		}
+		sum += float64(min(k, c)) * term
+	}
+	for k, term := mode-1, peak; k >= 0; k-- {
+		term *= float64(k+1) / float64(n-k) * (1 - p) / p
+		if term < peak*1e-17 {
+			break
+		}
+		sum += float64(min(k, c)) * term
+	}
+	return sum
+}
+
+// distribution of a single die, nil if it has too many faces
+func (d die) distribution() map[int64]float64 {
+	if d.high-d.low+1 > maxSupport {
+		return nil
+	}
+	dist := map[int64]float64{}
+	for value := d.low; value <= d.high; value++ {
+		above := 1.0
+		if value > d.low {
+			above = d.atLeast(value)
+		}
+		dist[value] = above - d.atLeast(value+1)
+	}
+	return dist
+}
+
+// sumDistribution is the distribution of the sum of quantity dice, nil if it
+// is too large to compute
+func (d die) sumDistribution(quantity int) map[int64]float64 {
+	single := d.distribution()
+	faces := int64(len(single))
+	support := int64(quantity)*(faces-1) + 1
+	if single == nil || support > maxSupport || int64(quantity)*support*faces > maxWork {
+		return nil
+	}
+
+	sum := map[int64]float64{0: 1}
+	for i := 0; i < quantity; i++ {
+		next := map[int64]float64{}
+		for total, p := range sum {
+			for value, q := range single {
+				next[total+value] += p * q
+			}
+		}
+		sum = next
+	}
+	return sum
+}
+
+// keptDistribution is the distribution of the sum of the dice ranked first to
+// last, found by enumerating every roll. It is nil if there are too many.
+func (d die) keptDistribution(quantity, first, last int) map[int64]float64 {
+	single := d.distribution()
+	if single == nil || math.Pow(float64(len(single)), float64(quantity)) > maxWork {
+		return nil
+	}
+	values := keys(single)
+
+	dist := map[int64]float64{}
+	faces := make([]int, quantity) // the face each die rolled, an odometer
+	rolls := make([]int64, quantity)
+	for {
+		p := 1.0
+		for i, face := range faces {
+			rolls[i] = values[face]
+			p *= single[values[face]]
+		}
+		slices.Sort(rolls)
+		total := int64(0)
+		for _, roll := range rolls[first-1 : last] {
+			total += roll
+		}
+		dist[total] += p
+
+		i := 0
+		for ; i < quantity && faces[i] == len(values)-1; i++ {
+			faces[i] = 0
+		}
+		if i == quantity {
+			return dist
+		}
+		faces[i]++
+	}
+}
+
+func fromDistribution(dist map[int64]float64) *analysis {
+	result := &analysis{Bounds: Bounds{Min: math.MaxInt64, Max: math.MinInt64}}
+	for value, p := range dist {
+		result.Min = min(result.Min, value)
+		result.Max = max(result.Max, value)
+		result.Mean += float64(value) * p
+	}
+	if len(dist) <= maxSupport {
+		result.dist = dist
+	}
+	return result
+}
+
+func negate(operand *analysis) *analysis {
+	result := &analysis{Bounds: Bounds{
+		Min:       -operand.Max,
+		Max:       -operand.Min,
+		Mean:      -operand.Mean,
+		Estimated: operand.Estimated,
+	}}
+	if operand.dist != nil {
+		result.dist = map[int64]float64{}
+		for value, p := range operand.dist {
+			result.dist[-value] = p
+		}
+	}
+	return result
+}
+
+func analyzeInfix(operator string, left, right *analysis) (*analysis, error) {
+	if _, ok := evaluator.Operate(operator, 0, 1); !ok {
+		return nil, fmt.Errorf("unknown operator: INTEGER %s INTEGER", operator)
+	}
+	operate := func(l, r int64) int64 {
+		value, _ := evaluator.Operate(operator, l, r)
+		return value
+	}
+
+	if left.dist != nil && right.dist != nil && len(left.dist)*len(right.dist) <= maxWork {
+		dist := map[int64]float64{}
+		for l, p := range left.dist {
+			for r, q := range right.dist {
+				dist[operate(l, r)] += p * q
+			}
+		}
+		return fromDistribution(dist), nil
+	}
+
+	result := &analysis{}
+	result.Estimated = left.Estimated || right.Estimated
+	switch operator {
+	case "+":
+		result.Min, result.Max = left.Min+right.Min, left.Max+right.Max
+		result.Mean = left.Mean + right.Mean
+		return result, nil
+	case "-":
+		result.Min, result.Max = left.Min-right.Max, left.Max-right.Min
+		result.Mean = left.Mean - right.Mean
+		return result, nil
+	case "*":
+		// the sides are rolled independently
+		result.Min, result.Max = extremes(operate, endpoints(left), endpoints(right))
+		result.Mean = left.Mean * right.Mean
+		return result, nil
+	case "/":
+		result.Min, result.Max = extremes(operate, endpoints(left), divisors(right))
+		result.Mean = left.Mean / nonZero(right.Mean)
+	case "%":
+		result.Min, result.Max = remainders(left, right)
+		result.Mean = float64(result.Min+result.Max) / 2
+	case "^":
+		result.Min, result.Max = extremes(operate, bases(left), exponents(right))
+		result.Mean = math.Pow(left.Mean, right.Mean)
+	}
+
+	// estimated from the means alone, ex: ignoring that division truncates
+	result.Estimated = result.Min != result.Max
+	result.Mean = max(float64(result.Min), min(result.Mean, float64(result.Max)))
+	return result, nil
+}
+
+// extremes are the smallest and largest results of operating on every pair of
+// candidate values
+func extremes(operate func(l, r int64) int64, lefts, rights []int64) (int64, int64) {
+	lowest, highest := int64(math.MaxInt64), int64(math.MinInt64)
+	for _, l := range lefts {
+		for _, r := range rights {
+			value := operate(l, r)
+			lowest, highest = min(lowest, value), max(highest, value)
+		}
+	}
+	return lowest, highest
+}
+
+func endpoints(operand *analysis) []int64 {
+	return []int64{operand.Min, operand.Max}
+}
+
+// within are the candidates between the operand's bounds
+func within(operand *analysis, candidates ...int64) []int64 {
+	result := []int64{}
+	for _, candidate := range candidates {
+		if operand.Min <= candidate && candidate <= operand.Max {
+			result = append(result, candidate)
+		}
+	}
+	return result
+}
+
+// divisors are the values a quotient is largest or smallest at, since it
+// only changes direction at 0, which divides by 1 instead
+func divisors(operand *analysis) []int64 {
+	if operand.dist != nil {
+		return keys(operand.dist)
+	}
+	result := append(endpoints(operand), within(operand, -1, 1)...)
+	if operand.Min <= 0 && 0 <= operand.Max {
+		result = append(result, 1)
+	}
+	return result
+}
+
+// bases are the values a power is largest or smallest at: the largest bases
+// either way, and those that don't grow with the exponent
+func bases(operand *analysis) []int64 {
+	return append(endpoints(operand), within(operand, -1, 0, 1)...)
+}
+
+// exponents are the values a power is largest or smallest at: the largest
+// and smallest exponents of either parity, and 0 for the exponents below 1
+func exponents(operand *analysis) []int64 {
+	return within(operand, operand.Min, operand.Min+1, operand.Max-1, operand.Max, 0)
+}
+
+// remainders bounds a modulus, which has the sign of the dividend and is
+// smaller than the divisor
+func remainders(left, right *analysis) (int64, int64) {
+	largest := int64(1)
+	for _, divisor := range divisors(right) {
+		if divisor == math.MinInt64 {
+			largest = math.MaxInt64
+			break
+		}
+		largest = max(largest, divisor, -divisor)
+	}
+	return max(min(left.Min, 0), -(largest - 1)), min(max(left.Max, 0), largest-1)
+}
+
+func keys(dist map[int64]float64) []int64 {
+	result := make([]int64, 0, len(dist))
+	for value := range dist {
+		result = append(result, value)
+	}
+	slices.Sort(result)
+	return result
+}
+
+func nonZero(value float64) float64 {
+	if value == 0 {
+		return 1
+	}
+	return value
+}
@@ -0,0 +1,222 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/ast"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/evaluator"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/lexer"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/object"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/parser"
+)
+
+func parse(t *testing.T, input string) *ast.Program {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("could not parse %q: %v", input, p.Errors())
+	}
+	return program
+}
+
+func TestAnalyze(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected Bounds
+	}{
+		{"5", Bounds{5, 5, 5, false}},
+		{"d6", Bounds{1, 6, 3.5, false}},
+		{"d6qu3", Bounds{3, 18, 10.5, false}},
+		{"d6qu4kh3", Bounds{3, 18, 15869.0 / 1296, false}},
+		{"d20qu2kh1", Bounds{1, 20, 13.825, false}},
+		{"d20qu2kl1", Bounds{1, 20, 7.175, false}},
+		{"d6qu3kh2kl1", Bounds{1, 6, 3.5, false}},
+		{"d6qu2kh5", Bounds{2, 12, 7, false}},
+		{"d6mi3", Bounds{3, 6, 4, false}},
+		{"d6ma2", Bounds{1, 2, 11.0 / 6, false}},
+		{"d6mi5ma3", Bounds{5, 5, 5, false}},
+		{"d6mi10", Bounds{10, 10, 10, false}},
+		{"d20 + 5", Bounds{6, 25, 15.5, false}},
+		{"-d6", Bounds{-6, -1, -3.5, false}},
+		{"d4 - d6", Bounds{-5, 3, -1, false}},
+		{"d6 * d6", Bounds{1, 36, 12.25, false}},
+		{"d6 / 0", Bounds{1, 6, 3.5, false}},
+		{"6 / (d3 - 2)", Bounds{-6, 6, 2, false}},
+		{"d6qu2 / 2", Bounds{1, 6, 117.0 / 36, false}},
+		{"d4 % 3", Bounds{0, 2, 1, false}},
+		{"d4 % (d2 - 1)", Bounds{0, 0, 0, false}},
+		{"2 ^ d4", Bounds{2, 16, 7.5, false}},
+		{"d20 d6", Bounds{1, 6, 3.5, false}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			actual, err := Analyze(parse(t, tc.input), 0, 0)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual.Min != tc.expected.Min || actual.Max != tc.expected.Max ||
+				math.Abs(actual.Mean-tc.expected.Mean) > 1e-9 || actual.Estimated != tc.expected.Estimated {
+				t.Fatalf("expected=%+v, got=%+v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+// the mean of kept dice is summed without enumerating the rolls, so it is
+// checked against enumerating them
+func TestKeptMean(t *testing.T) {
+	for size := 1; size <= 8; size++ {
+		for quantity := 1; quantity <= 4; quantity++ {
+			for keep := 0; keep <= quantity; keep++ {
+				for _, modifiers := range []string{"kh%d", "kl%d", "kh%dkl1", "mi3ma5kh%d"} {
+					input := fmt.Sprintf("d%dqu%d", size, quantity)
+					if keep > 0 {
+						input += fmt.Sprintf(modifiers, keep)
+					}
+					dice := parse(t, input).Statements[0].(*ast.ExpressionStatement).Expression.(*ast.DiceLiteral)
+
+					d := newDie(dice)
+					first, last := keptRanks(dice, quantity)
+					mean, _ := d.keptMean(quantity, first, last)
+					expected := fromDistribution(d.keptDistribution(quantity, first, last)).Mean
+					if math.Abs(mean-expected) > 1e-9 {
+						t.Fatalf("%s: expected=%v, got=%v", input, expected, mean)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestDistribution(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected map[int64]float64
+	}{
+		{"5", map[int64]float64{5: 1}},
+		{"d4", map[int64]float64{1: 0.25, 2: 0.25, 3: 0.25, 4: 0.25}},
+		{"d2qu2", map[int64]float64{2: 0.25, 3: 0.5, 4: 0.25}},
+		{"d2qu2kh1", map[int64]float64{1: 0.25, 2: 0.75}},
+		{"d4mi2ma3", map[int64]float64{2: 0.5, 3: 0.5}},
+		{"d2 * -d2", map[int64]float64{-4: 0.25, -2: 0.5, -1: 0.25}},
+		{"d4 % 2", map[int64]float64{0: 0.5, 1: 0.5}},
+		{"d3 / (d2 - 1)", map[int64]float64{1: 1.0 / 3, 2: 1.0 / 3, 3: 1.0 / 3}},
+		{"d6qu10000", nil},
+		{"d1000qu3kh1", nil},
+		{"d1000 * d1000 * d1000", nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			actual, err := Distribution(parse(t, tc.input), 0, 0)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (actual == nil) != (tc.expected == nil) || len(actual) != len(tc.expected) {
+				t.Fatalf("expected=%v, got=%v", tc.expected, actual)
+			}
+			for value, p := range tc.expected {
+				if math.Abs(actual[value]-p) > 1e-9 {
+					t.Fatalf("expected=%v, got=%v", tc.expected, actual)
+				}
+			}
+		})
+	}
+}
+
+// rolling a dice string many times stays within its bounds, and averages
+// close to its mean
+func TestAnalyzeMatchesRolls(t *testing.T) {
+	testCases := []string{
+		"d6qu10kh3 + d100qu3kl2",
+		"d1000qu50kh10 - d20",
+		"d100000qu3kh1",
+		"d10qu20kh5 / d4",
+		"(d8 + 3) * 2 % 7",
+		"-d6qu2 ^ d3",
+		"d12qu4mi2kh2",
+	}
+	const trials = 20000
+
+	for _, input := range testCases {
+		t.Run(input, func(t *testing.T) {
+			program := parse(t, input)
+			bounds, err := Analyze(program, 0, 0)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			sum := 0.0
+			for i := 0; i < trials; i++ {
+				value := evaluator.Eval(program, object.NewSeededMetadata(int64(i))).(*object.Integer).Value
+				if value < bounds.Min || value > bounds.Max {
+					t.Fatalf("rolled %d, outside of %v", value, bounds)
+				}
+				sum += float64(value)
+			}
+			if bounds.Estimated {
+				return
+			}
+			mean := sum / trials
+			if math.Abs(mean-bounds.Mean) > 0.02*math.Max(math.Abs(bounds.Mean), 1) {
+				t.Fatalf("expected a mean close to %v, rolled=%v", bounds.Mean, mean)
+			}
+		})
+	}
+}
+
+func TestAnalyzeErrors(t *testing.T) {
+	testCases := []struct {
+		input      string
+		maxDice    int
+		maxDieSize int
+		expected   string
+	}{
+		{"d0 + 1", 0, 0, "dice size must be at least 1, got=d0"},
+		{"str + 1", 0, 0, "unknown identifier: str"},
+		{"d6qu6 + d6qu5", 10, 0, "too many dice: 5d6 would roll more than 10 dice"},
+		{"d20 + d4000000000", 0, 1000, "die too large: d4000000000 has more than 1000 faces"},
+		{"1 + ", 0, 0, "illegal token: EOF"},
+		{"", 0, 0, "empty dice string"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			program := parser.New(lexer.New(tc.input)).ParseProgram()
+			_, err := Analyze(program, tc.maxDice, tc.maxDieSize)
+			if err == nil || err.Error() != tc.expected {
+				t.Fatalf("expected=%q, got=%v", tc.expected, err)
+			}
+
+			// the same errors as rolling
+			md := object.NewMetadata()
+			md.MaxDice = tc.maxDice
+			md.MaxDieSize = tc.maxDieSize
+			if rolled := evaluator.Eval(program, md); rolled.Inspect() != "ERROR: "+tc.expected {
+				t.Fatalf("expected=%q, got=%s", tc.expected, rolled.Inspect())
+			}
+		})
+	}
+}
+
+func TestBoundsString(t *testing.T) {
+	testCases := []struct {
+		bounds   Bounds
+		expected string
+	}{
+		{Bounds{3, 18, 10.5, false}, "3–18, avg 10.5"},
+		{Bounds{3, 18, 15869.0 / 1296, false}, "3–18, avg 12.24"},
+		{Bounds{5, 5, 5, false}, "5, avg 5"},
+		{Bounds{0, 40, 10.123, true}, "0–40, avg ~10.12"},
+	}
+
+	for _, tc := range testCases {
+		if actual := tc.bounds.String(); actual != tc.expected {
+			t.Errorf("expected=%q, got=%q", tc.expected, actual)
+		}
+	}
+}
@@ -10,6 +10,49 @@ import (
 type Node interface {
 	TokenLiteral() string
 	String() string
+	Span() Span
+}
+
+// Span is the half-open byte range [Start, End) a node covers in the source.
+type Span struct {
+	Start int
+	End   int
+}
+
+func spanOf(n Node) Span {
+	if n == nil {
+		return Span{}
+	}
+	return n.Span()
+}
+
+// withParens is the span of an expression, widened to the parentheses
+// around it if it was grouped
+func withParens(span Span, parens *Span) Span {
+	if parens == nil {
+		return span
+	}
+	return *parens
+}
+
+// Group records the parentheses around a grouped expression, from the ( to
+// one past the ), so its span covers them. Nested parentheses are grouped
+// from the inside out, so the outermost ones are kept.
+func Group(exp Expression, parens Span) {
+	switch exp := exp.(type) {
+	case *Identifier:
+		exp.Parens = &parens
+	case *IntegerLiteral:
+		exp.Parens = &parens
+	case *IllegalLiteral:
+		exp.Parens = &parens
+	case *DiceLiteral:
+		exp.Parens = &parens
+	case *PrefixExpression:
+		exp.Parens = &parens
+	case *InfixExpression:
+		exp.Parens = &parens
+	}
 }
 
 type Statement interface {
@@ -33,6 +76,16 @@ func (p *Program) TokenLiteral() string {
 	return p.Statements[0].TokenLiteral()
 }
 
+func (p *Program) Span() Span {
+	if len(p.Statements) == 0 {
+		return Span{}
+	}
+	return Span{
+		Start: spanOf(p.Statements[0]).Start,
+		End:   spanOf(p.Statements[len(p.Statements)-1]).End,
+	}
+}
+
 func (p *Program) String() string {
 	var out bytes.Buffer
 
@@ -50,6 +103,12 @@ type ExpressionStatement struct {
 
 func (es *ExpressionStatement) statementNode()       {}
 func (es *ExpressionStatement) TokenLiteral() string { return es.Token.Literal }
+func (es *ExpressionStatement) Span() Span {
+	if es.Expression == nil {
+		return Span{Start: es.Token.Start, End: es.Token.End}
+	}
+	return es.Expression.Span()
+}
 func (es *ExpressionStatement) String() string {
 	if es.Expression == nil {
 		return ""
@@ -58,33 +117,46 @@ func (es *ExpressionStatement) String() string {
 }
 
 type Identifier struct {
-	Token token.Token
-	Value string
+	Token  token.Token
+	Value  string
+	Parens *Span // the parentheses around it, if any
 }
 
 func (i *Identifier) expressionNode()      {}
 func (i *Identifier) TokenLiteral() string { return i.Token.Literal }
 func (i *Identifier) String() string       { return i.Value }
+func (i *Identifier) Span() Span {
+	return withParens(Span{Start: i.Token.Start, End: i.Token.End}, i.Parens)
+}
 
 type IntegerLiteral struct {
-	Token token.Token
-	Tags  []string
-	Value int64
+	Token  token.Token
+	Tags   []string
+	Value  int64
+	End    int   // end of the last tag, if any
+	Parens *Span // the parentheses around it, if any
 }
 
 func (il *IntegerLiteral) expressionNode()      {}
 func (il *IntegerLiteral) TokenLiteral() string { return il.Token.Literal }
 func (il *IntegerLiteral) String() string       { return il.Token.Literal }
+func (il *IntegerLiteral) Span() Span {
+	return withParens(Span{Start: il.Token.Start, End: max(il.Token.End, il.End)}, il.Parens)
+}
 
 type IllegalLiteral struct {
 	Token   token.Token
 	Literal string
 	pos     int
+	Parens  *Span // the parentheses around it, if any
 }
 
 func (il *IllegalLiteral) expressionNode()      {}
 func (il *IllegalLiteral) TokenLiteral() string { return il.Token.Literal }
 func (il *IllegalLiteral) String() string       { return il.Literal }
+func (il *IllegalLiteral) Span() Span {
+	return withParens(Span{Start: il.Token.Start, End: il.Token.End}, il.Parens)
+}
 
 type DiceLiteral struct {
 	Token       token.Token
@@ -95,10 +167,15 @@ type DiceLiteral struct {
 	MinValue    uint32
 	KeepHighest uint32
 	KeepLowest  uint32
+	End         int   // end of the last modifier or tag, if any
+	Parens      *Span // the parentheses around it, if any
 }
 
 func (dl *DiceLiteral) expressionNode()      {}
 func (dl *DiceLiteral) TokenLiteral() string { return dl.Token.Literal }
+func (dl *DiceLiteral) Span() Span {
+	return withParens(Span{Start: dl.Token.Start, End: max(dl.Token.End, dl.End)}, dl.Parens)
+}
 func (dl *DiceLiteral) String() string {
 	var out bytes.Buffer
 
@@ -134,10 +211,14 @@ type PrefixExpression struct {
 	Token    token.Token
 	Operator string
 	Right    Expression
+	Parens   *Span // the parentheses around it, if any
 }
 
 func (pe *PrefixExpression) expressionNode()      {}
 func (pe *PrefixExpression) TokenLiteral() string { return pe.Token.Literal }
+func (pe *PrefixExpression) Span() Span {
+	return withParens(Span{Start: pe.Token.Start, End: max(pe.Token.End, spanOf(pe.Right).End)}, pe.Parens)
+}
 func (pe *PrefixExpression) String() string {
 	var out bytes.Buffer
 
@@ -154,10 +235,21 @@ type InfixExpression struct {
 	Left     Expression
 	Operator string
 	Right    Expression
+	Parens   *Span // the parentheses around it, if any
 }
 
 func (ie *InfixExpression) expressionNode()      {}
 func (ie *InfixExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *InfixExpression) Span() Span {
+	span := Span{Start: ie.Token.Start, End: ie.Token.End}
+	if ie.Left != nil {
+		span.Start = ie.Left.Span().Start
+	}
+	if ie.Right != nil {
+		span.End = ie.Right.Span().End
+	}
+	return withParens(span, ie.Parens)
+}
 func (ie *InfixExpression) String() string {
 	var out bytes.Buffer
 
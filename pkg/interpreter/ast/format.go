@@ -0,0 +1,150 @@
+package ast
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/token"
+)
+
+// precedences of the infix operators, the same as the parser's
+var precedences = map[string]int{
+	"+": 1,
+	"-": 1,
+	"*": 2,
+	"/": 2,
+	"%": 2,
+	"^": 3,
+}
+
+// prefixPrecedence binds tighter than every infix operator
+const prefixPrecedence = 4
+
+// Format renders a node back into canonical source, which parses into the
+// same tree. Dice are written as d{size} followed by their modifiers in the
+// order qu, mi, ma, kh, kl and then their tags, leaving out the ones that
+// don't change the roll. Operators are surrounded by single spaces, and
+// parentheses are only kept where they change the meaning. Statements are
+// separated by a space.
+//
+// Nodes that can't be written as source, like illegal tokens, are an error.
+func Format(node Node) (string, error) {
+	var out strings.Builder
+	if err := format(&out, node); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+func format(out *strings.Builder, node Node) error {
+	switch node := node.(type) {
+	case *Program:
+		for i, statement := range node.Statements {
+			if i > 0 {
+				out.WriteString(" ")
+			}
+			if err := format(out, statement); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case *ExpressionStatement:
+		if node.Expression == nil {
+			return fmt.Errorf("could not format %q: missing expression", node.Token.Literal)
+		}
+		return format(out, node.Expression)
+
+	case *InfixExpression:
+		if node.Left == nil || node.Right == nil {
+			return fmt.Errorf("could not format %q: missing operand", node.Operator)
+		}
+		if err := formatOperand(out, node.Left, precedences[node.Operator], false); err != nil {
+			return err
+		}
+		out.WriteString(" " + node.Operator + " ")
+		return formatOperand(out, node.Right, precedences[node.Operator], true)
+
+	case *PrefixExpression:
+		if node.Right == nil {
+			return fmt.Errorf("could not format %q: missing operand", node.Operator)
+		}
+		out.WriteString(node.Operator)
+		return formatOperand(out, node.Right, prefixPrecedence, true)
+
+	case *DiceLiteral:
+		formatDice(out, node)
+		return nil
+
+	case *IntegerLiteral:
+		out.WriteString(strconv.FormatInt(node.Value, 10))
+		formatTags(out, node.Tags)
+		return nil
+
+	case *Identifier:
+		out.WriteString(node.Value)
+		return nil
+
+	case *IllegalLiteral:
+		if node.Token.Type == token.EOF {
+			return fmt.Errorf("could not format: unexpected end of input")
+		}
+		return fmt.Errorf("could not format %q: illegal token", node.Literal)
+
+	case nil:
+		return fmt.Errorf("could not format a missing expression")
+	}
+
+	return fmt.Errorf("could not format %T", node)
+}
+
+// formatOperand wraps an infix operand in parentheses when writing it bare
+// would change the meaning of the expression. All operators are left
+// associative.
+func formatOperand(out *strings.Builder, operand Expression, parentPrecedence int, isRight bool) error {
+	infix, ok := operand.(*InfixExpression)
+	if !ok {
+		return format(out, operand)
+	}
+
+	childPrecedence := precedences[infix.Operator]
+	if childPrecedence > parentPrecedence || (!isRight && childPrecedence == parentPrecedence) {
+		return format(out, operand)
+	}
+	out.WriteString("(")
+	if err := format(out, operand); err != nil {
+		return err
+	}
+	out.WriteString(")")
+	return nil
+}
+
+func formatDice(out *strings.Builder, dice *DiceLiteral) {
+	out.WriteString("d" + strconv.FormatUint(uint64(dice.Size), 10))
+	// a quantity of 0 or 1 rolls a single die
+	if dice.Quantity > 1 {
+		out.WriteString("qu" + strconv.FormatUint(uint64(dice.Quantity), 10))
+	}
+	modifiers := []struct {
+		keyword string
+		value   uint32
+	}{
+		{"mi", dice.MinValue},
+		{"ma", dice.MaxValue},
+		{"kh", dice.KeepHighest},
+		{"kl", dice.KeepLowest},
+	}
+	for _, modifier := range modifiers {
+		if modifier.value > 0 {
+			out.WriteString(modifier.keyword + strconv.FormatUint(uint64(modifier.value), 10))
+		}
+	}
+	formatTags(out, dice.Tags)
+}
+
+func formatTags(out *strings.Builder, tags []string) {
+	for _, tag := range tags {
+		out.WriteString("[" + tag + "]")
+	}
+}
@@ -0,0 +1,137 @@
+package compiler
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// Instructions are opcodes, each followed by its operands
+type Instructions []byte
+
+type Opcode byte
+
+const (
+	// OpLeaf evaluates Leaves[operand]: an identifier, or an expression that
+	// failed to parse
+	OpLeaf Opcode = iota
+	// OpDice rolls Dice[operand], applying its modifiers, and pushes the total
+	OpDice
+	// OpInteger pushes Integers[operand]
+	OpInteger
+	// OpOpen opens Nodes[operand] in the metadata tree
+	OpOpen
+	// OpClose records the value on top of the stack on the node opened last
+	OpClose
+	// OpPop drops the value of a statement that isn't the last one
+	OpPop
+	// OpEmpty fails, for programs without statements
+	OpEmpty
+	// OpNegate replaces the value on top of the stack with its negation
+	OpNegate
+	// the infix operators pop the right and then the left operand, and push
+	// the result
+	OpAdd
+	OpSub
+	OpMul
+	OpDiv
+	OpMod
+	OpPow
+)
+
+// definition is how an opcode is written out, and the width in bytes of
+// each of its operands
+type definition struct {
+	name          string
+	operandWidths []int
+}
+
+var definitions = map[Opcode]definition{
+	OpLeaf:    {"OpLeaf", []int{2}},
+	OpDice:    {"OpDice", []int{2}},
+	OpInteger: {"OpInteger", []int{2}},
+	OpOpen:    {"OpOpen", []int{2}},
+	OpClose:   {"OpClose", []int{}},
+	OpPop:     {"OpPop", []int{}},
+	OpEmpty:   {"OpEmpty", []int{}},
+	OpNegate:  {"OpNegate", []int{}},
+	OpAdd:     {"OpAdd", []int{}},
+	OpSub:     {"OpSub", []int{}},
+	OpMul:     {"OpMul", []int{}},
+	OpDiv:     {"OpDiv", []int{}},
+	OpMod:     {"OpMod", []int{}},
+	OpPow:     {"OpPow", []int{}},
+}
+
+// Make encodes an instruction. Missing operands are 0.
+func Make(op Opcode, operands ...int) []byte {
+	def, ok := definitions[op]
+	if !ok {
+		return []byte{}
+	}
+
+	length := 1
+	for _, width := range def.operandWidths {
+		length += width
+	}
+	instruction := make([]byte, length)
+	instruction[0] = byte(op)
+
+	offset := 1
+	for i, width := range def.operandWidths {
+		if i < len(operands) {
+			switch width {
+			case 2:
+				binary.BigEndian.PutUint16(instruction[offset:], uint16(operands[i]))
+			}
+		}
+		offset += width
+	}
+	return instruction
+}
+
+// ReadUint16 reads a 2 byte operand
+func ReadUint16(ins Instructions) uint16 {
+	return binary.BigEndian.Uint16(ins)
+}
+
+// String disassembles the instructions, one per line prefixed with its
+// offset, ex: 0003 OpDice 1
+func (ins Instructions) String() string {
+	return ins.disassemble(nil)
+}
+
+// disassemble writes out the instructions, followed by describe's
+// description of their operand in parentheses, when it has one
+func (ins Instructions) disassemble(describe func(op Opcode, operand int) string) string {
+	var out strings.Builder
+	for i := 0; i < len(ins); {
+		op := Opcode(ins[i])
+		def, ok := definitions[op]
+		if !ok {
+			fmt.Fprintf(&out, "%04d ERROR: unknown opcode %d\n", i, ins[i])
+			i++
+			continue
+		}
+
+		fmt.Fprintf(&out, "%04d %s", i, def.name)
+		offset := i + 1
+		for _, width := range def.operandWidths {
+			var operand int
+			switch width {
+			case 2:
+				operand = int(ReadUint16(ins[offset:]))
+			}
+			fmt.Fprintf(&out, " %d", operand)
+			if describe != nil {
+				if description := describe(op, operand); description != "" {
+					fmt.Fprintf(&out, " (%s)", description)
+				}
+			}
+			offset += width
+		}
+		out.WriteString("\n")
+		i = offset
+	}
+	return out.String()
+}
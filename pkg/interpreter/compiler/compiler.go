@@ -0,0 +1,221 @@
+package compiler
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/ast"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/object"
+)
+
+// Bytecode is a compiled dice string. Running it rolls the same dice, and
+// records the same metadata, as evaluating the tree it was compiled from.
+// It is never changed after compiling, so it can be run any number of times.
+type Bytecode struct {
+	Instructions Instructions
+	// Dice are the dice literals, rolled by the vm, see OpDice
+	Dice []Dice
+	// Integers are the integer literals, see OpInteger
+	Integers []Integer
+	// Leaves are the identifiers and the expressions that failed to parse,
+	// looked up and reported by the evaluator, see OpLeaf
+	Leaves []ast.Node
+	// Nodes are the nodes of the metadata tree above the leaves, see OpOpen
+	Nodes []Node
+	// MaxStack is the most values on the stack at once
+	MaxStack int
+	// Source is the tree the bytecode was compiled from, which traced runs
+	// evaluate instead
+	Source ast.Node
+}
+
+// Dice is a dice literal, with what is recorded about it in the metadata
+// worked out once when compiling
+type Dice struct {
+	Literal     string // as it is recorded, ex: 4d6kh3[str]
+	Tags        []string
+	Size        uint32
+	Quantity    int // at least 1
+	MaxValue    uint32
+	MinValue    uint32
+	KeepHighest uint32
+	KeepLowest  uint32
+	Start, End  int
+}
+
+// Integer is an integer literal, with what is recorded about it in the
+// metadata worked out once when compiling
+type Integer struct {
+	Literal    string
+	Tags       []string
+	Value      int64
+	Start, End int
+}
+
+// Node is a program, prefix or infix node of the metadata tree
+type Node struct {
+	Kind       object.NodeKind
+	Operator   string
+	Start, End int
+}
+
+// infixOpcodes are the opcodes of the infix operators
+var infixOpcodes = map[string]Opcode{
+	"+": OpAdd,
+	"-": OpSub,
+	"*": OpMul,
+	"/": OpDiv,
+	"%": OpMod,
+	"^": OpPow,
+}
+
+type compiler struct {
+	bytecode *Bytecode
+	stack    int // values on the stack after the instructions so far
+}
+
+// Compile compiles a parsed dice string. Expressions that failed to parse
+// compile to instructions that fail when they are reached, like evaluating
+// them does, so the dice before them are still rolled. Operators the parser
+// never produces are an error.
+func Compile(node ast.Node) (*Bytecode, error) {
+	c := &compiler{bytecode: &Bytecode{Source: node}}
+	if err := c.compile(node); err != nil {
+		return nil, err
+	}
+	if max(len(c.bytecode.Dice), len(c.bytecode.Integers), len(c.bytecode.Leaves), len(c.bytecode.Nodes)) > math.MaxUint16 {
+		return nil, fmt.Errorf("could not compile: more than %d nodes", math.MaxUint16)
+	}
+	return c.bytecode, nil
+}
+
+func (c *compiler) compile(node ast.Node) error {
+	switch node := node.(type) {
+	case *ast.Program:
+		span := node.Span()
+		c.open(Node{Kind: object.PROGRAM_NODE, Start: span.Start, End: span.End})
+		if len(node.Statements) == 0 {
+			c.emit(OpEmpty)
+			return nil
+		}
+		for i, statement := range node.Statements {
+			if i > 0 {
+				c.emit(OpPop)
+				c.stack--
+			}
+			if err := c.compile(statement); err != nil {
+				return err
+			}
+		}
+		c.emit(OpClose)
+		return nil
+
+	case *ast.ExpressionStatement:
+		return c.compile(node.Expression)
+
+	case *ast.PrefixExpression:
+		if node.Operator != "-" {
+			return fmt.Errorf("could not compile: unknown operator %s", node.Operator)
+		}
+		span := node.Span()
+		c.open(Node{Kind: object.PREFIX_NODE, Operator: node.Operator, Start: span.Start, End: span.End})
+		if err := c.compile(node.Right); err != nil {
+			return err
+		}
+		c.emit(OpNegate)
+		c.emit(OpClose)
+		return nil
+
+	case *ast.InfixExpression:
+		op, ok := infixOpcodes[node.Operator]
+		if !ok {
+			return fmt.Errorf("could not compile: unknown operator %s", node.Operator)
+		}
+		span := node.Span()
+		c.open(Node{Kind: object.INFIX_NODE, Operator: node.Operator, Start: span.Start, End: span.End})
+		if err := c.compile(node.Left); err != nil {
+			return err
+		}
+		if err := c.compile(node.Right); err != nil {
+			return err
+		}
+		c.emit(op)
+		c.stack--
+		c.emit(OpClose)
+		return nil
+
+	case *ast.DiceLiteral:
+		span := node.Span()
+		c.bytecode.Dice = append(c.bytecode.Dice, Dice{
+			Literal:     node.String(),
+			Tags:        node.Tags,
+			Size:        node.Size,
+			Quantity:    int(max(node.Quantity, 1)),
+			MaxValue:    node.MaxValue,
+			MinValue:    node.MinValue,
+			KeepHighest: node.KeepHighest,
+			KeepLowest:  node.KeepLowest,
+			Start:       span.Start,
+			End:         span.End,
+		})
+		c.push(OpDice, len(c.bytecode.Dice)-1)
+		return nil
+
+	case *ast.IntegerLiteral:
+		span := node.Span()
+		c.bytecode.Integers = append(c.bytecode.Integers, Integer{
+			Literal: node.String(),
+			Tags:    node.Tags,
+			Value:   node.Value,
+			Start:   span.Start,
+			End:     span.End,
+		})
+		c.push(OpInteger, len(c.bytecode.Integers)-1)
+		return nil
+
+	case *ast.Identifier, *ast.IllegalLiteral, nil:
+		c.bytecode.Leaves = append(c.bytecode.Leaves, node)
+		c.push(OpLeaf, len(c.bytecode.Leaves)-1)
+		return nil
+	}
+
+	return fmt.Errorf("could not compile %T", node)
+}
+
+func (c *compiler) open(node Node) {
+	c.bytecode.Nodes = append(c.bytecode.Nodes, node)
+	c.emit(OpOpen, len(c.bytecode.Nodes)-1)
+}
+
+// push emits an instruction pushing a value
+func (c *compiler) push(op Opcode, operand int) {
+	c.emit(op, operand)
+	c.stack++
+	c.bytecode.MaxStack = max(c.bytecode.MaxStack, c.stack)
+}
+
+func (c *compiler) emit(op Opcode, operands ...int) {
+	c.bytecode.Instructions = append(c.bytecode.Instructions, Make(op, operands...)...)
+}
+
+// String disassembles the bytecode, naming the leaf or node each
+// instruction refers to, ex: 0003 OpDice 1 (d20)
+func (b *Bytecode) String() string {
+	return b.Instructions.disassemble(func(op Opcode, operand int) string {
+		switch {
+		case op == OpDice && operand < len(b.Dice):
+			return b.Dice[operand].Literal
+		case op == OpInteger && operand < len(b.Integers):
+			return b.Integers[operand].Literal
+		case op == OpLeaf && operand < len(b.Leaves):
+			if b.Leaves[operand] == nil {
+				return "missing expression"
+			}
+			return b.Leaves[operand].String()
+		case op == OpOpen && operand < len(b.Nodes):
+			return strings.TrimSpace(string(b.Nodes[operand].Kind) + " " + b.Nodes[operand].Operator)
+		}
+		return ""
+	})
+}
@@ -0,0 +1,138 @@
+package compiler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/ast"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/lexer"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/parser"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/token"
+)
+
+func TestMake(t *testing.T) {
+	testCases := []struct {
+		op       Opcode
+		operands []int
+		expected []byte
+	}{
+		{OpLeaf, []int{65534}, []byte{byte(OpLeaf), 255, 254}},
+		{OpOpen, []int{1}, []byte{byte(OpOpen), 0, 1}},
+		{OpDice, []int{258}, []byte{byte(OpDice), 1, 2}},
+		{OpAdd, []int{}, []byte{byte(OpAdd)}},
+		{Opcode(255), []int{}, []byte{}},
+	}
+
+	for _, tc := range testCases {
+		instruction := Make(tc.op, tc.operands...)
+		if string(instruction) != string(tc.expected) {
+			t.Errorf("expected=%v, got=%v", tc.expected, instruction)
+		}
+	}
+}
+
+func TestCompile(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected []string
+	}{
+		{"d20", []string{
+			"0000 OpOpen 0 (PROGRAM)",
+			"0003 OpDice 0 (d20)",
+			"0006 OpClose",
+		}},
+		{"(d6 + 2) * -x", []string{
+			"0000 OpOpen 0 (PROGRAM)",
+			"0003 OpOpen 1 (INFIX *)",
+			"0006 OpOpen 2 (INFIX +)",
+			"0009 OpDice 0 (d6)",
+			"0012 OpInteger 0 (2)",
+			"0015 OpAdd",
+			"0016 OpClose",
+			"0017 OpOpen 3 (PREFIX -)",
+			"0020 OpLeaf 0 (x)",
+			"0023 OpNegate",
+			"0024 OpClose",
+			"0025 OpMul",
+			"0026 OpClose",
+			"0027 OpClose",
+		}},
+		{"d4 d6", []string{
+			"0000 OpOpen 0 (PROGRAM)",
+			"0003 OpDice 0 (d4)",
+			"0006 OpPop",
+			"0007 OpDice 1 (d6)",
+			"0010 OpClose",
+		}},
+		{"1 +", []string{
+			"0000 OpOpen 0 (PROGRAM)",
+			"0003 OpOpen 1 (INFIX +)",
+			"0006 OpInteger 0 (1)",
+			"0009 OpLeaf 0 (EOF)",
+			"0012 OpAdd",
+			"0013 OpClose",
+			"0014 OpClose",
+		}},
+		{"", []string{
+			"0000 OpOpen 0 (PROGRAM)",
+			"0003 OpEmpty",
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			bytecode, err := Compile(parser.New(lexer.New(tc.input)).ParseProgram())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expected := strings.Join(tc.expected, "\n") + "\n"
+			if bytecode.String() != expected {
+				t.Fatalf("expected=\n%s\ngot=\n%s", expected, bytecode)
+			}
+		})
+	}
+}
+
+func TestCompileMaxStack(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected int
+	}{
+		{"", 0},
+		{"d20", 1},
+		{"d20 d6 d4", 1},
+		{"1 + 2 + 3 + 4", 2},
+		{"1 + (2 + (3 + 4))", 4},
+	}
+
+	for _, tc := range testCases {
+		bytecode, err := Compile(parser.New(lexer.New(tc.input)).ParseProgram())
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tc.input, err)
+		}
+		if bytecode.MaxStack != tc.expected {
+			t.Errorf("%q: expected=%d, got=%d", tc.input, tc.expected, bytecode.MaxStack)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	integer := &ast.IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "1"}, Value: 1}
+	testCases := []struct {
+		name     string
+		node     ast.Node
+		expected string
+	}{
+		{"infix", &ast.InfixExpression{Left: integer, Operator: "&", Right: integer}, "could not compile: unknown operator &"},
+		{"prefix", &ast.PrefixExpression{Operator: "+", Right: integer}, "could not compile: unknown operator +"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Compile(tc.node)
+			if err == nil || err.Error() != tc.expected {
+				t.Fatalf("expected=%q, got=%v", tc.expected, err)
+			}
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"math/rand"
 	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/daneofmanythings/calcuroller/pkg/interpreter/ast"
 	"github.com/daneofmanythings/calcuroller/pkg/interpreter/object"
@@ -15,6 +17,13 @@ func EvalFromRequest(node ast.Node) (object.Object, *object.Metadata) {
 	return val, md
 }
 
+// EvalWithSeed is EvalFromRequest with the dice rolled from the given seed.
+func EvalWithSeed(node ast.Node, seed int64) (object.Object, *object.Metadata) {
+	md := object.NewSeededMetadata(seed)
+	val := Eval(node, md)
+	return val, md
+}
+
 func Eval(node ast.Node, md *object.Metadata) object.Object {
 	switch node := node.(type) {
 
@@ -35,25 +44,48 @@ func Eval(node ast.Node, md *object.Metadata) object.Object {
 	case *ast.IllegalLiteral:
 		return evalIllegalLiteral(node, md)
 
+	case *ast.Identifier:
+		return evalIdentifier(node, md)
+
+	case nil:
+		return newSyntaxError("missing expression")
+
 	case *ast.PrefixExpression:
+		span := node.Span()
+		md.Open(object.PREFIX_NODE, node.Operator, span.Start, span.End)
 		right := Eval(node.Right, md)
 		if isError(right) {
+			md.Close(right)
 			return right
 		}
-		return evalPrefixExpression(node.Operator, right)
+		result := evalPrefixExpression(node.Operator, right)
+		md.Close(result)
+		if md.Trace && !isError(result) {
+			md.AddStep(object.PREFIX_NODE, span.Start, span.End, "%s(%s) = %s", node.Operator, right.Inspect(), result.Inspect())
+		}
+		return result
 
 	case *ast.InfixExpression:
+		span := node.Span()
+		md.Open(object.INFIX_NODE, node.Operator, span.Start, span.End)
 		left := Eval(node.Left, md)
 		if isError(left) {
+			md.Close(left)
 			return left
 		}
 
 		right := Eval(node.Right, md)
 		if isError(right) {
+			md.Close(right)
 			return right
 		}
 
-		return evalInfixExpression(node.Operator, left, right)
+		result := evalInfixExpression(node.Operator, left, right)
+		md.Close(result)
+		if md.Trace && !isError(result) {
+			md.AddStep(object.INFIX_NODE, span.Start, span.End, "%s", describeInfix(node.Operator, left, right, result))
+		}
+		return result
 	}
 
 	return nil
@@ -62,6 +94,10 @@ func Eval(node ast.Node, md *object.Metadata) object.Object {
 func evalProgram(program *ast.Program, env *object.Metadata) object.Object {
 	var result object.Object
 
+	span := program.Span()
+	env.Open(object.PROGRAM_NODE, "", span.Start, span.End)
+	defer func() { env.Close(result) }()
+
 	for _, statement := range program.Statements {
 		result = Eval(statement, env)
 
@@ -71,6 +107,13 @@ func evalProgram(program *ast.Program, env *object.Metadata) object.Object {
 		}
 	}
 
+	if result == nil {
+		result = newError("empty dice string")
+	}
+	if env.Trace && len(program.Statements) > 1 && !isError(result) {
+		env.AddStep(object.PROGRAM_NODE, span.Start, span.End, "the last statement is the value: %s", result.Inspect())
+	}
+
 	return result
 }
 
@@ -89,17 +132,40 @@ func evalIntegerExpression(node ast.Expression, md *object.Metadata) object.Obje
 		return newError("expected IntegerLiteral, got=%v", node.TokenLiteral())
 	}
 
-	md.Add(integerNode.String(), object.DiceData{
-		Literal:    integerNode.String(),
-		Tags:       integerNode.Tags,
-		RawRolls:   []uint32{},
-		FinalRolls: []uint32{},
-		Value:      integerNode.Value,
+	span := integerNode.Span()
+	md.Add(object.INTEGER_NODE, span.Start, span.End, object.DiceData{
+		Literal: integerNode.String(),
+		Tags:    slices.Clone(integerNode.Tags),
+		Dice:    []object.Die{},
+		Value:   integerNode.Value,
 	})
+	if md.Trace {
+		md.AddStep(object.INTEGER_NODE, span.Start, span.End, "%d", integerNode.Value)
+	}
 
 	return &object.Integer{Value: integerNode.Value}
 }
 
+func evalIdentifier(node *ast.Identifier, md *object.Metadata) object.Object {
+	value, ok := md.Variables[node.Value]
+	if !ok {
+		return newError("unknown identifier: %s", node.Value)
+	}
+
+	span := node.Span()
+	md.Add(object.VARIABLE_NODE, span.Start, span.End, object.DiceData{
+		Literal: node.Value,
+		Tags:    []string{},
+		Dice:    []object.Die{},
+		Value:   value,
+	})
+	if md.Trace {
+		md.AddStep(object.VARIABLE_NODE, span.Start, span.End, "%s = %d", node.Value, value)
+	}
+
+	return &object.Integer{Value: value}
+}
+
 // TODO: here is the dice evaluation!
 func evalDiceExpression(node ast.Expression, md *object.Metadata) object.Object {
 	dice, ok := node.(*ast.DiceLiteral)
@@ -107,116 +173,311 @@ func evalDiceExpression(node ast.Expression, md *object.Metadata) object.Object
 		return newError("expected DiceLiteral, got=%v", node.TokenLiteral())
 	}
 
-	rawRolls := []uint32{}
+	if dice.Size == 0 {
+		return newError("dice size must be at least 1, got=%s", dice.String())
+	}
+	if md.MaxDieSize > 0 && int64(dice.Size) > int64(md.MaxDieSize) {
+		return newError("die too large: %s has more than %d faces", dice.String(), md.MaxDieSize)
+	}
+
+	if md.MaxDice > 0 {
+		if md.Rolled()+int(max(dice.Quantity, 1)) > md.MaxDice {
+			return newError("too many dice: %s would roll more than %d dice", dice.String(), md.MaxDice)
+		}
+	}
+
+	rawRolls := make([]uint32, 0, max(dice.Quantity, 1))
 
 	if dice.Quantity > 0 {
 		for i := 0; i < int(dice.Quantity); i++ {
-			rawRolls = rollSingleDie(dice.Size, rawRolls)
+			rawRolls = rollSingleDie(md.Rand(), dice.Size, rawRolls)
 		}
 	} else {
-		rawRolls = rollSingleDie(dice.Size, rawRolls)
+		rawRolls = rollSingleDie(md.Rand(), dice.Size, rawRolls)
 	}
 
-	adjustedRolls := slices.Clone(rawRolls)
-
+	rolled := newDice(rawRolls)
 	if dice.MaxValue > 0 {
-		adjustedRolls = applyMaxValue(adjustedRolls, dice.MaxValue)
+		rolled = applyMaxValue(rolled, dice.MaxValue)
 	}
 	if dice.MinValue > 0 {
-		adjustedRolls = applyMinValue(adjustedRolls, dice.MinValue)
+		rolled = applyMinValue(rolled, dice.MinValue)
 	}
 	if dice.KeepHighest > 0 {
-		adjustedRolls = applyKeepHighest(adjustedRolls, dice.KeepHighest)
+		rolled = applyKeepHighest(rolled, dice.KeepHighest)
 	}
 	if dice.KeepLowest > 0 {
-		adjustedRolls = applyKeepLowest(adjustedRolls, dice.KeepLowest)
+		rolled = applyKeepLowest(rolled, dice.KeepLowest)
 	}
 
-	value := sumRolls(adjustedRolls)
+	value := sumDice(rolled)
 
-	md.Add(dice.String(), object.DiceData{
-		Literal:    dice.String(),
-		Tags:       dice.Tags,
-		RawRolls:   rawRolls,
-		FinalRolls: adjustedRolls,
-		Value:      value,
+	span := dice.Span()
+	if md.Trace {
+		traceDice(md, dice, span, rawRolls)
+	}
+	md.Add(object.DICE_NODE, span.Start, span.End, object.DiceData{
+		Literal: dice.String(),
+		Tags:    slices.Clone(dice.Tags),
+		Size:    dice.Size,
+		Dice:    rolled,
+		Value:   value,
 	})
 
 	return &object.Integer{Value: value}
 }
 
-func rollSingleDie(size uint32, rawRolls []uint32) []uint32 {
-	roll := rand.Intn(int(size))
+// traceDice records the steps of rolling dice, applying its modifiers to
+// rawRolls again one at a time in the same order
+func traceDice(md *object.Metadata, dice *ast.DiceLiteral, span ast.Span, rawRolls []uint32) {
+	step := func(format string, a ...any) {
+		md.AddStep(object.DICE_NODE, span.Start, span.End, format, a...)
+	}
+
+	step("%s: rolled %d d%d: %s", dice.String(), len(rawRolls), dice.Size, joinRolls(rawRolls))
+	rolled := newDice(rawRolls)
+	if dice.MaxValue > 0 {
+		before := slices.Clone(rolled)
+		rolled = applyMaxValue(rolled, dice.MaxValue)
+		step("ma%d: %s", dice.MaxValue, describeClamp(before, rolled, "lowered", "above", dice.MaxValue))
+	}
+	if dice.MinValue > 0 {
+		before := slices.Clone(rolled)
+		rolled = applyMinValue(rolled, dice.MinValue)
+		step("mi%d: %s", dice.MinValue, describeClamp(before, rolled, "raised", "below", dice.MinValue))
+	}
+	if dice.KeepHighest > 0 {
+		before := slices.Clone(rolled)
+		rolled = applyKeepHighest(rolled, dice.KeepHighest)
+		step("kh%d: %s", dice.KeepHighest, describeKeep(before, rolled))
+	}
+	if dice.KeepLowest > 0 {
+		before := slices.Clone(rolled)
+		rolled = applyKeepLowest(rolled, dice.KeepLowest)
+		step("kl%d: %s", dice.KeepLowest, describeKeep(before, rolled))
+	}
+
+	kept := keptValues(rolled)
+	switch {
+	case len(kept) > 1:
+		step("total: %s = %d", strings.ReplaceAll(joinRolls(kept), ", ", " + "), sumDice(rolled))
+	case len(kept) != len(rawRolls) || kept[0] != rawRolls[0]:
+		step("total: %d", sumDice(rolled))
+	}
+}
+
+func rollSingleDie(r *rand.Rand, size uint32, rawRolls []uint32) []uint32 {
+	roll := r.Intn(int(size))
 	rawRolls = append(rawRolls, uint32(roll+1))
 	return rawRolls
 }
 
-func applyMaxValue(rolls []uint32, val uint32) []uint32 {
-	for i := 0; i < len(rolls); i++ {
-		if rolls[i] > val {
-			rolls[i] = val
-		}
+// newDice are the dice of rawRolls, before any modifier is applied
+func newDice(rawRolls []uint32) []object.Die {
+	dice := make([]object.Die, 0, len(rawRolls))
+	for i, roll := range rawRolls {
+		dice = append(dice, object.Die{Index: i, Roll: roll, Value: roll})
 	}
-	return rolls
+	return dice
 }
 
-func applyMinValue(rolls []uint32, val uint32) []uint32 {
-	for i := 0; i < len(rolls); i++ {
-		if rolls[i] < val {
-			rolls[i] = val
+func applyMaxValue(dice []object.Die, val uint32) []object.Die {
+	for i := 0; i < len(dice); i++ {
+		if dice[i].Value > val {
+			dice[i].Value = val
+			dice[i].Clamped = true
 		}
 	}
-	return rolls
+	return dice
 }
 
-func applyKeepHighest(rolls []uint32, val uint32) []uint32 {
-	return applyKeepFunc(rolls, val, slices.Max)
+func applyMinValue(dice []object.Die, val uint32) []object.Die {
+	for i := 0; i < len(dice); i++ {
+		if dice[i].Value < val {
+			dice[i].Value = val
+			dice[i].Clamped = true
+		}
+	}
+	return dice
 }
 
-func applyKeepLowest(rolls []uint32, val uint32) []uint32 {
-	return applyKeepFunc(rolls, val, slices.Min)
+func applyKeepHighest(dice []object.Die, val uint32) []object.Die {
+	return applyKeep(dice, val, true)
 }
 
-func applyKeepFunc(rolls []uint32, val uint32, f func([]uint32) uint32) []uint32 {
-	resultRolls := []uint32{}        // what will be returned
-	rollsCopy := slices.Clone(rolls) // what will be updated to track remaining rolls after grabbing a min
-	// rolls will be used to sort the returning slice in the order the rolls happened
+func applyKeepLowest(dice []object.Die, val uint32) []object.Die {
+	return applyKeep(dice, val, false)
+}
 
-	if int(val) >= len(rolls) {
-		return rolls // more rolls that the keep value
+// applyKeep drops every die not dropped yet but the val highest or lowest.
+// Of several dice of the same value, the ones rolled first are kept.
+func applyKeep(dice []object.Die, val uint32, highest bool) []object.Die {
+	values := keptValues(dice)
+	if int(val) >= len(values) {
+		return dice // more rolls that the keep value
 	}
 
-	// grabbing mins, putting them into lowestRolls, and removing them from the copy.
-	for i := 0; i < int(val); i++ {
-		nextRoll := f(rollsCopy)
-		resultRolls = append(resultRolls, nextRoll)
-		idx := slices.Index(rollsCopy, nextRoll)
-		rollsCopy = slices.Delete(rollsCopy, idx, idx+1)
+	// counting the kept dice of each value, from the highest or lowest value
+	counts := countRolls(values)
+	kept := newRollCounts(values)
+	sorted := counts.values()
+	if highest {
+		slices.Reverse(sorted)
+	}
+	remaining := int(val)
+	for _, value := range sorted {
+		n := min(counts.get(value), remaining)
+		kept.add(value, n)
+		remaining -= n
+		if remaining == 0 {
+			break
+		}
 	}
 
-	// sorting the result in roll order
-	slices.SortFunc(resultRolls, func(a, b uint32) int {
-		if slices.Index(rolls, a) < slices.Index(rolls, b) {
-			return -1
+	// keeping the first dice of each value, in roll order
+	for i := range dice {
+		if dice[i].Dropped {
+			continue
+		}
+		if kept.get(dice[i].Value) > 0 {
+			kept.add(dice[i].Value, -1)
 		} else {
-			return 1
+			dice[i].Dropped = true
 		}
-	})
+	}
 
-	return resultRolls
+	return dice
 }
 
-func sumRolls(rolls []uint32) int64 {
-	var result int64 = 0
+// keptValues are the values of the dice not dropped, in roll order
+func keptValues(dice []object.Die) []uint32 {
+	values := make([]uint32, 0, len(dice))
+	for _, die := range dice {
+		if !die.Dropped {
+			values = append(values, die.Value)
+		}
+	}
+	return values
+}
+
+// rollCounts counts rolls by value. Rolls are at most the size of their die,
+// which is usually small next to how many are rolled, so they are counted in
+// a slice indexed by value. Dice much larger than that are counted in a map.
+type rollCounts struct {
+	dense  []int
+	sparse map[uint32]int
+}
+
+// newRollCounts counts no rolls yet, for values up to the highest of rolls
+func newRollCounts(rolls []uint32) *rollCounts {
+	highest := uint32(0)
+	for _, roll := range rolls {
+		highest = max(highest, roll)
+	}
+	if int(highest) <= 4*len(rolls)+16 {
+		return &rollCounts{dense: make([]int, highest+1)}
+	}
+	return &rollCounts{sparse: make(map[uint32]int, len(rolls))}
+}
+
+func countRolls(rolls []uint32) *rollCounts {
+	counts := newRollCounts(rolls)
+	for _, roll := range rolls {
+		counts.add(roll, 1)
+	}
+	return counts
+}
+
+func (c *rollCounts) get(value uint32) int {
+	if c.sparse != nil {
+		return c.sparse[value]
+	}
+	if int(value) >= len(c.dense) {
+		return 0
+	}
+	return c.dense[value]
+}
+
+func (c *rollCounts) add(value uint32, n int) {
+	if c.sparse != nil {
+		c.sparse[value] += n
+		return
+	}
+	c.dense[value] += n
+}
+
+// values are the values counted, from lowest to highest
+func (c *rollCounts) values() []uint32 {
+	values := []uint32{}
+	if c.sparse != nil {
+		for value, n := range c.sparse {
+			if n > 0 {
+				values = append(values, value)
+			}
+		}
+		slices.Sort(values)
+		return values
+	}
+	for value, n := range c.dense {
+		if n > 0 {
+			values = append(values, uint32(value))
+		}
+	}
+	return values
+}
+
+func joinRolls(rolls []uint32) string {
+	parts := make([]string, 0, len(rolls))
 	for _, roll := range rolls {
-		result += int64(roll)
+		parts = append(parts, strconv.FormatUint(uint64(roll), 10))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// describeClamp tells which rolls a mi or ma modifier changed
+func describeClamp(before, after []object.Die, verb, direction string, limit uint32) string {
+	changed := []uint32{}
+	values := make([]uint32, 0, len(after))
+	for i := range after {
+		if before[i].Value != after[i].Value {
+			changed = append(changed, before[i].Value)
+		}
+		values = append(values, after[i].Value)
+	}
+	if len(changed) == 0 {
+		return fmt.Sprintf("no rolls %s %d", direction, limit)
+	}
+	return fmt.Sprintf("%s %s to %d: %s", verb, joinRolls(changed), limit, joinRolls(values))
+}
+
+// describeKeep tells which rolls a kh or kl modifier kept and dropped
+func describeKeep(before, after []object.Die) string {
+	dropped := []uint32{}
+	for i := range after {
+		if after[i].Dropped && !before[i].Dropped {
+			dropped = append(dropped, after[i].Value)
+		}
+	}
+	if len(dropped) == 0 {
+		return "kept every roll: " + joinRolls(keptValues(after))
+	}
+	return fmt.Sprintf("kept %s, dropped %s", joinRolls(keptValues(after)), joinRolls(dropped))
+}
+
+// sumDice is the total of the dice not dropped
+func sumDice(dice []object.Die) int64 {
+	var result int64 = 0
+	for _, die := range dice {
+		if !die.Dropped {
+			result += int64(die.Value)
+		}
 	}
 
 	return result
 }
 
 func evalIllegalLiteral(node ast.Expression, md *object.Metadata) object.Object {
-	return newError("illegal token: %s", node.(*ast.IllegalLiteral).Literal)
+	return newSyntaxError("illegal token: %s", node.(*ast.IllegalLiteral).Literal)
 }
 
 func evalPrefixExpression(operator string, right object.Object) object.Object {
@@ -251,32 +512,60 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 	leftVal := left.(*object.Integer).Value
 	rightVal := right.(*object.Integer).Value
 
+	value, ok := Operate(operator, leftVal, rightVal)
+	if !ok {
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+	return &object.Integer{Value: value}
+}
+
+func describeInfix(operator string, left, right, result object.Object) string {
+	description := fmt.Sprintf("%s %s %s = %s", left.Inspect(), operator, right.Inspect(), result.Inspect())
+	if (operator == "/" || operator == "%") && right.Inspect() == "0" {
+		description += ", as if by 1 since it can't be by 0"
+	}
+	return description
+}
+
+// Operate applies an infix operator to two integers, as evaluating does:
+// dividing or taking the modulus by 0 does it by 1 instead, and raising to an
+// exponent below 1 gives 1. ok is false for unknown operators.
+func Operate(operator string, left, right int64) (value int64, ok bool) {
 	switch operator {
 	case "+":
-		return &object.Integer{Value: leftVal + rightVal}
+		return left + right, true
 	case "-":
-		return &object.Integer{Value: leftVal - rightVal}
+		return left - right, true
 	case "*":
-		return &object.Integer{Value: leftVal * rightVal}
+		return left * right, true
 	case "/":
-		if rightVal == 0 {
-			rightVal = 1 // this is to handle the case where a dice expression is the denominator and is 0.
+		if right == 0 {
+			right = 1 // this is to handle the case where a dice expression is the denominator and is 0.
 		}
-		return &object.Integer{Value: leftVal / rightVal}
+		return left / right, true
 	case "%":
-		return &object.Integer{Value: leftVal % rightVal}
+		if right == 0 {
+			right = 1 // same as division
+		}
+		return left % right, true
 	case "^":
-		return &object.Integer{Value: integerExponentiation(leftVal, rightVal)}
+		return integerExponentiation(left, right), true
 	default:
-		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+		return 0, false
 	}
 }
 
+// integerExponentiation squares, so large exponents take as many steps as
+// they have bits. Overflowing wraps around like repeated multiplication does.
 func integerExponentiation(base, exponent int64) int64 {
-	if exponent < 1 {
-		return 1
+	result := int64(1)
+	for ; exponent >= 1; exponent >>= 1 {
+		if exponent&1 == 1 {
+			result *= base
+		}
+		base *= base
 	}
-	return base * integerExponentiation(base, exponent-1)
+	return result
 }
 
 func evalExpressions(exps []ast.Expression, env *object.Metadata) []object.Object {
@@ -297,6 +586,10 @@ func newError(format string, a ...interface{}) *object.Error {
 	return &object.Error{Message: fmt.Sprintf(format, a...)}
 }
 
+func newSyntaxError(format string, a ...interface{}) *object.Error {
+	return &object.Error{Message: fmt.Sprintf(format, a...), Syntax: true}
+}
+
 func isError(obj object.Object) bool {
 	if obj != nil {
 		return obj.Type() == object.ERROR_OBJ
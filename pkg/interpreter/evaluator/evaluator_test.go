@@ -1,7 +1,11 @@
 package evaluator
 
 import (
+	"cmp"
+	"fmt"
+	"math/rand"
 	"slices"
+	"strings"
 	"testing"
 
 	"github.com/daneofmanythings/calcuroller/pkg/interpreter/lexer"
@@ -21,7 +25,7 @@ func TestRollSingleDie(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			for i := 0; i < tc.repetitions; i++ {
-				result := rollSingleDie(tc.val, []uint32{})
+				result := rollSingleDie(rand.New(rand.NewSource(int64(i))), tc.val, []uint32{})
 				if result[0] < 1 || result[0] > tc.val {
 					t.Fatalf("got a roll out of range. min=1, max=%d. got=%d", tc.val, result)
 				}
@@ -44,7 +48,7 @@ func TestApplyMaxValue(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result := applyMaxValue(tc.rolls, tc.val)
+			result := keptValues(applyMaxValue(newDice(tc.rolls), tc.val))
 			if slices.Compare(result, tc.expected) != 0 {
 				t.Fatalf("expected=%d, got=%d", tc.expected, result)
 			}
@@ -66,7 +70,7 @@ func TestApplyMinValue(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result := applyMinValue(tc.rolls, tc.val)
+			result := keptValues(applyMinValue(newDice(tc.rolls), tc.val))
 			if slices.Compare(result, tc.expected) != 0 {
 				t.Fatalf("expected=%d, got=%d", tc.expected, result)
 			}
@@ -88,7 +92,7 @@ func TestApplyKeepHighest(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result := applyKeepHighest(tc.rolls, tc.val)
+			result := keptValues(applyKeepHighest(newDice(tc.rolls), tc.val))
 			if slices.Compare(result, tc.expected) != 0 {
 				t.Fatalf("expected=%d, got=%d", tc.expected, result)
 			}
@@ -110,7 +114,7 @@ func TestApplyKeepLowest(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result := applyKeepLowest(tc.rolls, tc.val)
+			result := keptValues(applyKeepLowest(newDice(tc.rolls), tc.val))
 			if slices.Compare(result, tc.expected) != 0 {
 				t.Fatalf("expected=%d, got=%d", tc.expected, result)
 			}
@@ -118,38 +122,124 @@ func TestApplyKeepLowest(t *testing.T) {
 	}
 }
 
+// dice of the same value are told apart by the order they were rolled in
+func TestApplyKeepDuplicates(t *testing.T) {
+	testCases := []struct {
+		name     string
+		rolls    []uint32
+		keep     func([]object.Die) []object.Die
+		expected []int // the indexes of the dice dropped
+	}{
+		{"kh2 of 4, 2, 4, 4", []uint32{4, 2, 4, 4}, func(dice []object.Die) []object.Die {
+			return applyKeepHighest(dice, 2)
+		}, []int{1, 3}},
+		{"kl1 of 3, 1, 1", []uint32{3, 1, 1}, func(dice []object.Die) []object.Die {
+			return applyKeepLowest(dice, 1)
+		}, []int{0, 2}},
+		{"kh3kl1 of 5, 6, 5, 5", []uint32{5, 6, 5, 5}, func(dice []object.Die) []object.Die {
+			return applyKeepLowest(applyKeepHighest(dice, 3), 1)
+		}, []int{1, 2, 3}},
+		{"kh2 of 2, 6, 6, 6", []uint32{2, 6, 6, 6}, func(dice []object.Die) []object.Die {
+			return applyKeepHighest(dice, 2)
+		}, []int{0, 3}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dropped := []int{}
+			for _, die := range tc.keep(newDice(tc.rolls)) {
+				if die.Dropped {
+					dropped = append(dropped, die.Index)
+				}
+			}
+			if !slices.Equal(dropped, tc.expected) {
+				t.Fatalf("expected=%v, got=%v", tc.expected, dropped)
+			}
+		})
+	}
+}
+
+// referenceKeep sorts the dice to find the ones kept, the highest or lowest
+// val with the first rolled of equal dice first, and returns the indexes of
+// the others
+func referenceKeep(rolls []uint32, val uint32, highest bool) []int {
+	order := []int{}
+	for i := range rolls {
+		order = append(order, i)
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		if highest {
+			return cmp.Compare(rolls[b], rolls[a])
+		}
+		return cmp.Compare(rolls[a], rolls[b])
+	})
+	dropped := order[min(int(val), len(order)):]
+	slices.Sort(dropped)
+	return dropped
+}
+
+func TestApplyKeepMatchesReference(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		rolls := make([]uint32, r.Intn(12)+1)
+		size := []int{1, 6, 20, 1000000}[r.Intn(4)]
+		for j := range rolls {
+			rolls[j] = uint32(r.Intn(size) + 1)
+		}
+		val := uint32(r.Intn(len(rolls)+1) + 1)
+
+		for _, highest := range []bool{true, false} {
+			dropped := []int{}
+			for _, die := range applyKeep(newDice(rolls), val, highest) {
+				if die.Dropped {
+					dropped = append(dropped, die.Index)
+				}
+			}
+			if expected := referenceKeep(rolls, val, highest); !slices.Equal(dropped, expected) {
+				t.Fatalf("keeping %d of %v, highest=%v: expected=%v, got=%v", val, rolls, highest, expected, dropped)
+			}
+		}
+	}
+}
+
 func TestEval(t *testing.T) {
 	testCases := []struct {
 		name     string
 		input    string
 		expected int64
-		dicedata map[string]object.DiceData
+		dicedata []object.DiceData
 	}{
-		{"sanity1", "5 + 5", 10, map[string]object.DiceData{
-			"5(0)": {Literal: "5", Tags: []string{}, RawRolls: []uint32{}, FinalRolls: []uint32{}, Value: 5},
-			"5(1)": {Literal: "5", Tags: []string{}, RawRolls: []uint32{}, FinalRolls: []uint32{}, Value: 5},
+		{"sanity1", "5 + 5", 10, []object.DiceData{
+			{Literal: "5", Tags: []string{}, Dice: []object.Die{}, Value: 5},
+			{Literal: "5", Tags: []string{}, Dice: []object.Die{}, Value: 5},
+		}},
+		{"sanity2", "5 + 5 * 2[test][another one]", 15, []object.DiceData{
+			{Literal: "5", Tags: []string{}, Dice: []object.Die{}, Value: 5},
+			{Literal: "5", Tags: []string{}, Dice: []object.Die{}, Value: 5},
+			{Literal: "2", Tags: []string{"test", "another one"}, Dice: []object.Die{}, Value: 2},
 		}},
-		{"sanity2", "5 + 5 * 2[test][another one]", 15, map[string]object.DiceData{
-			"5(0)": {Literal: "5", Tags: []string{}, RawRolls: []uint32{}, FinalRolls: []uint32{}, Value: 5},
-			"5(1)": {Literal: "5", Tags: []string{}, RawRolls: []uint32{}, FinalRolls: []uint32{}, Value: 5},
-			"2(0)": {Literal: "2", Tags: []string{"test", "another one"}, RawRolls: []uint32{}, FinalRolls: []uint32{}, Value: 2},
+		{"sanity3", "(5[first] + 5[second]) * 2[third]", 20, []object.DiceData{
+			{Literal: "5", Tags: []string{"first"}, Dice: []object.Die{}, Value: 5},
+			{Literal: "5", Tags: []string{"second"}, Dice: []object.Die{}, Value: 5},
+			{Literal: "2", Tags: []string{"third"}, Dice: []object.Die{}, Value: 2},
 		}},
-		{"sanity3", "(5[first] + 5[second]) * 2[third]", 20, map[string]object.DiceData{
-			"5(0)": {Literal: "5", Tags: []string{"first"}, RawRolls: []uint32{}, FinalRolls: []uint32{}, Value: 5},
-			"5(1)": {Literal: "5", Tags: []string{"second"}, RawRolls: []uint32{}, FinalRolls: []uint32{}, Value: 5},
-			"2(0)": {Literal: "2", Tags: []string{"third"}, RawRolls: []uint32{}, FinalRolls: []uint32{}, Value: 2},
+		{"sanity4", "-5 ^ - d1qu3", 1, []object.DiceData{
+			{Literal: "5", Tags: []string{}, Dice: []object.Die{}, Value: 5},
+			{Literal: "3d1", Tags: []string{}, Size: 1, Dice: newDice([]uint32{1, 1, 1}), Value: 3},
 		}},
-		{"sanity4", "-5 ^ - d1qu3", 1, map[string]object.DiceData{
-			"5(0)":   {Literal: "5", Tags: []string{}, RawRolls: []uint32{}, FinalRolls: []uint32{}, Value: 5},
-			"3d1(0)": {Literal: "3d1", Tags: []string{}, RawRolls: []uint32{1, 1, 1}, FinalRolls: []uint32{1, 1, 1}, Value: 3},
+		{"2d1 + 10", "d1qu2[test] + 10", 12, []object.DiceData{
+			{Literal: "2d1[test]", Tags: []string{"test"}, Size: 1, Dice: newDice([]uint32{1, 1}), Value: 2},
+			{Literal: "10", Tags: []string{}, Dice: []object.Die{}, Value: 10},
 		}},
-		{"2d1 + 10", "d1qu2[test] + 10", 12, map[string]object.DiceData{
-			"2d1[test](0)": {Literal: "2d1[test]", Tags: []string{"test"}, RawRolls: []uint32{1, 1}, FinalRolls: []uint32{1, 1}, Value: 2},
-			"10(0)":        {Literal: "10", Tags: []string{}, RawRolls: []uint32{}, FinalRolls: []uint32{}, Value: 10},
+		{"modulo zero", "7 % 0", 0, []object.DiceData{
+			{Literal: "7", Tags: []string{}, Dice: []object.Die{}, Value: 7},
+			{Literal: "0", Tags: []string{}, Dice: []object.Die{}, Value: 0},
 		}},
-		{"4d1kh3 - 2", "d1qu4kh3 - 2", 1, map[string]object.DiceData{
-			"4d1kh3(0)": {Literal: "4d1kh3", Tags: []string{}, RawRolls: []uint32{1, 1, 1, 1}, FinalRolls: []uint32{1, 1, 1}, Value: 3},
-			"2(0)":      {Literal: "2", Tags: []string{}, RawRolls: []uint32{}, FinalRolls: []uint32{}, Value: 2},
+		{"4d1kh3 - 2", "d1qu4kh3 - 2", 1, []object.DiceData{
+			{Literal: "4d1kh3", Tags: []string{}, Size: 1, Dice: []object.Die{
+				{Index: 0, Roll: 1, Value: 1}, {Index: 1, Roll: 1, Value: 1}, {Index: 2, Roll: 1, Value: 1}, {Index: 3, Roll: 1, Value: 1, Dropped: true},
+			}, Value: 3},
+			{Literal: "2", Tags: []string{}, Dice: []object.Die{}, Value: 2},
 		}},
 	}
 
@@ -164,15 +254,396 @@ func TestEval(t *testing.T) {
 			if result != tc.expected {
 				t.Fatalf("expected=%d, got=%d", int(tc.expected), result)
 			}
-			for key, tcdd := range tc.dicedata {
-				dd, ok := metadata.Store[key]
-				if !ok {
-					t.Fatalf("data not found for key=%s.\nmetadata=%v", key, metadata)
+			dicedata := metadata.Dice()
+			if len(dicedata) != len(tc.dicedata) {
+				t.Fatalf("expected %d entries, got=%d.\nmetadata=%v", len(tc.dicedata), len(dicedata), dicedata)
+			}
+			for i, tcdd := range tc.dicedata {
+				if !dicedata[i].IsEqualTo(tcdd) {
+					t.Fatalf("entry %d: expected=%v, got=%v", i, tcdd, dicedata[i])
+				}
+			}
+		})
+	}
+}
+
+func TestOperate(t *testing.T) {
+	testCases := []struct {
+		operator    string
+		left, right int64
+		expected    int64
+	}{
+		{"+", 2, 3, 5},
+		{"-", 2, 3, -1},
+		{"*", -2, 3, -6},
+		{"/", 7, 2, 3},
+		{"/", -7, 2, -3},
+		{"/", 7, 0, 7},
+		{"%", 7, 3, 1},
+		{"%", 7, 0, 0},
+		{"^", 2, 10, 1024},
+		{"^", -3, 3, -27},
+		{"^", 5, 0, 1},
+		{"^", 5, -2, 1},
+		{"^", 2, 64, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%d %s %d", tc.left, tc.operator, tc.right), func(t *testing.T) {
+			result, ok := Operate(tc.operator, tc.left, tc.right)
+			if !ok || result != tc.expected {
+				t.Fatalf("expected=%d, got=%d", tc.expected, result)
+			}
+		})
+	}
+
+	if _, ok := Operate("&", 1, 2); ok {
+		t.Fatalf("expected an unknown operator to fail")
+	}
+}
+
+func TestEvalWithSeed(t *testing.T) {
+	l := lexer.New("d20qu10 + d6qu4kh2 - d100")
+	p := parser.New(l)
+	program := p.ParseProgram()
+
+	first, firstMetadata := EvalWithSeed(program, 42)
+	second, secondMetadata := EvalWithSeed(program, 42)
+
+	if first.Inspect() != second.Inspect() {
+		t.Fatalf("expected the same value for the same seed. got=%s and %s", first.Inspect(), second.Inspect())
+	}
+	if firstMetadata.Seed != 42 || secondMetadata.Seed != 42 {
+		t.Fatalf("expected seed=42, got=%d and %d", firstMetadata.Seed, secondMetadata.Seed)
+	}
+	firstDice, secondDice := firstMetadata.Dice(), secondMetadata.Dice()
+	for i := range firstDice {
+		if !firstDice[i].IsEqualTo(secondDice[i]) {
+			t.Fatalf("entry %d: expected=%v, got=%v", i, firstDice[i], secondDice[i])
+		}
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	testCases := []struct {
+		name           string
+		input          string
+		expected       string
+		expectedSyntax bool
+	}{
+		{"empty", "", "empty dice string", false},
+		{"missing operand", "5 + )", "missing expression", true},
+		{"unclosed group", "(5", "missing expression", true},
+		{"identifier", "foo", "unknown identifier: foo", false},
+		{"zero sided dice", "d0qu2", "dice size must be at least 1, got=2d0", false},
+		{"illegal token", "d20 + @", "illegal token: @", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			l := lexer.New(tc.input)
+			p := parser.New(l)
+			program := p.ParseProgram()
+			evaluation := Eval(program, object.NewMetadata())
+			err, ok := evaluation.(*object.Error)
+			if !ok {
+				t.Fatalf("expected an error, got=%v", evaluation)
+			}
+			if err.Message != tc.expected {
+				t.Fatalf("expected=%q, got=%q", tc.expected, err.Message)
+			}
+			if err.Syntax != tc.expectedSyntax {
+				t.Fatalf("expected syntax=%t, got=%t", tc.expectedSyntax, err.Syntax)
+			}
+		})
+	}
+}
+
+func TestEvalVariables(t *testing.T) {
+	l := lexer.New("atk * 2 + dmg")
+	p := parser.New(l)
+	program := p.ParseProgram()
+	md := object.NewMetadata()
+	md.Variables = map[string]int64{"atk": 5, "dmg": 3}
+
+	evaluation := Eval(program, md)
+	integer, ok := evaluation.(*object.Integer)
+	if !ok || integer.Value != 13 {
+		t.Fatalf("expected=13, got=%v", evaluation)
+	}
+
+	variables := []string{}
+	md.Walk(func(node *object.MetadataNode) {
+		if node.Kind == object.VARIABLE_NODE {
+			variables = append(variables, fmt.Sprintf("%s=%d", node.Data.Literal, node.Value))
+		}
+	})
+	if strings.Join(variables, " ") != "atk=5 dmg=3" {
+		t.Fatalf("expected=%q, got=%q", "atk=5 dmg=3", variables)
+	}
+}
+
+func TestEvalTrace(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected []string
+	}{
+		{
+			"-(d12qu4mi2ma10kh2 + 3) * d4kl1 / 0",
+			[]string{
+				"        4d12mi2ma10kh2: rolled 4 d12: 6, 4, 12, 12",
+				"        ma10: lowered 12, 12 to 10: 6, 4, 10, 10",
+				"        mi2: no rolls below 2",
+				"        kh2: kept 10, 10, dropped 6, 4",
+				"        total: 10 + 10 = 20",
+				"        3",
+				"      20 + 3 = 23",
+				"    -(23) = -23",
+				"    d4kl1: rolled 1 d4: 2",
+				"    kl1: kept every roll: 2",
+				"  -23 * 2 = -46",
+				"  0",
+				"-46 / 0 = -46, as if by 1 since it can't be by 0",
+			},
+		},
+		{
+			"d20 x",
+			[]string{
+				"d20: rolled 1 d20: 2",
+				"x = 3",
+				"the last statement is the value: 3",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			program := parser.New(lexer.New(tc.input)).ParseProgram()
+			md := object.NewSeededMetadata(1)
+			md.Trace = true
+			md.Variables = map[string]int64{"x": 3}
+			Eval(program, md)
+
+			actual := []string{}
+			for _, step := range md.Steps {
+				actual = append(actual, strings.Repeat("  ", step.Depth)+step.Text)
+			}
+			if !slices.Equal(actual, tc.expected) {
+				t.Fatalf("expected=\n%s\ngot=\n%s", strings.Join(tc.expected, "\n"), strings.Join(actual, "\n"))
+			}
+		})
+	}
+
+	md := object.NewSeededMetadata(1)
+	Eval(parser.New(lexer.New("d20qu3kh1 + 2")).ParseProgram(), md)
+	if len(md.Steps) != 0 {
+		t.Fatalf("expected no steps without tracing, got=%v", md.Steps)
+	}
+}
+
+// programs are cached and evaluated again, so nothing recorded in the
+// metadata may share memory with the tree
+func TestEvalLeavesTreeUnchanged(t *testing.T) {
+	program := parser.New(lexer.New("d6qu2[fire] + 3[bonus]")).ParseProgram()
+	before := program.String()
+
+	for i := 0; i < 2; i++ {
+		md := object.NewSeededMetadata(int64(i))
+		Eval(program, md)
+		for _, data := range md.Dice() {
+			if len(data.Tags) != 1 || data.Tags[0] == "changed" {
+				t.Fatalf("expected the tags of the tree, got=%v", data.Tags)
+			}
+			data.Tags[0] = "changed"
+		}
+	}
+
+	if after := program.String(); after != before {
+		t.Fatalf("expected=%s, got=%s", before, after)
+	}
+}
+
+func TestEvalMaxDice(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		maxDice  int
+		expected string
+	}{
+		{"under the limit", "d6qu5 + d6", 6, ""},
+		{"single dice over the limit", "d6qu7", 6, "too many dice: 7d6 would roll more than 6 dice"},
+		{"total over the limit", "d6qu5 + d6qu2", 6, "too many dice: 2d6 would roll more than 6 dice"},
+		{"large quantity", "d20qu5000", 1000, "too many dice: 5000d20 would roll more than 1000 dice"},
+		{"no limit", "d6qu100", 0, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			program := parser.New(lexer.New(tc.input)).ParseProgram()
+			md := object.NewMetadata()
+			md.MaxDice = tc.maxDice
+			evaluation := Eval(program, md)
+
+			err, isErr := evaluation.(*object.Error)
+			if tc.expected == "" {
+				if isErr {
+					t.Fatalf("unexpected error: %s", err.Message)
 				}
-				if !dd.IsEqualTo(tcdd) {
-					t.Fatalf("expected=%v, got=%v", tcdd, dd)
+				return
+			}
+			if !isErr || err.Message != tc.expected {
+				t.Fatalf("expected=%q, got=%v", tc.expected, evaluation)
+			}
+		})
+	}
+}
+
+func TestEvalMaxDieSize(t *testing.T) {
+	testCases := []struct {
+		name       string
+		input      string
+		maxDieSize int
+		expected   string
+	}{
+		{"at the limit", "d1000qu3", 1000, ""},
+		{"over the limit", "d20 + d1001", 1000, "die too large: d1001 has more than 1000 faces"},
+		{"largest die", "d4000000000", 1000000, "die too large: d4000000000 has more than 1000000 faces"},
+		{"no limit", "d4000000000", 0, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			program := parser.New(lexer.New(tc.input)).ParseProgram()
+			md := object.NewMetadata()
+			md.MaxDieSize = tc.maxDieSize
+			evaluation := Eval(program, md)
+
+			err, isErr := evaluation.(*object.Error)
+			if tc.expected == "" {
+				if isErr {
+					t.Fatalf("unexpected error: %s", err.Message)
+				}
+				return
+			}
+			if !isErr || err.Message != tc.expected {
+				t.Fatalf("expected=%q, got=%v", tc.expected, evaluation)
+			}
+		})
+	}
+}
+
+func TestEvalCryptoMetadata(t *testing.T) {
+	program := parser.New(lexer.New("d6qu100")).ParseProgram()
+	md := object.NewCryptoMetadata()
+	Eval(program, md)
+
+	if md.Seed != 0 {
+		t.Fatalf("expected seed=0, got=%d", md.Seed)
+	}
+	for _, roll := range md.Dice()[0].RawRolls() {
+		if roll < 1 || roll > 6 {
+			t.Fatalf("expected rolls between 1 and 6, got=%d", roll)
+		}
+	}
+}
+
+func TestEvalMetadataTree(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"single literal", "d1qu2", "PROGRAM[0:5]=2(DICE[0:5]=2)"},
+		{"infix", "d1qu2 + 5", "PROGRAM[0:9]=7(INFIX+[0:9]=7(DICE[0:5]=2 INTEGER[8:9]=5))"},
+		{"nested", "(d1 + 2) * -3[x]", "PROGRAM[0:16]=-9(INFIX*[0:16]=-9(INFIX+[0:8]=3(DICE[1:3]=1 INTEGER[6:7]=2) PREFIX-[11:16]=-3(INTEGER[12:16]=3)))"},
+		{"nested parentheses", "-((d1)) + (2)", "PROGRAM[0:13]=1(INFIX+[0:13]=1(PREFIX-[0:7]=-1(DICE[1:7]=1) INTEGER[10:13]=2))"},
+		{"tagged dice", "d1ma1[a][b] - 1", "PROGRAM[0:15]=0(INFIX-[0:15]=0(DICE[0:11]=1 INTEGER[14:15]=1))"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			l := lexer.New(tc.input)
+			p := parser.New(l)
+			program := p.ParseProgram()
+			metadata := object.NewMetadata()
+			Eval(program, metadata)
+
+			result := stringifyMetadataNode(metadata.Root)
+			if result != tc.expected {
+				t.Fatalf("expected=%s, got=%s", tc.expected, result)
+			}
+		})
+	}
+}
+
+func stringifyMetadataNode(node *object.MetadataNode) string {
+	out := fmt.Sprintf("%s%s[%d:%d]=%d", node.Kind, node.Operator, node.Start, node.End, node.Value)
+	if len(node.Children) == 0 {
+		return out
+	}
+	children := []string{}
+	for _, child := range node.Children {
+		children = append(children, stringifyMetadataNode(child))
+	}
+	return out + "(" + strings.Join(children, " ") + ")"
+}
+
+// the evaluator's benchmarks roll from seeded metadata, like the server
+// does, so every roll includes seeding math/rand. BenchmarkNewMetadata is
+// that cost alone.
+func BenchmarkNewMetadata(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		object.NewSeededMetadata(int64(i))
+	}
+}
+
+func BenchmarkEval(b *testing.B) {
+	benchmarkInputs := []struct {
+		name    string
+		input   string
+		maxDice int
+	}{
+		{"single die", "d20", 0},
+		{"stats", "d6qu4kh3", 0},
+		{"keep highest of 10000", "d6qu10000kh10", 0},
+		{"keep lowest of 10000", "d6qu10000kl10", 0},
+		{"keep half of 10000", "d100qu10000mi10ma90kh5000", 0},
+		{"keep both of 10000", "d1000qu10000kh5000kl2500", 0},
+		{"200 literals", strings.Repeat("d6 + ", 199) + "d6", 10000},
+	}
+
+	for _, bc := range benchmarkInputs {
+		b.Run(bc.name, func(b *testing.B) {
+			program := parser.New(lexer.New(bc.input)).ParseProgram()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				md := object.NewSeededMetadata(int64(i))
+				md.MaxDice = bc.maxDice
+				if result := Eval(program, md); result.Type() != object.INTEGER_OBJ {
+					b.Fatalf("could not roll %q: %s", bc.input, result.Inspect())
 				}
 			}
 		})
 	}
 }
+
+func BenchmarkKeep(b *testing.B) {
+	for _, n := range []int{10, 1000, 10000} {
+		r := rand.New(rand.NewSource(1))
+		rolls := make([]uint32, n)
+		for i := range rolls {
+			rolls[i] = uint32(r.Intn(100) + 1)
+		}
+
+		b.Run(fmt.Sprintf("highest half of %d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				applyKeepHighest(newDice(rolls), uint32(n/2))
+			}
+		})
+		b.Run(fmt.Sprintf("lowest 3 of %d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				applyKeepLowest(newDice(rolls), 3)
+			}
+		})
+	}
+}
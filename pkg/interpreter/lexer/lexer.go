@@ -29,10 +29,19 @@ func (l *Lexer) readChar() {
 }
 
 func (l *Lexer) NextToken() token.Token {
-	var tok token.Token
-
 	l.skipWhitespace()
 
+	start := l.position
+	tok := l.readToken()
+	tok.Start = min(start, len(l.input))
+	tok.End = min(l.position, len(l.input))
+
+	return tok
+}
+
+func (l *Lexer) readToken() token.Token {
+	var tok token.Token
+
 	switch l.ch {
 	case '+':
 		tok = newToken(token.PLUS, l.ch)
@@ -1,6 +1,7 @@
 package lexer
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/daneofmanythings/calcuroller/pkg/interpreter/token"
@@ -50,3 +51,54 @@ func TestNextToken(t *testing.T) {
 		}
 	}
 }
+
+func TestNextTokenSpans(t *testing.T) {
+	input := `d20kh1[adv] +  14`
+
+	tests := []struct {
+		expectedType  token.TokenType
+		expectedStart int
+		expectedEnd   int
+	}{
+		{token.DICE, 0, 3},
+		{token.DICEKEEPHIGHEST, 3, 6},
+		{token.METATAG, 6, 11},
+		{token.PLUS, 12, 13},
+		{token.INT, 15, 17},
+		{token.EOF, 17, 17},
+	}
+	l := New(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+		if tok.Start != tt.expectedStart || tok.End != tt.expectedEnd {
+			t.Fatalf("tests[%d] - span wrong. expected=[%d:%d], got=[%d:%d]",
+				i, tt.expectedStart, tt.expectedEnd, tok.Start, tok.End)
+		}
+	}
+}
+
+var benchmarkInputs = []struct {
+	name  string
+	input string
+}{
+	{"attack", "d20 + 5 + d4[bless]"},
+	{"modifiers", "d12qu4mi2ma10kh2kl1[cold][crit] * (3 - d6) ^ 2 % 7"},
+	{"long", strings.Repeat("d6qu4kh3[str] + ", 100) + "5"},
+}
+
+func BenchmarkNextToken(b *testing.B) {
+	for _, bc := range benchmarkInputs {
+		b.Run(bc.name, func(b *testing.B) {
+			b.SetBytes(int64(len(bc.input)))
+			for i := 0; i < b.N; i++ {
+				l := New(bc.input)
+				for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,265 @@
+package object
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// JSONVersion is the version of the JSON encoding of RollResult and Metadata.
+// It is bumped whenever a field is removed or changes meaning. Adding a field
+// does not bump the version, so decoders should ignore fields they don't know.
+//
+// Version 1:
+//
+//	RollResult   {"version", "literal", "value", "error", "metadata"}
+//	Metadata     {"version", "seed", "root", "steps"}
+//	MetadataNode {"kind", "operator", "start", "end", "value", "data", "children"}
+//	DiceData     {"literal", "tags", "size", "dice", "raw_rolls", "final_rolls", "dropped_rolls", "value"}
+//	Die          {"index", "roll", "value", "clamped", "dropped"}
+//	Step         {"depth", "kind", "start", "end", "text"}
+//
+// "error" is only present on failed rolls, "data" is only present on DICE,
+// INTEGER and VARIABLE nodes, and "steps" is only present on traced
+// evaluations. Lists are always encoded as arrays, never null.
+//
+// "raw_rolls", "final_rolls" and "dropped_rolls" are the rolls of "dice", in
+// roll order. Rolls encoded before "dice" was added are decoded from them.
+const JSONVersion = 1
+
+type diceDataJSON struct {
+	Literal      string    `json:"literal"`
+	Tags         []string  `json:"tags"`
+	Size         uint32    `json:"size"`
+	Dice         []dieJSON `json:"dice"`
+	RawRolls     []uint32  `json:"raw_rolls"`
+	FinalRolls   []uint32  `json:"final_rolls"`
+	DroppedRolls []uint32  `json:"dropped_rolls"`
+	Value        int64     `json:"value"`
+}
+
+type dieJSON struct {
+	Index   int    `json:"index"`
+	Roll    uint32 `json:"roll"`
+	Value   uint32 `json:"value"`
+	Clamped bool   `json:"clamped"`
+	Dropped bool   `json:"dropped"`
+}
+
+type metadataNodeJSON struct {
+	Kind     NodeKind        `json:"kind"`
+	Operator string          `json:"operator"`
+	Start    int             `json:"start"`
+	End      int             `json:"end"`
+	Value    int64           `json:"value"`
+	Data     *DiceData       `json:"data,omitempty"`
+	Children []*MetadataNode `json:"children"`
+}
+
+type stepJSON struct {
+	Depth int      `json:"depth"`
+	Kind  NodeKind `json:"kind"`
+	Start int      `json:"start"`
+	End   int      `json:"end"`
+	Text  string   `json:"text"`
+}
+
+type metadataJSON struct {
+	Version int           `json:"version"`
+	Seed    int64         `json:"seed"`
+	Root    *MetadataNode `json:"root"`
+	Steps   []stepJSON    `json:"steps,omitempty"`
+}
+
+type rollResultJSON struct {
+	Version  int       `json:"version"`
+	Literal  string    `json:"literal"`
+	Value    int64     `json:"value"`
+	Error    string    `json:"error,omitempty"`
+	Metadata *Metadata `json:"metadata"`
+}
+
+func (dd DiceData) MarshalJSON() ([]byte, error) {
+	dice := make([]dieJSON, 0, len(dd.Dice))
+	for _, die := range dd.Dice {
+		dice = append(dice, dieJSON(die))
+	}
+	return json.Marshal(diceDataJSON{
+		Literal:      dd.Literal,
+		Tags:         nonNil(dd.Tags),
+		Size:         dd.Size,
+		Dice:         dice,
+		RawRolls:     dd.RawRolls(),
+		FinalRolls:   dd.FinalRolls(),
+		DroppedRolls: dd.DroppedRolls(),
+		Value:        dd.Value,
+	})
+}
+
+func (dd *DiceData) UnmarshalJSON(data []byte) error {
+	var decoded diceDataJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	dice := make([]Die, 0, len(decoded.Dice))
+	for _, die := range decoded.Dice {
+		dice = append(dice, Die(die))
+	}
+	if decoded.Dice == nil {
+		dice = legacyDice(decoded.RawRolls, decoded.FinalRolls, decoded.DroppedRolls)
+	}
+
+	*dd = DiceData{
+		Literal: decoded.Literal,
+		Tags:    nonNil(decoded.Tags),
+		Size:    decoded.Size,
+		Dice:    dice,
+		Value:   decoded.Value,
+	}
+	return nil
+}
+
+// legacyDice rebuilds the dice of a roll encoded before "dice" was added.
+// mi and ma never swap two rolls, so the kept and dropped values sorted line
+// up with the raw rolls sorted. Kept rolls weren't in roll order then, so the
+// first of several equal dice are taken to be the ones kept.
+func legacyDice(raw, final, dropped []uint32) []Die {
+	dice := make([]Die, 0, len(raw))
+	for i, roll := range raw {
+		dice = append(dice, Die{Index: i, Roll: roll, Value: roll})
+	}
+
+	values := append(slices.Clone(final), dropped...)
+	if len(values) != len(raw) {
+		return dice
+	}
+	slices.Sort(values)
+	order := make([]int, 0, len(raw))
+	for i := range raw {
+		order = append(order, i)
+	}
+	slices.SortStableFunc(order, func(a, b int) int { return cmp.Compare(raw[a], raw[b]) })
+	for j, i := range order {
+		dice[i].Value = values[j]
+		dice[i].Clamped = values[j] != raw[i]
+	}
+
+	kept := map[uint32]int{}
+	for _, value := range final {
+		kept[value]++
+	}
+	for i := range dice {
+		if kept[dice[i].Value] > 0 {
+			kept[dice[i].Value]--
+		} else {
+			dice[i].Dropped = true
+		}
+	}
+	return dice
+}
+
+func (mn *MetadataNode) MarshalJSON() ([]byte, error) {
+	return json.Marshal(metadataNodeJSON{
+		Kind:     mn.Kind,
+		Operator: mn.Operator,
+		Start:    mn.Start,
+		End:      mn.End,
+		Value:    mn.Value,
+		Data:     mn.Data,
+		Children: nonNil(mn.Children),
+	})
+}
+
+func (mn *MetadataNode) UnmarshalJSON(data []byte) error {
+	var decoded metadataNodeJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	*mn = MetadataNode{
+		Kind:     decoded.Kind,
+		Operator: decoded.Operator,
+		Start:    decoded.Start,
+		End:      decoded.End,
+		Value:    decoded.Value,
+		Data:     decoded.Data,
+		Children: nonNil(decoded.Children),
+	}
+	return nil
+}
+
+func (m *Metadata) MarshalJSON() ([]byte, error) {
+	steps := []stepJSON{}
+	for _, step := range m.Steps {
+		steps = append(steps, stepJSON(step))
+	}
+	return json.Marshal(metadataJSON{Version: JSONVersion, Seed: m.Seed, Root: m.Root, Steps: steps})
+}
+
+func (m *Metadata) UnmarshalJSON(data []byte) error {
+	var decoded metadataJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if err := checkJSONVersion(decoded.Version); err != nil {
+		return err
+	}
+
+	*m = Metadata{Root: decoded.Root, Seed: decoded.Seed, stack: []*MetadataNode{}}
+	for _, step := range decoded.Steps {
+		m.Steps = append(m.Steps, Step(step))
+	}
+	return nil
+}
+
+func (rr *RollResult) MarshalJSON() ([]byte, error) {
+	md := rr.Metadata
+	if md == nil {
+		md = &Metadata{}
+	}
+
+	return json.Marshal(rollResultJSON{
+		Version:  JSONVersion,
+		Literal:  rr.Literal,
+		Value:    rr.Value,
+		Error:    rr.Error,
+		Metadata: md,
+	})
+}
+
+func (rr *RollResult) UnmarshalJSON(data []byte) error {
+	var decoded rollResultJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if err := checkJSONVersion(decoded.Version); err != nil {
+		return err
+	}
+	if decoded.Metadata == nil {
+		decoded.Metadata = &Metadata{stack: []*MetadataNode{}}
+	}
+
+	*rr = RollResult{
+		Literal:  decoded.Literal,
+		Value:    decoded.Value,
+		Error:    decoded.Error,
+		Metadata: decoded.Metadata,
+	}
+	return nil
+}
+
+func checkJSONVersion(version int) error {
+	if version != JSONVersion {
+		return fmt.Errorf("unsupported json version %d. expected=%d", version, JSONVersion)
+	}
+	return nil
+}
+
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
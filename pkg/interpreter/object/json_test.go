@@ -0,0 +1,160 @@
+package object
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func newTestRollResult() *RollResult {
+	md := NewSeededMetadata(7)
+	md.Open(PROGRAM_NODE, "", 0, 13)
+	md.Open(INFIX_NODE, "+", 0, 13)
+	md.Add(DICE_NODE, 0, 9, DiceData{
+		Literal: "2d20kh1",
+		Tags:    []string{"adv"},
+		Size:    20,
+		Dice: []Die{
+			{Index: 0, Roll: 4, Value: 4, Dropped: true},
+			{Index: 1, Roll: 17, Value: 17},
+		},
+		Value: 17,
+	})
+	md.Add(INTEGER_NODE, 12, 13, DiceData{Literal: "5", Value: 5})
+	md.Close(&Integer{Value: 22})
+	md.Close(&Integer{Value: 22})
+
+	return &RollResult{Literal: "d20qu2kh1 + 5", Value: 22, Metadata: md}
+}
+
+func newTracedRollResult() *RollResult {
+	result := newTestRollResult()
+	result.Metadata.Steps = []Step{
+		{Depth: 2, Kind: DICE_NODE, Start: 0, End: 9, Text: "2d20kh1: rolled 2 d20: 4, 17"},
+		{Depth: 2, Kind: DICE_NODE, Start: 0, End: 9, Text: "kh1: kept 17, dropped 4"},
+		{Depth: 1, Kind: INFIX_NODE, Start: 0, End: 13, Text: "17 + 5 = 22"},
+	}
+	return result
+}
+
+func TestRollResultJSONRoundTrip(t *testing.T) {
+	testCases := []struct {
+		name   string
+		result *RollResult
+	}{
+		{"roll", newTestRollResult()},
+		{"traced", newTracedRollResult()},
+		{"error", &RollResult{Literal: "5 + @", Error: "illegal token: @", Metadata: NewMetadata()}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			encoded, err := json.Marshal(tc.result)
+			if err != nil {
+				t.Fatalf("could not encode: %v", err)
+			}
+
+			decoded := &RollResult{}
+			if err := json.Unmarshal(encoded, decoded); err != nil {
+				t.Fatalf("could not decode: %v", err)
+			}
+
+			reencoded, err := json.Marshal(decoded)
+			if err != nil {
+				t.Fatalf("could not re-encode: %v", err)
+			}
+			if string(encoded) != string(reencoded) {
+				t.Fatalf("round trip changed the encoding.\nexpected=%s\ngot=%s", encoded, reencoded)
+			}
+			expectedDice, decodedDice := tc.result.Metadata.Dice(), decoded.Metadata.Dice()
+			if len(expectedDice) != len(decodedDice) {
+				t.Fatalf("expected %d entries, got=%d", len(expectedDice), len(decodedDice))
+			}
+			for i := range expectedDice {
+				if !expectedDice[i].IsEqualTo(decodedDice[i]) {
+					t.Fatalf("entry %d: expected=%v, got=%v", i, expectedDice[i], decodedDice[i])
+				}
+			}
+			if !slices.Equal(decoded.Metadata.Steps, tc.result.Metadata.Steps) {
+				t.Fatalf("expected=%v, got=%v", tc.result.Metadata.Steps, decoded.Metadata.Steps)
+			}
+			if decoded.Value != tc.result.Value || decoded.Error != tc.result.Error {
+				t.Fatalf("expected=%v, got=%v", tc.result, decoded)
+			}
+		})
+	}
+}
+
+func TestRollResultJSONSchema(t *testing.T) {
+	expected := `{"version":1,"literal":"d20qu2kh1 + 5","value":22,"metadata":{"version":1,"seed":7,"root":` +
+		`{"kind":"PROGRAM","operator":"","start":0,"end":13,"value":22,"children":[` +
+		`{"kind":"INFIX","operator":"+","start":0,"end":13,"value":22,"children":[` +
+		`{"kind":"DICE","operator":"","start":0,"end":9,"value":17,"data":{"literal":"2d20kh1","tags":["adv"],"size":20,` +
+		`"dice":[{"index":0,"roll":4,"value":4,"clamped":false,"dropped":true},{"index":1,"roll":17,"value":17,"clamped":false,"dropped":false}],` +
+		`"raw_rolls":[4,17],"final_rolls":[17],"dropped_rolls":[4],"value":17},"children":[]},` +
+		`{"kind":"INTEGER","operator":"","start":12,"end":13,"value":5,"data":{"literal":"5","tags":[],"size":0,"dice":[],"raw_rolls":[],"final_rolls":[],"dropped_rolls":[],"value":5},"children":[]}` +
+		`]}]}}}`
+
+	encoded, err := json.Marshal(newTestRollResult())
+	if err != nil {
+		t.Fatalf("could not encode: %v", err)
+	}
+	if string(encoded) != expected {
+		t.Fatalf("expected=%s\ngot=%s", expected, encoded)
+	}
+}
+
+// rolls encoded before "dice" was added only have the lists of rolls, with
+// the kept rolls of equal values together
+func TestDiceDataJSONWithoutDice(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected []Die
+	}{
+		{"kept duplicates", `{"raw_rolls":[4,2,4,4],"final_rolls":[4,4],"dropped_rolls":[2,4]}`, []Die{
+			{Index: 0, Roll: 4, Value: 4},
+			{Index: 1, Roll: 2, Value: 2, Dropped: true},
+			{Index: 2, Roll: 4, Value: 4},
+			{Index: 3, Roll: 4, Value: 4, Dropped: true},
+		}},
+		{"clamped", `{"raw_rolls":[1,5,5,3],"final_rolls":[5,5],"dropped_rolls":[2,3]}`, []Die{
+			{Index: 0, Roll: 1, Value: 2, Clamped: true, Dropped: true},
+			{Index: 1, Roll: 5, Value: 5},
+			{Index: 2, Roll: 5, Value: 5},
+			{Index: 3, Roll: 3, Value: 3, Dropped: true},
+		}},
+		{"integer", `{"raw_rolls":[],"final_rolls":[],"dropped_rolls":[]}`, []Die{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var decoded DiceData
+			if err := json.Unmarshal([]byte(tc.input), &decoded); err != nil {
+				t.Fatalf("could not decode: %v", err)
+			}
+			if !slices.Equal(decoded.Dice, tc.expected) {
+				t.Fatalf("expected=%v, got=%v", tc.expected, decoded.Dice)
+			}
+		})
+	}
+}
+
+func TestRollResultJSONRejectsUnknownVersion(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+	}{
+		{"missing version", `{"literal":"d20","value":3}`},
+		{"future version", `{"version":2,"literal":"d20","value":3}`},
+		{"future metadata version", `{"version":1,"literal":"d20","value":3,"metadata":{"version":2}}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := json.Unmarshal([]byte(tc.input), &RollResult{}); err == nil {
+				t.Fatalf("expected an error decoding %s", tc.input)
+			}
+		})
+	}
+}
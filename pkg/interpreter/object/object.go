@@ -2,7 +2,10 @@ package object
 
 import (
 	"bytes"
+	crand "crypto/rand"
+	"encoding/binary"
 	"fmt"
+	"math/rand"
 	"slices"
 	"strings"
 )
@@ -29,17 +32,29 @@ func (i *Integer) Inspect() string  { return fmt.Sprintf("%d", i.Value) }
 
 type Error struct {
 	Message string
+	Syntax  bool // the dice string could not be parsed, as opposed to rolled
 }
 
 func (e *Error) Type() ObjectType { return ERROR_OBJ }
 func (e *Error) Inspect() string  { return "ERROR: " + e.Message }
 
+// Die is one die rolled for a dice literal. Dice are told apart by the order
+// they were rolled in, so it is known which of several equal rolls a
+// modifier changed or dropped.
+type Die struct {
+	Index   int    // the order the die was rolled in, from 0
+	Roll    uint32 // the face rolled
+	Value   uint32 // the roll after mi and ma
+	Clamped bool   // mi or ma changed the roll
+	Dropped bool   // kh or kl discarded the die, so it isn't in the value
+}
+
 type DiceData struct {
-	Literal    string
-	Tags       []string
-	RawRolls   []uint32
-	FinalRolls []uint32
-	Value      int64
+	Literal string
+	Tags    []string
+	Size    uint32
+	Dice    []Die // in the order they were rolled
+	Value   int64
 }
 
 func (dd *DiceData) Type() ObjectType { return DICE_OBJ }
@@ -53,21 +68,59 @@ func (dd *DiceData) Inspect() string {
 		out.WriteString("Tags: " + tags + "\n")
 	}
 
-	if len(dd.RawRolls) > 0 {
-		rawAsString := uintSliceToString(dd.RawRolls)
+	if rawRolls := dd.RawRolls(); len(rawRolls) > 0 {
+		rawAsString := uintSliceToString(rawRolls)
 		out.WriteString("Raw Rolls: " + rawAsString + "\n")
 	}
 
-	if len(dd.FinalRolls) > 0 {
-		finalAsString := uintSliceToString(dd.FinalRolls)
+	if finalRolls := dd.FinalRolls(); len(finalRolls) > 0 {
+		finalAsString := uintSliceToString(finalRolls)
 		out.WriteString("Final Rolls: " + finalAsString + "\n")
 	}
 
+	if droppedRolls := dd.DroppedRolls(); len(droppedRolls) > 0 {
+		droppedAsString := uintSliceToString(droppedRolls)
+		out.WriteString("Dropped Rolls: " + droppedAsString + "\n")
+	}
+
 	out.WriteString("Value: " + fmt.Sprintf("%d", dd.Value) + "\n")
 
 	return out.String()
 }
 
+// RawRolls are the faces of every die, in the order they were rolled.
+func (dd DiceData) RawRolls() []uint32 {
+	rolls := make([]uint32, 0, len(dd.Dice))
+	for _, die := range dd.Dice {
+		rolls = append(rolls, die.Roll)
+	}
+	return rolls
+}
+
+// FinalRolls are the values of the dice counted in Value, in the order they
+// were rolled.
+func (dd DiceData) FinalRolls() []uint32 {
+	rolls := make([]uint32, 0, len(dd.Dice))
+	for _, die := range dd.Dice {
+		if !die.Dropped {
+			rolls = append(rolls, die.Value)
+		}
+	}
+	return rolls
+}
+
+// DroppedRolls are the values of the dice kh or kl discarded, in the order
+// they were rolled.
+func (dd DiceData) DroppedRolls() []uint32 {
+	rolls := []uint32{}
+	for _, die := range dd.Dice {
+		if die.Dropped {
+			rolls = append(rolls, die.Value)
+		}
+	}
+	return rolls
+}
+
 func uintSliceToString(input []uint32) string {
 	var out bytes.Buffer
 	for _, num := range input {
@@ -80,34 +133,233 @@ func (dd *DiceData) IsEqualTo(other DiceData) bool {
 	// for testing purposes to compare equality
 	isLit := dd.Literal == other.Literal
 	isTags := slices.Compare(dd.Tags, other.Tags) == 0
-	isRawRolls := slices.Compare(dd.RawRolls, other.RawRolls) == 0
-	isFinalRolls := slices.Compare(dd.FinalRolls, other.FinalRolls) == 0
+	isSize := dd.Size == other.Size
+	isDice := slices.Equal(dd.Dice, other.Dice)
 	isValue := dd.Value == other.Value
 
-	return isLit && isTags && isRawRolls && isFinalRolls && isValue
+	return isLit && isTags && isSize && isDice && isValue
+}
+
+// RollResult is everything produced by evaluating a single request literal.
+// Error is only set when the evaluation failed, in which case Value is zero.
+type RollResult struct {
+	Literal  string
+	Value    int64
+	Error    string
+	Metadata *Metadata
 }
 
+func NewRollResult(literal string, value Object, md *Metadata) *RollResult {
+	result := &RollResult{Literal: literal, Metadata: md}
+
+	switch value := value.(type) {
+	case *Integer:
+		result.Value = value.Value
+	case *Error:
+		result.Error = value.Message
+	default:
+		result.Error = "no value produced"
+	}
+
+	return result
+}
+
+type NodeKind string
+
+const (
+	PROGRAM_NODE  NodeKind = "PROGRAM"
+	PREFIX_NODE   NodeKind = "PREFIX"
+	INFIX_NODE    NodeKind = "INFIX"
+	DICE_NODE     NodeKind = "DICE"
+	INTEGER_NODE  NodeKind = "INTEGER"
+	VARIABLE_NODE NodeKind = "VARIABLE"
+)
+
+// MetadataNode mirrors a single node of the evaluated ast.Program. Start and
+// End are the byte offsets the node covers in the request literal.
+type MetadataNode struct {
+	Kind     NodeKind
+	Operator string
+	Start    int
+	End      int
+	Value    int64
+	Data     *DiceData // only set on DICE, INTEGER and VARIABLE nodes
+	Children []*MetadataNode
+}
+
+// Step is one step of an evaluation, recorded when Metadata.Trace is set.
+// Depth is how many nodes enclose the node the step was taken at, not counting
+// the program, and Start and End are the byte offsets it covers in the
+// request literal.
+type Step struct {
+	Depth int
+	Kind  NodeKind
+	Start int
+	End   int
+	Text  string
+}
+
+// Metadata is the ordered tree of everything rolled while evaluating a
+// program. Nodes are opened and closed by the evaluator as it walks the ast.
+// Every die is rolled from a source seeded with Seed, so evaluating the same
+// program with the same seed reproduces the same rolls.
 type Metadata struct {
-	Store map[string]DiceData
+	Root *MetadataNode
+	Seed int64
+	// MaxDice limits how many dice a single evaluation may roll. 0 is no limit.
+	MaxDice int
+	// MaxDieSize limits how many faces a die may have. 0 is no limit.
+	MaxDieSize int
+	// Variables are the values identifiers evaluate to, set by scripts.
+	Variables map[string]int64
+	// Trace records every step of the evaluation in Steps, in the order they
+	// were taken: each roll, each modifier applied to it and each operation.
+	Trace  bool
+	Steps  []Step
+	rand   *rand.Rand
+	stack  []*MetadataNode
+	rolled int // dice added so far
 }
 
 func NewMetadata() *Metadata {
-	s := make(map[string]DiceData)
-	return &Metadata{Store: s}
-}
-
-func (m *Metadata) Add(literal string, val DiceData) {
-	// NOTE: handles collisions very sloppily. There shouldn't be too many though.
-	collisionCounter := 0
-	quantifier := "(%d)"
-	literal += fmt.Sprintf(quantifier, collisionCounter)
-	for {
-		if _, ok := m.Store[literal]; ok {
-			collisionCounter += 1
-			literal = literal[:len(literal)-3] + fmt.Sprintf(quantifier, collisionCounter)
-		} else {
-			break
+	return NewSeededMetadata(rand.Int63())
+}
+
+func NewSeededMetadata(seed int64) *Metadata {
+	return &Metadata{
+		Seed:  seed,
+		rand:  rand.New(rand.NewSource(seed)),
+		stack: []*MetadataNode{},
+	}
+}
+
+// NewCryptoMetadata rolls dice from crypto/rand. The rolls can't be
+// reproduced, so the Seed is 0.
+func NewCryptoMetadata() *Metadata {
+	return &Metadata{
+		rand:  rand.New(cryptoSource{}),
+		stack: []*MetadataNode{},
+	}
+}
+
+type cryptoSource struct{}
+
+func (cryptoSource) Int63() int64 {
+	return int64(cryptoSource{}.Uint64() &^ (1 << 63))
+}
+
+func (cryptoSource) Uint64() uint64 {
+	var b [8]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		panic(err) // crypto/rand never fails on supported platforms
+	}
+	return binary.BigEndian.Uint64(b[:])
+}
+
+func (cryptoSource) Seed(int64) {}
+
+// Rand is the source dice are rolled from. It is not safe for concurrent use.
+func (m *Metadata) Rand() *rand.Rand {
+	if m.rand == nil {
+		m.rand = rand.New(rand.NewSource(m.Seed))
+	}
+	return m.rand
+}
+
+// Open attaches a new node to the currently open one and makes it the target
+// for subsequent nodes until Close is called.
+func (m *Metadata) Open(kind NodeKind, operator string, start, end int) *MetadataNode {
+	node := &MetadataNode{
+		Kind:     kind,
+		Operator: operator,
+		Start:    start,
+		End:      end,
+		Children: []*MetadataNode{},
+	}
+	m.attach(node)
+	m.stack = append(m.stack, node)
+	return node
+}
+
+// Close records the evaluated value on the most recently opened node.
+func (m *Metadata) Close(result Object) {
+	if len(m.stack) == 0 {
+		return
+	}
+	node := m.stack[len(m.stack)-1]
+	m.stack = m.stack[:len(m.stack)-1]
+	if integer, ok := result.(*Integer); ok {
+		node.Value = integer.Value
+	}
+}
+
+// Add attaches a leaf holding the roll data for a dice or integer literal.
+func (m *Metadata) Add(kind NodeKind, start, end int, val DiceData) {
+	m.rolled += len(val.Dice)
+	m.attach(&MetadataNode{
+		Kind:     kind,
+		Start:    start,
+		End:      end,
+		Value:    val.Value,
+		Data:     &val,
+		Children: []*MetadataNode{},
+	})
+}
+
+// AddStep records a step taken at the node most recently added or closed, if
+// tracing.
+func (m *Metadata) AddStep(kind NodeKind, start, end int, format string, a ...any) {
+	if !m.Trace {
+		return
+	}
+	m.Steps = append(m.Steps, Step{
+		Depth: max(len(m.stack)-1, 0),
+		Kind:  kind,
+		Start: start,
+		End:   end,
+		Text:  fmt.Sprintf(format, a...),
+	})
+}
+
+func (m *Metadata) attach(node *MetadataNode) {
+	switch {
+	case len(m.stack) > 0:
+		parent := m.stack[len(m.stack)-1]
+		parent.Children = append(parent.Children, node)
+	case m.Root == nil:
+		m.Root = node
+	default:
+		m.Root.Children = append(m.Root.Children, node)
+	}
+}
+
+// Rolled is how many dice were added so far, as limited by MaxDice.
+func (m *Metadata) Rolled() int {
+	return m.rolled
+}
+
+// Dice returns the data of every literal in the order it appears in the source.
+func (m *Metadata) Dice() []DiceData {
+	result := []DiceData{}
+	m.Walk(func(node *MetadataNode) {
+		if node.Data != nil {
+			result = append(result, *node.Data)
+		}
+	})
+	return result
+}
+
+// Walk visits every node of the tree depth first, parents before children.
+func (m *Metadata) Walk(f func(*MetadataNode)) {
+	var walk func(*MetadataNode)
+	walk = func(node *MetadataNode) {
+		if node == nil {
+			return
+		}
+		f(node)
+		for _, child := range node.Children {
+			walk(child)
 		}
 	}
-	m.Store[literal] = val
+	walk(m.Root)
 }
@@ -204,6 +204,7 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	for p.peekToken.Type == token.METATAG {
 		p.nextToken()
 		lit.Tags = append(lit.Tags, p.curToken.Literal)
+		lit.End = p.curToken.End
 	}
 
 	return lit
@@ -220,6 +221,7 @@ func (p *Parser) parseDiceExpression() ast.Expression {
 	for slices.Contains(token.DiceMods, p.peekToken.Type) {
 		p.nextToken()
 		p.dicemodParseFns[p.curToken.Type](dice)
+		dice.End = p.curToken.End
 	}
 
 	return dice
@@ -261,6 +263,7 @@ func (p *Parser) validateIntToUInt(lit string) uint32 {
 }
 
 func (p *Parser) parseGroupedExpression() ast.Expression {
+	start := p.curToken.Start
 	p.nextToken()
 
 	exp := p.parseExpression(LOWEST)
@@ -268,6 +271,7 @@ func (p *Parser) parseGroupedExpression() ast.Expression {
 	if !p.expectPeek(token.RPAREN) {
 		return nil
 	}
+	ast.Group(exp, ast.Span{Start: start, End: p.curToken.End})
 	return exp
 }
 
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/daneofmanythings/calcuroller/pkg/interpreter/ast"
@@ -319,3 +320,95 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 		}
 	}
 }
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"d20", "d20"},
+		{"  d20+5 ", "d20 + 5"},
+		{"d6kl1qu4kh3ma5mi2", "d6qu4mi2ma5kh3kl1"},
+		{"d6[fire]qu2[cold]", "d6qu2[fire][cold]"},
+		{"d6qu1", "d6"},
+		{"d6qu0", "d6"},
+		{"5[bonus]", "5[bonus]"},
+		{"((1 + 2)) * 3", "(1 + 2) * 3"},
+		{"1 + (2 * 3)", "1 + 2 * 3"},
+		{"(1 + 2) + 3", "1 + 2 + 3"},
+		{"1 + (2 + 3)", "1 + (2 + 3)"},
+		{"1 - (2 - 3)", "1 - (2 - 3)"},
+		{"(2 ^ 3) ^ 2", "2 ^ 3 ^ 2"},
+		{"2 ^ (3 ^ 2)", "2 ^ (3 ^ 2)"},
+		{"-(5 + 5)", "-(5 + 5)"},
+		{"(-5) ^ 2", "-5 ^ 2"},
+		{"-(5 ^ 2)", "-(5 ^ 2)"},
+		{"2--3", "2 - -3"},
+		{"str+d4", "str + d4"},
+		{"d20 (d6)", "d20 d6"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			p := New(lexer.New(tt.input))
+			program := p.ParseProgram()
+			checkParserErrors(t, p)
+
+			actual, err := ast.Format(program)
+			if err != nil {
+				t.Fatalf("could not format: %v", err)
+			}
+			if actual != tt.expected {
+				t.Fatalf("expected=%q, got=%q", tt.expected, actual)
+			}
+
+			// the canonical source is already formatted
+			reformatted, err := ast.Format(New(lexer.New(actual)).ParseProgram())
+			if err != nil || reformatted != actual {
+				t.Errorf("expected=%q, got=%q (%v)", actual, reformatted, err)
+			}
+		})
+	}
+}
+
+func TestFormatErrors(t *testing.T) {
+	tests := []string{
+		"d20 + ",
+		"d20 $ 4",
+		"* 3",
+	}
+
+	for _, input := range tests {
+		t.Run(input, func(t *testing.T) {
+			program := New(lexer.New(input)).ParseProgram()
+			if actual, err := ast.Format(program); err == nil {
+				t.Errorf("expected an error, got=%q", actual)
+			}
+		})
+	}
+}
+
+func BenchmarkParseProgram(b *testing.B) {
+	benchmarkInputs := []struct {
+		name  string
+		input string
+	}{
+		{"attack", "d20 + 5 + d4[bless]"},
+		{"modifiers", "d12qu4mi2ma10kh2kl1[cold][crit] * (3 - d6) ^ 2 % 7"},
+		{"long", strings.Repeat("d6qu4kh3[str] + ", 100) + "5"},
+		{"nested", strings.Repeat("(", 100) + "d6" + strings.Repeat(" + 1)", 100)},
+	}
+
+	for _, bc := range benchmarkInputs {
+		b.Run(bc.name, func(b *testing.B) {
+			b.SetBytes(int64(len(bc.input)))
+			for i := 0; i < b.N; i++ {
+				p := New(lexer.New(bc.input))
+				p.ParseProgram()
+				if len(p.Errors()) > 0 {
+					b.Fatalf("could not parse %q: %v", bc.input, p.Errors())
+				}
+			}
+		})
+	}
+}
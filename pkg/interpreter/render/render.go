@@ -0,0 +1,251 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/object"
+)
+
+type Format string
+
+const (
+	TEXT     Format = "text"
+	ANSI     Format = "ansi"
+	MARKDOWN Format = "markdown"
+	HTML     Format = "html"
+	JSON     Format = "json"
+)
+
+var Formats []Format = []Format{
+	TEXT,
+	ANSI,
+	MARKDOWN,
+	HTML,
+	JSON,
+}
+
+func ParseFormat(input string) (Format, error) {
+	for _, format := range Formats {
+		if strings.EqualFold(input, string(format)) {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unknown format %q. expected one of %v", input, Formats)
+}
+
+// Render formats a roll result. Every format except JSON renders the result
+// inline, ex: 'd20 (14) + 5 = 19'.
+func Render(result *object.RollResult, format Format) (string, error) {
+	switch format {
+	case TEXT:
+		return renderWithStyle(result, textStyle), nil
+	case ANSI:
+		return renderWithStyle(result, ansiStyle), nil
+	case MARKDOWN:
+		return renderWithStyle(result, markdownStyle), nil
+	case HTML:
+		return renderWithStyle(result, htmlStyle), nil
+	case JSON:
+		out, err := json.Marshal(result)
+		if err != nil {
+			return "", err
+		}
+		return string(out), nil
+	default:
+		return "", fmt.Errorf("unknown format %q. expected one of %v", format, Formats)
+	}
+}
+
+// style holds the decorations a format applies to each part of a result
+type style struct {
+	escape  func(string) string
+	literal func(string) string
+	roll    func(string) string
+	crit    func(string) string
+	fumble  func(string) string
+	dropped func(string) string
+	total   func(string) string
+	error   func(string) string
+	wrap    func(string) string
+}
+
+func identity(s string) string { return s }
+
+func wrapWith(prefix, suffix string) func(string) string {
+	return func(s string) string { return prefix + s + suffix }
+}
+
+var textStyle = style{
+	escape:  identity,
+	literal: identity,
+	roll:    identity,
+	crit:    identity,
+	fumble:  identity,
+	dropped: wrapWith("~", "~"),
+	total:   identity,
+	error:   wrapWith("(error) ", ""),
+	wrap:    identity,
+}
+
+const (
+	ansiReset   = "\x1b[0m"
+	ansiBold    = "\x1b[1m"
+	ansiDropped = "\x1b[2;9m" // dim and struck through
+	ansiCrit    = "\x1b[1;32m"
+	ansiFumble  = "\x1b[1;31m"
+	ansiLiteral = "\x1b[36m"
+)
+
+var ansiStyle = style{
+	escape:  identity,
+	literal: wrapWith(ansiLiteral, ansiReset),
+	roll:    identity,
+	crit:    wrapWith(ansiCrit, ansiReset),
+	fumble:  wrapWith(ansiFumble, ansiReset),
+	dropped: wrapWith(ansiDropped, ansiReset),
+	total:   wrapWith(ansiBold, ansiReset),
+	error:   wrapWith(ansiFumble+"(error)"+ansiReset+" ", ""),
+	wrap:    identity,
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "~", `\~`, "`", "\\`",
+	"[", `\[`, "]", `\]`, "<", `\<`, ">", `\>`, "#", `\#`,
+)
+
+var markdownStyle = style{
+	escape:  markdownEscaper.Replace,
+	literal: wrapWith("`", "`"),
+	roll:    identity,
+	crit:    wrapWith("**", "**"),
+	fumble:  wrapWith("_", "_"),
+	dropped: wrapWith("~~", "~~"),
+	total:   wrapWith("**", "**"),
+	error:   wrapWith("**error:** ", ""),
+	wrap:    identity,
+}
+
+var htmlStyle = style{
+	escape:  html.EscapeString,
+	literal: wrapWith(`<span class="literal">`, "</span>"),
+	roll:    wrapWith(`<span class="die">`, "</span>"),
+	crit:    wrapWith(`<span class="die crit">`, "</span>"),
+	fumble:  wrapWith(`<span class="die fumble">`, "</span>"),
+	dropped: wrapWith(`<span class="die dropped">`, "</span>"),
+	total:   wrapWith(`<span class="total">`, "</span>"),
+	error:   wrapWith(`<span class="error">`, "</span>"),
+	wrap:    wrapWith(`<span class="roll">`, "</span>"),
+}
+
+func renderWithStyle(result *object.RollResult, s style) string {
+	if result.Error != "" {
+		return s.wrap(s.error(s.escape(result.Error)))
+	}
+
+	var out bytes.Buffer
+
+	if result.Metadata != nil && result.Metadata.Root != nil {
+		out.WriteString(renderNode(result.Metadata.Root, s))
+		out.WriteString(" = ")
+	}
+	out.WriteString(s.total(fmt.Sprintf("%d", result.Value)))
+
+	return s.wrap(out.String())
+}
+
+func renderNode(node *object.MetadataNode, s style) string {
+	switch node.Kind {
+	case object.PROGRAM_NODE:
+		statements := []string{}
+		for _, child := range node.Children {
+			statements = append(statements, renderNode(child, s))
+		}
+		return strings.Join(statements, ", ")
+
+	case object.INFIX_NODE:
+		if len(node.Children) != 2 {
+			return ""
+		}
+		left := renderOperand(node, node.Children[0], false, s)
+		right := renderOperand(node, node.Children[1], true, s)
+		return left + " " + s.escape(node.Operator) + " " + right
+
+	case object.PREFIX_NODE:
+		if len(node.Children) != 1 {
+			return ""
+		}
+		return s.escape(node.Operator) + renderOperand(node, node.Children[0], true, s)
+
+	case object.DICE_NODE:
+		return renderDice(node.Data, s)
+
+	case object.INTEGER_NODE:
+		return s.literal(s.escape(node.Data.Literal))
+
+	case object.VARIABLE_NODE:
+		return s.literal(s.escape(node.Data.Literal)) + " (" + s.roll(fmt.Sprintf("%d", node.Value)) + ")"
+	}
+
+	return ""
+}
+
+var precedences = map[string]int{
+	"+": 1,
+	"-": 1,
+	"*": 2,
+	"/": 2,
+	"%": 2,
+	"^": 3,
+}
+
+// renderOperand wraps a child in parentheses when rendering it bare would
+// change the meaning of the expression. All operators are left associative.
+func renderOperand(parent, child *object.MetadataNode, isRight bool, s style) string {
+	out := renderNode(child, s)
+	if child.Kind != object.INFIX_NODE {
+		return out
+	}
+
+	parentPrecedence := precedences[parent.Operator]
+	if parent.Kind == object.PREFIX_NODE {
+		parentPrecedence = len(precedences)
+	}
+	childPrecedence := precedences[child.Operator]
+
+	if childPrecedence < parentPrecedence || (isRight && childPrecedence == parentPrecedence) {
+		return "(" + out + ")"
+	}
+	return out
+}
+
+func renderDice(data *object.DiceData, s style) string {
+	if data == nil {
+		return ""
+	}
+
+	rolls := []string{}
+	for _, roll := range data.FinalRolls() {
+		rolls = append(rolls, renderRoll(roll, data.Size, s))
+	}
+	for _, roll := range data.DroppedRolls() {
+		rolls = append(rolls, s.dropped(fmt.Sprintf("%d", roll)))
+	}
+
+	return s.literal(s.escape(data.Literal)) + " (" + strings.Join(rolls, ", ") + ")"
+}
+
+func renderRoll(roll, size uint32, s style) string {
+	out := fmt.Sprintf("%d", roll)
+	switch {
+	case size > 1 && roll == size:
+		return s.crit(out)
+	case size > 1 && roll == 1:
+		return s.fumble(out)
+	default:
+		return s.roll(out)
+	}
+}
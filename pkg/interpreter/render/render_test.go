@@ -0,0 +1,139 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/evaluator"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/lexer"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/object"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/parser"
+)
+
+func evaluate(input string) *object.RollResult {
+	return evaluateWithSeed(input, 1)
+}
+
+func evaluateWithSeed(input string, seed int64) *object.RollResult {
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	value, metadata := evaluator.EvalWithSeed(program, seed)
+	return object.NewRollResult(input, value, metadata)
+}
+
+func TestParseFormat(t *testing.T) {
+	testCases := []struct {
+		input     string
+		expected  Format
+		expectErr bool
+	}{
+		{"text", TEXT, false},
+		{"ANSI", ANSI, false},
+		{"Markdown", MARKDOWN, false},
+		{"html", HTML, false},
+		{"json", JSON, false},
+		{"yaml", "", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			result, err := ParseFormat(tc.input)
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("expected error=%t, got=%v", tc.expectErr, err)
+			}
+			if result != tc.expected {
+				t.Fatalf("expected=%s, got=%s", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		format   Format
+		expected string
+	}{
+		{"text infix", "d1 + 5", TEXT, "d1 (1) + 5 = 6"},
+		{"text dropped", "d1qu3kh2 * 2", TEXT, "3d1kh2 (1, 1, ~1~) * 2 = 4"},
+		{"text parens", "(d1 + 2) * -(3 - 1)", TEXT, "(d1 (1) + 2) * -(3 - 1) = -6"},
+		{"text right assoc", "10 - (4 - 1)", TEXT, "10 - (4 - 1) = 7"},
+		{"text no parens", "(10 - 4) - 1 + 2 * 3", TEXT, "10 - 4 - 1 + 2 * 3 = 11"},
+		{"text error", "5 + @", TEXT, "(error) illegal token: @"},
+		{"ansi d1", "d1ma1[fire] + 1", ANSI, "\x1b[36md1ma1[fire]\x1b[0m (1) + \x1b[36m1\x1b[0m = \x1b[1m2\x1b[0m"},
+		{"markdown", "d1qu2kl1[a_b]", MARKDOWN, "`2d1kl1\\[a\\_b\\]` (1, ~~1~~) = **1**"},
+		{"html", "d1qu2kh1 + 1", HTML, `<span class="roll"><span class="literal">2d1kh1</span> (<span class="die">1</span>, <span class="die dropped">1</span>) + <span class="literal">1</span> = <span class="total">2</span></span>`},
+		{"html error", "<", HTML, `<span class="roll"><span class="error">illegal token: &lt;</span></span>`},
+		{"json", "2", JSON, `{"version":1,"literal":"2","value":2,"metadata":{"version":1,"seed":1,"root":{"kind":"PROGRAM","operator":"","start":0,"end":1,"value":2,"children":[{"kind":"INTEGER","operator":"","start":0,"end":1,"value":2,"data":{"literal":"2","tags":[],"size":0,"dice":[],"raw_rolls":[],"final_rolls":[],"dropped_rolls":[],"value":2},"children":[]}]}}}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := Render(evaluate(tc.input), tc.format)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tc.expected {
+				t.Fatalf("expected=%q, got=%q", tc.expected, result)
+			}
+		})
+	}
+}
+
+// seeds 103 and 11 roll a 20 and a 1 on a d20
+func TestRenderSeededCritsAndFumbles(t *testing.T) {
+	testCases := []struct {
+		name     string
+		seed     int64
+		expected string
+	}{
+		{"crit", 103, "\x1b[36md20\x1b[0m (\x1b[1;32m20\x1b[0m) + \x1b[36m1\x1b[0m = \x1b[1m21\x1b[0m"},
+		{"fumble", 11, "\x1b[36md20\x1b[0m (\x1b[1;31m1\x1b[0m) + \x1b[36m1\x1b[0m = \x1b[1m2\x1b[0m"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := Render(evaluateWithSeed("d20 + 1", tc.seed), ANSI)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tc.expected {
+				t.Fatalf("expected=%q, got=%q", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestRenderHighlightsCritsAndFumbles(t *testing.T) {
+	result := &object.RollResult{
+		Literal: "2d20",
+		Value:   21,
+		Metadata: &object.Metadata{Root: &object.MetadataNode{
+			Kind: object.DICE_NODE,
+			Data: &object.DiceData{Literal: "2d20", Size: 20, Dice: []object.Die{{Index: 0, Roll: 20, Value: 20}, {Index: 1, Roll: 1, Value: 1}}, Value: 21},
+		}},
+	}
+
+	testCases := []struct {
+		format   Format
+		expected string
+	}{
+		{TEXT, "2d20 (20, 1) = 21"},
+		{ANSI, "\x1b[36m2d20\x1b[0m (\x1b[1;32m20\x1b[0m, \x1b[1;31m1\x1b[0m) = \x1b[1m21\x1b[0m"},
+		{MARKDOWN, "`2d20` (**20**, _1_) = **21**"},
+		{HTML, `<span class="roll"><span class="literal">2d20</span> (<span class="die crit">20</span>, <span class="die fumble">1</span>) = <span class="total">21</span></span>`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.format), func(t *testing.T) {
+			rendered, err := Render(result, tc.format)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if rendered != tc.expected {
+				t.Fatalf("expected=%q, got=%q", tc.expected, rendered)
+			}
+		})
+	}
+}
@@ -0,0 +1,351 @@
+package repl
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/analyzer"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/ast"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/object"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/render"
+)
+
+const (
+	// distTrials is how many times :dist rolls a dice string
+	distTrials = 10000
+	// distMaxDice bounds the dice :dist rolls across every trial, so fewer
+	// trials are rolled for dice strings rolling many dice
+	distMaxDice = 1000000
+	// distMaxRows is how many rows the distribution is grouped into at most
+	distMaxRows = 30
+	// maxLoadDepth stops files that :load each other
+	maxLoadDepth = 8
+)
+
+type metaCommand struct {
+	usage   string
+	summary string
+	run     func(r *REPL, arg string) error
+}
+
+var metaCommands map[string]metaCommand
+
+func init() {
+	// assigned in init, since :help refers to the map
+	metaCommands = map[string]metaCommand{
+		"help":    {":help", "list the meta-commands", (*REPL).help},
+		"quit":    {":quit", "leave the REPL, as does Ctrl-D", (*REPL).quit},
+		"explain": {":explain [dice string]", "show how a dice string is parsed. defaults to the last one rolled", (*REPL).explain},
+		"dist":    {":dist [dice string]", "show the chance of each value of a dice string. defaults to the last one rolled", (*REPL).dist},
+		"seed":    {":seed [n|off]", "roll from a fixed seed, so the same rolls are made every time. off rolls randomly again", (*REPL).setSeed},
+		"verbose": {":verbose [on|off]", "show every die rolled along with the result", (*REPL).setVerbose},
+		"load":    {":load <file>", "roll every line of a file, as if it were typed in", (*REPL).load},
+		"trace":   {":trace [dice string]", "roll a dice string and show every step to its value. defaults to the last one rolled", (*REPL).trace},
+	}
+}
+
+// errQuit ends the session
+var errQuit = errors.New("quit")
+
+// meta runs a line starting with ':'
+func (r *REPL) meta(line string) error {
+	name, arg, _ := strings.Cut(strings.TrimPrefix(line, ":"), " ")
+	arg = strings.TrimSpace(arg)
+	if name == "q" {
+		name = "quit"
+	}
+
+	cmd, ok := metaCommands[name]
+	if !ok {
+		return fmt.Errorf("unknown command :%s. try :help", name)
+	}
+	return cmd.run(r, arg)
+}
+
+func (r *REPL) help(string) error {
+	names := make([]string, 0, len(metaCommands))
+	for name := range metaCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(r.out, "  %-24s %s\n", metaCommands[name].usage, metaCommands[name].summary)
+	}
+	return nil
+}
+
+func (r *REPL) quit(string) error {
+	return errQuit
+}
+
+func (r *REPL) explain(arg string) error {
+	input, err := r.inputOrLast(arg)
+	if err != nil {
+		return err
+	}
+
+	program, parseErrors := Parse(input)
+	for _, msg := range parseErrors {
+		fmt.Fprintf(r.out, "(parse error) %s\n", msg)
+	}
+	fmt.Fprintf(r.out, "parsed as: %s\n", program.String())
+	for _, statement := range program.Statements {
+		if statement, ok := statement.(*ast.ExpressionStatement); ok {
+			writeNode(r.out, statement.Expression, "")
+		}
+	}
+	return nil
+}
+
+func writeNode(out io.Writer, node ast.Expression, indent string) {
+	switch node := node.(type) {
+	case *ast.InfixExpression:
+		fmt.Fprintf(out, "%sINFIX %s\n", indent, node.Operator)
+		writeNode(out, node.Left, indent+"  ")
+		writeNode(out, node.Right, indent+"  ")
+	case *ast.PrefixExpression:
+		fmt.Fprintf(out, "%sPREFIX %s\n", indent, node.Operator)
+		writeNode(out, node.Right, indent+"  ")
+	case *ast.DiceLiteral:
+		fmt.Fprintf(out, "%sDICE %s: %s\n", indent, node.String(), describeDice(node))
+	case *ast.IntegerLiteral:
+		fmt.Fprintf(out, "%sINTEGER %d%s\n", indent, node.Value, describeTags(node.Tags))
+	case *ast.IllegalLiteral:
+		fmt.Fprintf(out, "%sILLEGAL %q\n", indent, node.Literal)
+	case *ast.Identifier:
+		fmt.Fprintf(out, "%sIDENTIFIER %s\n", indent, node.Value)
+	case nil:
+		fmt.Fprintf(out, "%sMISSING\n", indent)
+	default:
+		fmt.Fprintf(out, "%s%T %s\n", indent, node, node.String())
+	}
+}
+
+func describeDice(dice *ast.DiceLiteral) string {
+	parts := []string{fmt.Sprintf("roll %d d%d", max(dice.Quantity, 1), dice.Size)}
+	if dice.MinValue > 0 {
+		parts = append(parts, fmt.Sprintf("raise rolls below %d", dice.MinValue))
+	}
+	if dice.MaxValue > 0 {
+		parts = append(parts, fmt.Sprintf("lower rolls above %d", dice.MaxValue))
+	}
+	if dice.KeepHighest > 0 {
+		parts = append(parts, fmt.Sprintf("keep the highest %d", dice.KeepHighest))
+	}
+	if dice.KeepLowest > 0 {
+		parts = append(parts, fmt.Sprintf("keep the lowest %d", dice.KeepLowest))
+	}
+	return strings.Join(parts, ", ") + describeTags(dice.Tags)
+}
+
+func describeTags(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(tags, "] [") + "]"
+}
+
+// trace rolls a dice string, then prints every roll, modifier and operation
+// that led to its value, nested like the dice string
+func (r *REPL) trace(arg string) error {
+	input, err := r.inputOrLast(arg)
+	if err != nil {
+		return err
+	}
+
+	program, parseErrors := Parse(input)
+	if len(parseErrors) > 0 {
+		return fmt.Errorf("could not parse %q: %s", input, strings.Join(parseErrors, ", "))
+	}
+
+	md := object.NewSeededMetadata(r.nextSeed())
+	md.Trace = true
+	result := object.NewRollResult(input, r.eval(program, md), md)
+	rendered, err := render.Render(result, r.format)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(r.out, rendered)
+	for _, step := range md.Steps {
+		fmt.Fprintf(r.out, "  %s%s\n", strings.Repeat("  ", step.Depth), step.Text)
+	}
+	return nil
+}
+
+// dist prints the chance of each value of a dice string. It is worked out
+// exactly when the analyzer can, and estimated by rolling the dice string
+// many times when it has too many values or outcomes.
+func (r *REPL) dist(arg string) error {
+	input, err := r.inputOrLast(arg)
+	if err != nil {
+		return err
+	}
+
+	program, parseErrors := Parse(input)
+	if len(parseErrors) > 0 {
+		return fmt.Errorf("could not parse %q: %s", input, strings.Join(parseErrors, ", "))
+	}
+
+	// errors are left to rolling, which reports them the same way
+	if dist, err := analyzer.Distribution(program, 0, 0); err == nil && dist != nil {
+		r.printDistribution(input, dist)
+		return nil
+	}
+
+	// rolled until distTrials rolls are made, or distMaxDice dice are
+	source := rand.New(rand.NewSource(r.nextSeed()))
+	counts := map[int64]int{}
+	rolls, rolled := 0, 0
+	for rolls < distTrials && rolled < distMaxDice {
+		md := object.NewSeededMetadata(source.Int63())
+		md.MaxDice = distMaxDice
+		value := r.eval(program, md)
+		integer, ok := value.(*object.Integer)
+		if !ok {
+			return fmt.Errorf("could not roll %q: %s", input, value.Inspect())
+		}
+		counts[integer.Value]++
+		rolls++
+		for _, data := range md.Dice() {
+			rolled += len(data.RawRolls())
+		}
+	}
+
+	dist := make(map[int64]float64, len(counts))
+	for value, count := range counts {
+		dist[value] = float64(count) / float64(rolls)
+	}
+	r.printDistribution(fmt.Sprintf("%s, estimated from %d rolls", input, rolls), dist)
+	return nil
+}
+
+// printDistribution prints the chance of each value, after a line with the
+// title and the bounds of the values
+func (r *REPL) printDistribution(title string, dist map[int64]float64) {
+	values := make([]int64, 0, len(dist))
+	for value := range dist {
+		values = append(values, value)
+	}
+	slices.Sort(values)
+	// summed in order, so the mean doesn't change with the map's order
+	mean := 0.0
+	for _, value := range values {
+		mean += float64(value) * dist[value]
+	}
+	lowest, highest := values[0], values[len(values)-1]
+
+	fmt.Fprintf(r.out, "%s: min %d, max %d, mean %.2f\n", title, lowest, highest, mean)
+
+	// values are grouped into ranges when there are too many to list
+	width := (highest-lowest)/distMaxRows + 1
+	rows := []float64{}
+	for _, value := range values {
+		row := int((value - lowest) / width)
+		for len(rows) <= row {
+			rows = append(rows, 0)
+		}
+		rows[row] += dist[value]
+	}
+	mostCommon := slices.Max(rows)
+	for i, p := range rows {
+		from := lowest + int64(i)*width
+		label := strconv.FormatInt(from, 10)
+		if to := min(from+width-1, highest); to > from {
+			label += ".." + strconv.FormatInt(to, 10)
+		}
+		// the chances are summed in any order, so they are a little off
+		bar := strings.Repeat("#", int(p*40/mostCommon+1e-9))
+		fmt.Fprintln(r.out, strings.TrimRight(fmt.Sprintf("%12s %6.2f%% %s", label, 100*p, bar), " "))
+	}
+}
+
+func (r *REPL) setSeed(arg string) error {
+	switch arg {
+	case "":
+		if r.rand == nil {
+			fmt.Fprintln(r.out, "rolling randomly")
+		} else {
+			fmt.Fprintf(r.out, "rolling from seed %d\n", r.seed)
+		}
+		return nil
+	case "off":
+		r.rand = nil
+		fmt.Fprintln(r.out, "rolling randomly")
+		return nil
+	}
+
+	seed, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		return fmt.Errorf("expected a seed or off, got=%q", arg)
+	}
+	r.seed = seed
+	r.rand = rand.New(rand.NewSource(seed))
+	fmt.Fprintf(r.out, "rolling from seed %d\n", seed)
+	return nil
+}
+
+func (r *REPL) setVerbose(arg string) error {
+	switch arg {
+	case "":
+		r.verbose = !r.verbose
+	case "on":
+		r.verbose = true
+	case "off":
+		r.verbose = false
+	default:
+		return fmt.Errorf("expected on or off, got=%q", arg)
+	}
+
+	if r.verbose {
+		fmt.Fprintln(r.out, "verbose on")
+	} else {
+		fmt.Fprintln(r.out, "verbose off")
+	}
+	return nil
+}
+
+// load runs every line of a file, echoing them as if they were typed in
+func (r *REPL) load(arg string) error {
+	if arg == "" {
+		return fmt.Errorf("expected a file to load")
+	}
+	if r.loadDepth >= maxLoadDepth {
+		return fmt.Errorf("could not load %s: files are loaded more than %d deep", arg, maxLoadDepth)
+	}
+
+	file, err := os.Open(filepath.Clean(arg))
+	if err != nil {
+		return fmt.Errorf("could not load %s: %v", arg, err)
+	}
+	defer file.Close()
+
+	r.loadDepth++
+	defer func() { r.loadDepth-- }()
+	// the file's lines are told apart from typed ones by their prompt
+	reader := newPlainReader(file, r.out, true)
+	reader.prefix = filepath.Base(arg)
+	err = r.loop(reader)
+	if errors.Is(err, errQuit) {
+		// :quit only stops the file
+		return nil
+	}
+	return err
+}
+
+func (r *REPL) inputOrLast(arg string) (string, error) {
+	if arg != "" {
+		return arg, nil
+	}
+	if r.last == "" {
+		return "", fmt.Errorf("expected a dice string, nothing has been rolled yet")
+	}
+	return r.last, nil
+}
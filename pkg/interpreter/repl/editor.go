@@ -0,0 +1,242 @@
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// maxHistory is how many lines are kept in the history file
+const maxHistory = 1000
+
+// lineReader reads one line of input at a time. io.EOF ends the session.
+type lineReader interface {
+	ReadLine(prompt string) (string, error)
+	Close() error
+}
+
+// plainReader reads lines without editing, when the input isn't a terminal.
+// The prompt is only written once a line is read, followed by the line when
+// echo is set, so the output reads like a session typed into a terminal.
+// prefix is written before every prompt.
+type plainReader struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+	echo    bool
+	prefix  string
+}
+
+func newPlainReader(in io.Reader, out io.Writer, echo bool) *plainReader {
+	return &plainReader{scanner: bufio.NewScanner(in), out: out, echo: echo}
+}
+
+func (r *plainReader) ReadLine(prompt string) (string, error) {
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	fmt.Fprint(r.out, r.prefix+prompt)
+	if r.echo {
+		fmt.Fprintln(r.out, r.scanner.Text())
+	}
+	return r.scanner.Text(), nil
+}
+
+func (r *plainReader) Close() error { return nil }
+
+// editor reads lines from a terminal in raw mode, with emacs style editing
+// and a history browsed with the arrow keys. The history is loaded from and
+// saved to historyPath, if set.
+type editor struct {
+	fd          int
+	in          *bufio.Reader
+	out         io.Writer
+	history     []string
+	historyPath string
+}
+
+func newEditor(in *os.File, out io.Writer, historyPath string) *editor {
+	return &editor{
+		fd:          int(in.Fd()),
+		in:          bufio.NewReader(in),
+		out:         out,
+		history:     loadHistory(historyPath),
+		historyPath: historyPath,
+	}
+}
+
+// ReadLine reads a line, returning io.EOF when Ctrl-D is pressed on an empty
+// line.
+func (e *editor) ReadLine(prompt string) (string, error) {
+	state, err := term.MakeRaw(e.fd)
+	if err != nil {
+		return "", err
+	}
+	defer term.Restore(e.fd, state)
+
+	line := []rune{}
+	pos := 0
+	// browsing the history edits a copy, so the original lines are kept
+	entries := append(append([]string{}, e.history...), "")
+	current := len(entries) - 1
+
+	redraw := func() {
+		fmt.Fprintf(e.out, "\r%s%s\x1b[K", prompt, string(line))
+		if back := len(line) - pos; back > 0 {
+			fmt.Fprintf(e.out, "\x1b[%dD", back)
+		}
+	}
+	recall := func(i int) {
+		entries[current] = string(line)
+		current = i
+		line = []rune(entries[current])
+		pos = len(line)
+	}
+
+	redraw()
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+
+		switch r {
+		case '\r', '\n':
+			fmt.Fprint(e.out, "\r\n")
+			e.remember(string(line))
+			return string(line), nil
+		case 4: // Ctrl-D
+			if len(line) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+			if pos < len(line) {
+				line = append(line[:pos], line[pos+1:]...)
+			}
+		case 3: // Ctrl-C
+			fmt.Fprint(e.out, "^C\r\n")
+			line, pos = []rune{}, 0
+			current = len(entries) - 1
+		case 127, 8: // Backspace
+			if pos > 0 {
+				line = append(line[:pos-1], line[pos:]...)
+				pos--
+			}
+		case 1: // Ctrl-A
+			pos = 0
+		case 5: // Ctrl-E
+			pos = len(line)
+		case 11: // Ctrl-K
+			line = line[:pos]
+		case 21: // Ctrl-U
+			line, pos = line[pos:], 0
+		case 27: // an escape sequence, ex: the arrow keys
+			switch e.readEscape() {
+			case "[A", "OA": // up
+				if current > 0 {
+					recall(current - 1)
+				}
+			case "[B", "OB": // down
+				if current < len(entries)-1 {
+					recall(current + 1)
+				}
+			case "[C", "OC": // right
+				pos = min(pos+1, len(line))
+			case "[D", "OD": // left
+				pos = max(pos-1, 0)
+			case "[H", "OH", "[1~":
+				pos = 0
+			case "[F", "OF", "[4~":
+				pos = len(line)
+			case "[3~": // delete
+				if pos < len(line) {
+					line = append(line[:pos], line[pos+1:]...)
+				}
+			}
+		default:
+			if r < ' ' || r == utf8.RuneError {
+				continue
+			}
+			line = append(line[:pos], append([]rune{r}, line[pos:]...)...)
+			pos++
+		}
+		redraw()
+	}
+}
+
+// readEscape reads the rest of an escape sequence, ex: "[A" for the up arrow
+func (e *editor) readEscape() string {
+	var seq strings.Builder
+	for {
+		b, err := e.in.ReadByte()
+		if err != nil {
+			return seq.String()
+		}
+		seq.WriteByte(b)
+		// sequences end with a letter or ~, except for their first byte
+		if seq.Len() > 1 && (b >= 'A' && b <= 'Z' || b >= 'a' && b <= 'z' || b == '~') {
+			return seq.String()
+		}
+	}
+}
+
+func (e *editor) remember(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" || (len(e.history) > 0 && e.history[len(e.history)-1] == line) {
+		return
+	}
+	e.history = append(e.history, line)
+	if len(e.history) > maxHistory {
+		e.history = e.history[len(e.history)-maxHistory:]
+	}
+}
+
+// Close saves the history
+func (e *editor) Close() error {
+	if e.historyPath == "" {
+		return nil
+	}
+	return os.WriteFile(e.historyPath, []byte(strings.Join(e.history, "\n")+"\n"), 0o600)
+}
+
+func loadHistory(path string) []string {
+	if path == "" {
+		return []string{}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []string{}
+	}
+	history := []string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			history = append(history, line)
+		}
+	}
+	if len(history) > maxHistory {
+		history = history[len(history)-maxHistory:]
+	}
+	return history
+}
+
+// historyPath is where the history is kept between sessions,
+// $CALCUROLLER_HISTORY or ~/.calcuroller_history. It is empty if there is
+// nowhere to keep it.
+func historyPath() string {
+	if path, ok := os.LookupEnv("CALCUROLLER_HISTORY"); ok {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".calcuroller_history")
+}
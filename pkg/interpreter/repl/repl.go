@@ -1,49 +1,256 @@
 package repl
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
+	"io"
+	"math/rand"
 	"os"
+	"strings"
 
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/ast"
 	"github.com/daneofmanythings/calcuroller/pkg/interpreter/evaluator"
 	"github.com/daneofmanythings/calcuroller/pkg/interpreter/lexer"
 	"github.com/daneofmanythings/calcuroller/pkg/interpreter/object"
 	"github.com/daneofmanythings/calcuroller/pkg/interpreter/parser"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/render"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/token"
+	"golang.org/x/term"
 )
 
-func run(input string) (object.Object, *object.Metadata) {
+// RunWithMetadata evaluates input, rolling the dice from md and recording
+// them in it.
+func RunWithMetadata(input string, md *object.Metadata) object.Object {
+	program, _ := Parse(input)
+	return evaluator.Eval(program, md)
+}
+
+// Parse parses input. The program is returned even if there are parse
+// errors, since evaluating it reports them in context.
+func Parse(input string) (*ast.Program, []string) {
 	l := lexer.New(input)
 	p := parser.New(l)
 	program := p.ParseProgram()
 
-	value, metadata := evaluator.EvalFromRequest(program)
+	return program, p.Errors()
+}
 
-	return value, metadata
+// Format rewrites a dice string into its canonical form, see ast.Format. Dice
+// strings that are written differently but roll the same are formatted the
+// same, so they can be compared.
+func Format(input string) (string, error) {
+	program, parseErrors := Parse(input)
+	if len(parseErrors) > 0 {
+		return "", fmt.Errorf("could not parse %q: %s", input, strings.Join(parseErrors, ", "))
+	}
+	if len(program.Statements) == 0 {
+		return "", fmt.Errorf("expected a dice string")
+	}
+	return ast.Format(program)
 }
 
-func RunFromTerminal() {
+// RunFromTerminal reads dice strings and meta-commands from stdin until
+// Ctrl-D.
+func RunFromTerminal(format render.Format) {
 	fmt.Println("Welcome to the calcuroller REPL!")
-	fmt.Print("(enter dice strings, ex: d20 + 4)\n\n")
-	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("(enter dice strings, ex: d20 + 4. :help lists the meta-commands)\n\n")
+
+	r := New(os.Stdin, os.Stdout, format)
+	r.SetHistoryPath(historyPath())
+	if err := r.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "...%v\n", err)
+	}
+}
+
+// Evaluator rolls a parsed dice string, recording the dice in md.
+type Evaluator func(node ast.Node, md *object.Metadata) object.Object
+
+// REPL reads dice strings and meta-commands from in and writes their results
+// to out. Meta-commands change how the following dice strings are rolled.
+type REPL struct {
+	in          io.Reader
+	out         io.Writer
+	eval        Evaluator
+	historyPath string
+	format      render.Format
+	verbose     bool
+	seed        int64
+	rand        *rand.Rand // rolls are seeded from it after :seed, and randomly when nil
+	last        string     // the last dice string rolled
+	loadDepth   int
+}
+
+// New creates a REPL rolling dice strings with evaluator.Eval. When in is a
+// terminal, lines can be edited and earlier lines recalled.
+func New(in io.Reader, out io.Writer, format render.Format) *REPL {
+	return &REPL{
+		in:     in,
+		out:    out,
+		eval:   evaluator.Eval,
+		format: format,
+	}
+}
+
+// SetEvaluator replaces how dice strings are rolled. :dist only rolls the
+// dice strings it can't work out exactly.
+func (r *REPL) SetEvaluator(eval Evaluator) {
+	r.eval = eval
+}
+
+// SetSeed rolls every following dice string from seed, as :seed does.
+func (r *REPL) SetSeed(seed int64) {
+	r.seed = seed
+	r.rand = rand.New(rand.NewSource(seed))
+}
+
+// SetHistoryPath keeps the lines typed into a terminal in path, between
+// sessions.
+func (r *REPL) SetHistoryPath(path string) {
+	r.historyPath = path
+}
+
+// Run reads input until it ends or :quit is run.
+func (r *REPL) Run() (err error) {
+	var reader lineReader = newPlainReader(r.in, r.out, true)
+	if file, ok := r.in.(*os.File); ok && term.IsTerminal(int(file.Fd())) {
+		reader = newEditor(file, r.out, r.historyPath)
+	}
+	defer func() {
+		if closeErr := reader.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("could not save the history: %w", closeErr)
+		}
+	}()
 
+	err = r.loop(reader)
+	if err != nil && !errors.Is(err, errQuit) {
+		return fmt.Errorf("could not read input: %w", err)
+	}
+	return nil
+}
+
+// loop runs every input read until EOF
+func (r *REPL) loop(reader lineReader) error {
 	for {
-		fmt.Print(">> ")
-		input, err := reader.ReadString('\n')
-		if err == nil {
-			val, _ := run(input)
-			integer, ok := val.(*object.Integer)
-			if !ok {
-				fmt.Printf("(error) %s\n\n", val.(*object.Error).Message)
-			} else {
-				fmt.Printf("%d\n\n", integer.Value)
+		input, err := readInput(reader)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if input == "" {
+			continue
+		}
+
+		if strings.HasPrefix(input, ":") {
+			if err := r.meta(input); err != nil {
+				if errors.Is(err, errQuit) {
+					return err
+				}
+				fmt.Fprintf(r.out, "(error) %s\n", err)
 			}
 		} else {
-			fmt.Printf("\nan error occurred reading input. err=%s", err)
+			r.roll(input)
 		}
+		fmt.Fprintln(r.out)
 	}
 }
 
-func RunFromGRPC(input string) (object.Object, *object.Metadata) {
-	value, metadata := run(input)
-	return value, metadata
+// readInput reads a line, and the lines continuing it while the dice string
+// is incomplete, ex: it ends in an operator or has unclosed parentheses.
+func readInput(reader lineReader) (string, error) {
+	line, err := reader.ReadLine(">> ")
+	if err != nil {
+		return "", err
+	}
+	input := strings.TrimSpace(line)
+	switch {
+	case strings.HasPrefix(input, "#"):
+		return "", nil // a comment, in files given to :load
+	case strings.HasPrefix(input, ":"):
+		return input, nil
+	}
+
+	for incomplete(input) {
+		line, err := reader.ReadLine(".. ")
+		if err == io.EOF {
+			break // the parse errors are reported
+		}
+		if err != nil {
+			return "", err
+		}
+		input = strings.TrimSuffix(input, "\\") + " " + strings.TrimSpace(line)
+	}
+	return strings.TrimSpace(strings.TrimSuffix(input, "\\")), nil
+}
+
+// incomplete reports whether more lines are needed to finish the dice
+// string: it ends with a backslash or an operator, or a parenthesis is left
+// open.
+func incomplete(input string) bool {
+	if strings.HasSuffix(input, "\\") {
+		return true
+	}
+
+	depth := 0
+	var last token.Token
+	l := lexer.New(input)
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		switch tok.Type {
+		case token.LPAREN:
+			depth++
+		case token.RPAREN:
+			depth--
+		}
+		last = tok
+	}
+
+	switch last.Type {
+	case token.PLUS, token.MINUS, token.ASTERISK, token.SLASH, token.MODULUS, token.CARET:
+		return true
+	}
+	return depth > 0
+}
+
+func (r *REPL) roll(input string) {
+	r.last = input
+
+	program, parseErrors := Parse(input)
+	if len(parseErrors) > 0 {
+		for _, msg := range parseErrors {
+			fmt.Fprintf(r.out, "(parse error) %s\n", msg)
+		}
+		return
+	}
+
+	md := object.NewSeededMetadata(r.nextSeed())
+	val := r.eval(program, md)
+	rendered, err := render.Render(object.NewRollResult(input, val, md), r.format)
+	if err != nil {
+		fmt.Fprintf(r.out, "(error) %s\n", err)
+		return
+	}
+	fmt.Fprintln(r.out, rendered)
+
+	if r.verbose {
+		fmt.Fprintf(r.out, "seed %d\n", md.Seed)
+		for _, data := range md.Dice() {
+			if data.Size == 0 {
+				continue // integer literals
+			}
+			fmt.Fprintf(r.out, "  %s: rolled %v, kept %v", data.Literal, data.RawRolls(), data.FinalRolls())
+			if len(data.DroppedRolls()) > 0 {
+				fmt.Fprintf(r.out, ", dropped %v", data.DroppedRolls())
+			}
+			fmt.Fprintf(r.out, " = %d\n", data.Value)
+		}
+	}
+}
+
+// nextSeed is the seed of the next roll
+func (r *REPL) nextSeed() int64 {
+	if r.rand == nil {
+		return rand.Int63()
+	}
+	return r.rand.Int63()
 }
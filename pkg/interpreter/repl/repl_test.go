@@ -0,0 +1,134 @@
+package repl
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/ast"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/object"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/render"
+)
+
+var update = flag.Bool("update", false, "rewrite the transcripts in testdata with the REPL's output")
+
+// transcriptSeed is what every transcript is rolled from, unless it uses
+// :seed
+const transcriptSeed = 1
+
+// TestTranscripts replays every session in testdata/*.txt. The lines typed in
+// are the ones starting with the prompts, and everything else is the output
+// expected. After a change to the output, `go test -update` rewrites them.
+func TestTranscripts(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "*.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range paths {
+		t.Run(strings.TrimSuffix(filepath.Base(path), ".txt"), func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := string(data)
+
+			out := &bytes.Buffer{}
+			r := New(strings.NewReader(typedLines(expected)), out, render.TEXT)
+			r.SetSeed(transcriptSeed)
+			if err := r.Run(); err != nil {
+				t.Fatalf("could not run: %v", err)
+			}
+
+			if *update {
+				if err := os.WriteFile(path, out.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if out.String() != expected {
+				t.Fatalf("transcript differs:\n%s", lineDiff(expected, out.String()))
+			}
+		})
+	}
+}
+
+// typedLines are the lines of a transcript following a prompt
+func typedLines(transcript string) string {
+	var in strings.Builder
+	for _, line := range strings.Split(transcript, "\n") {
+		for _, prompt := range []string{">> ", ".. "} {
+			if typed, ok := strings.CutPrefix(line, prompt); ok {
+				in.WriteString(typed + "\n")
+			}
+		}
+	}
+	return in.String()
+}
+
+func lineDiff(expected, got string) string {
+	expectedLines, gotLines := strings.Split(expected, "\n"), strings.Split(got, "\n")
+	for i := 0; i < max(len(expectedLines), len(gotLines)); i++ {
+		var e, g string
+		if i < len(expectedLines) {
+			e = expectedLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if e != g {
+			return fmt.Sprintf("line %d:\nexpected=%q\ngot=%q", i+1, e, g)
+		}
+	}
+	return ""
+}
+
+func TestSetEvaluator(t *testing.T) {
+	inputs := []string{}
+	out := &bytes.Buffer{}
+	r := New(strings.NewReader("d20\n:dist d1000qu3kh1\n"), out, render.TEXT)
+	r.SetEvaluator(func(node ast.Node, md *object.Metadata) object.Object {
+		inputs = append(inputs, node.String())
+		return &object.Integer{Value: 42}
+	})
+	if err := r.Run(); err != nil {
+		t.Fatalf("could not run: %v", err)
+	}
+
+	if inputs[0] != "d20" {
+		t.Fatalf("expected=d20, got=%s", inputs[0])
+	}
+	if !strings.Contains(out.String(), ">> d20\n42\n") || !strings.Contains(out.String(), "min 42, max 42") {
+		t.Fatalf("expected every roll to be 42, got=%q", out.String())
+	}
+}
+
+func TestIncomplete(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected bool
+	}{
+		{"d20 + 4", false},
+		{"d20 +", true},
+		{"d20 *", true},
+		{"(d20 + 4", true},
+		{"((d20 + 4)", true},
+		{"(d20 + 4)", false},
+		{"d20 \\", true},
+		{"", false},
+		{"-", true},
+		{"(d20 + 4))", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			if got := incomplete(tc.input); got != tc.expected {
+				t.Fatalf("expected=%t, got=%t", tc.expected, got)
+			}
+		})
+	}
+}
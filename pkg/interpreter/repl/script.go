@@ -0,0 +1,263 @@
+package repl
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/evaluator"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/lexer"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/object"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/render"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/token"
+)
+
+// ScriptError is the first statement of a script that could not be run
+type ScriptError struct {
+	Name    string
+	Line    int
+	Message string
+}
+
+func (e *ScriptError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.Name, e.Line, e.Message)
+}
+
+// Script runs dice scripts: a statement per line, which is one of
+//
+//	let name = <dice string>   roll the dice string and keep its value in name
+//	print <item>, ...          print quoted text and the values of dice strings
+//	<dice string>              roll the dice string
+//
+// Dice strings may use the variables set before them. A statement continues
+// on the next line like it does in the REPL, and comments start with # or //.
+type Script struct {
+	out    io.Writer
+	eval   Evaluator
+	format render.Format
+	rand   *rand.Rand // dice strings are rolled randomly when nil
+}
+
+func NewScript(out io.Writer, format render.Format) *Script {
+	return &Script{out: out, eval: evaluator.Eval, format: format}
+}
+
+// SetEvaluator replaces how dice strings are rolled.
+func (s *Script) SetEvaluator(eval Evaluator) {
+	s.eval = eval
+}
+
+// SetSeed rolls the dice strings of every script run after from seed.
+func (s *Script) SetSeed(seed int64) {
+	s.rand = rand.New(rand.NewSource(seed))
+}
+
+// statementJSON is how a statement's output is encoded in the JSON format,
+// one object per line
+type statementJSON struct {
+	Line   int             `json:"line"`
+	Kind   string          `json:"kind"`
+	Name   string          `json:"name,omitempty"`
+	Text   string          `json:"text,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+}
+
+// Run runs the script read from src until a statement fails, which is
+// returned as a *ScriptError. name is the script's name in errors.
+func (s *Script) Run(name string, src io.Reader) error {
+	variables := map[string]int64{}
+	scanner := bufio.NewScanner(src)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		line := lineNumber
+		statement := stripComment(scanner.Text())
+		for incomplete(statement) && scanner.Scan() {
+			lineNumber++
+			statement = strings.TrimSuffix(statement, "\\") + " " + stripComment(scanner.Text())
+		}
+		statement = strings.TrimSpace(strings.TrimSuffix(statement, "\\"))
+		if statement == "" {
+			continue
+		}
+
+		if err := s.runStatement(statement, line, variables); err != nil {
+			return &ScriptError{Name: name, Line: line, Message: err.Error()}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return &ScriptError{Name: name, Line: lineNumber, Message: err.Error()}
+	}
+	return nil
+}
+
+func (s *Script) runStatement(statement string, line int, variables map[string]int64) error {
+	keyword, rest, _ := strings.Cut(statement, " ")
+	switch keyword {
+	case "let":
+		name, input, ok := strings.Cut(rest, "=")
+		if !ok {
+			return fmt.Errorf("expected let <name> = <dice string>, got=%q", statement)
+		}
+		name, input = strings.TrimSpace(name), strings.TrimSpace(input)
+		if !isVariableName(name) {
+			return fmt.Errorf("%q can't be a variable name: use letters and underscores, and not a dice modifier", name)
+		}
+		result, err := s.roll(input, variables)
+		if err != nil {
+			return err
+		}
+		variables[name] = result.Value
+		return s.write(statementJSON{Line: line, Kind: "let", Name: name}, result)
+
+	case "print":
+		items, err := splitItems(rest)
+		if err != nil {
+			return err
+		}
+		text := []string{}
+		for _, item := range items {
+			if unquoted, err := strconv.Unquote(item); err == nil {
+				text = append(text, unquoted)
+				continue
+			}
+			result, err := s.roll(item, variables)
+			if err != nil {
+				return err
+			}
+			text = append(text, strconv.FormatInt(result.Value, 10))
+		}
+		return s.write(statementJSON{Line: line, Kind: "print", Text: strings.Join(text, " ")}, nil)
+
+	default:
+		result, err := s.roll(statement, variables)
+		if err != nil {
+			return err
+		}
+		return s.write(statementJSON{Line: line, Kind: "roll"}, result)
+	}
+}
+
+func (s *Script) roll(input string, variables map[string]int64) (*object.RollResult, error) {
+	program, parseErrors := Parse(input)
+	if len(parseErrors) > 0 {
+		return nil, fmt.Errorf("could not parse %q: %s", input, strings.Join(parseErrors, ", "))
+	}
+
+	seed := rand.Int63()
+	if s.rand != nil {
+		seed = s.rand.Int63()
+	}
+	md := object.NewSeededMetadata(seed)
+	md.Variables = variables
+
+	result := object.NewRollResult(input, s.eval(program, md), md)
+	if result.Error != "" {
+		return nil, fmt.Errorf("could not roll %q: %s", input, result.Error)
+	}
+	return result, nil
+}
+
+// write prints a statement's output: a line of text, or an object per line in
+// the JSON format
+func (s *Script) write(statement statementJSON, result *object.RollResult) error {
+	if s.format == render.JSON {
+		if result != nil {
+			rendered, err := render.Render(result, render.JSON)
+			if err != nil {
+				return err
+			}
+			statement.Result = json.RawMessage(rendered)
+		}
+		out, err := json.Marshal(statement)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(s.out, string(out))
+		return err
+	}
+
+	text := statement.Text
+	if result != nil {
+		rendered, err := render.Render(result, s.format)
+		if err != nil {
+			return err
+		}
+		text = rendered
+		if statement.Name != "" {
+			text = statement.Name + ": " + rendered
+		}
+	}
+	_, err := fmt.Fprintln(s.out, text)
+	return err
+}
+
+// stripComment removes a # or // comment, unless it is inside a tag or
+// quoted text
+func stripComment(line string) string {
+	inTag, inQuote := false, false
+	for i := 0; i < len(line); i++ {
+		switch {
+		case inQuote && line[i] == '\\':
+			i++
+		case line[i] == '"' && !inTag:
+			inQuote = !inQuote
+		case inQuote:
+		case line[i] == '[':
+			inTag = true
+		case line[i] == ']':
+			inTag = false
+		case inTag:
+		case line[i] == '#', strings.HasPrefix(line[i:], "//"):
+			return strings.TrimSpace(line[:i])
+		}
+	}
+	return strings.TrimSpace(line)
+}
+
+// isVariableName reports whether name lexes as a single identifier, so it
+// can be used in dice strings
+func isVariableName(name string) bool {
+	if name == "let" || name == "print" {
+		return false
+	}
+	l := lexer.New(name)
+	tok := l.NextToken()
+	return tok.Type == token.IDENT && tok.Literal == name && l.NextToken().Type == token.EOF
+}
+
+// splitItems splits a print statement on the commas outside of quotes
+func splitItems(input string) ([]string, error) {
+	items := []string{}
+	if strings.TrimSpace(input) == "" {
+		return items, nil // a blank line
+	}
+	start, inQuote := 0, false
+	for i := 0; i < len(input); i++ {
+		switch {
+		case input[i] == '\\' && inQuote:
+			i++
+		case input[i] == '"':
+			inQuote = !inQuote
+		case input[i] == ',' && !inQuote:
+			items = append(items, strings.TrimSpace(input[start:i]))
+			start = i + 1
+		}
+	}
+	if inQuote {
+		return nil, fmt.Errorf("unterminated text in %q", input)
+	}
+	items = append(items, strings.TrimSpace(input[start:]))
+
+	for _, item := range items {
+		if item == "" {
+			return nil, fmt.Errorf("expected print <text or dice string>, ..., got=%q", "print "+input)
+		}
+	}
+	return items, nil
+}
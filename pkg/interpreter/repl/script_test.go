@@ -0,0 +1,126 @@
+package repl
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/render"
+)
+
+func TestScript(t *testing.T) {
+	testCases := []struct {
+		name     string
+		script   string
+		expected string
+	}{
+		{
+			"variables",
+			"let bonus = 5\nlet attack = bonus + 2\nattack * 2",
+			"bonus: 5 = 5\nattack: bonus (5) + 2 = 7\nattack (7) * 2 = 14\n",
+		},
+		{
+			"print",
+			"let hp = 4 * 3\nprint \"the goblin has\", hp, \"hp, or\", hp / 2, \"when bloodied\"\nprint\nprint \"done\"",
+			"hp: 4 * 3 = 12\nthe goblin has 12 hp, or 6 when bloodied\n\ndone\n",
+		},
+		{
+			"comments",
+			"# setup\n\n1 + 1 // two\n2[#tag] # three\nprint \"# and // are text\"",
+			"1 + 1 = 2\n2 = 2\n# and // are text\n",
+		},
+		{
+			"continued lines",
+			"let total = (1 +\n  2) *\n  3\ntotal \\\n  - 1",
+			"total: (1 + 2) * 3 = 9\ntotal (9) - 1 = 8\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			if err := NewScript(out, render.TEXT).Run("test.dice", strings.NewReader(tc.script)); err != nil {
+				t.Fatalf("could not run: %v", err)
+			}
+			if out.String() != tc.expected {
+				t.Fatalf("expected=%q, got=%q", tc.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestScriptErrors(t *testing.T) {
+	testCases := []struct {
+		name         string
+		script       string
+		expectedLine int
+		expected     string
+	}{
+		{"unknown variable", "1\n\nhp + 1", 3, "unknown identifier: hp"},
+		{"parse error", "(1 +\n 2", 1, "could not parse"},
+		{"roll error", "let x = 1\nd0", 2, "dice size must be at least 1"},
+		{"missing =", "let x 1", 1, "expected let <name> = <dice string>"},
+		{"dice modifier name", "let kh = 1", 1, `"kh" can't be a variable name`},
+		{"dice name", "let d6 = 1", 1, `"d6" can't be a variable name`},
+		{"keyword name", "let print = 1", 1, `"print" can't be a variable name`},
+		{"unterminated text", "print \"hp, 1", 1, "unterminated text"},
+		{"empty item", "print 1,, 2", 1, "expected print"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := NewScript(&bytes.Buffer{}, render.TEXT).Run("test.dice", strings.NewReader(tc.script))
+			var scriptErr *ScriptError
+			if !errors.As(err, &scriptErr) {
+				t.Fatalf("expected a *ScriptError, got=%v", err)
+			}
+			if scriptErr.Line != tc.expectedLine || !strings.Contains(scriptErr.Message, tc.expected) {
+				t.Fatalf("expected line=%d message=%q, got=%v", tc.expectedLine, tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestScriptSeed(t *testing.T) {
+	run := func() string {
+		out := &bytes.Buffer{}
+		script := NewScript(out, render.TEXT)
+		script.SetSeed(3)
+		if err := script.Run("test.dice", strings.NewReader("let a = d20\nd100qu3 + a")); err != nil {
+			t.Fatalf("could not run: %v", err)
+		}
+		return out.String()
+	}
+
+	if first, second := run(), run(); first != second {
+		t.Fatalf("expected the same rolls, got=%q and %q", first, second)
+	}
+}
+
+func TestScriptJSON(t *testing.T) {
+	out := &bytes.Buffer{}
+	script := "let a = d6\nprint \"a is\", a\na + 1"
+	if err := NewScript(out, render.JSON).Run("test.dice", strings.NewReader(script)); err != nil {
+		t.Fatalf("could not run: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	expectedKinds := []string{"let", "print", "roll"}
+	if len(lines) != len(expectedKinds) {
+		t.Fatalf("expected=%d lines, got=%q", len(expectedKinds), out.String())
+	}
+	for i, line := range lines {
+		statement := statementJSON{}
+		if err := json.Unmarshal([]byte(line), &statement); err != nil {
+			t.Fatalf("could not decode %q: %v", line, err)
+		}
+		if statement.Line != i+1 || statement.Kind != expectedKinds[i] {
+			t.Fatalf("expected line=%d kind=%s, got=%+v", i+1, expectedKinds[i], statement)
+		}
+		if (statement.Kind == "print") == (statement.Result != nil) {
+			t.Fatalf("expected a result on rolls only, got=%+v", statement)
+		}
+	}
+}
@@ -5,6 +5,8 @@ type TokenType string
 type Token struct {
 	Type    TokenType
 	Literal string
+	Start   int // byte offset of the first char of the token in the input
+	End     int // byte offset one past the last char of the token
 }
 
 var Keywords = map[string]TokenType{
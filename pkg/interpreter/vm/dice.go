@@ -0,0 +1,134 @@
+package vm
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/compiler"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/object"
+)
+
+// roll rolls dice from md, applies its modifiers in the order the evaluator
+// does, ma, mi, kh and then kl, and records the dice in md
+func roll(dice *compiler.Dice, md *object.Metadata) (int64, *object.Error) {
+	if dice.Size == 0 {
+		return 0, newError("dice size must be at least 1, got=%s", dice.Literal)
+	}
+	if md.MaxDieSize > 0 && int64(dice.Size) > int64(md.MaxDieSize) {
+		return 0, newError("die too large: %s has more than %d faces", dice.Literal, md.MaxDieSize)
+	}
+	if md.MaxDice > 0 && md.Rolled()+dice.Quantity > md.MaxDice {
+		return 0, newError("too many dice: %s would roll more than %d dice", dice.Literal, md.MaxDice)
+	}
+
+	r := md.Rand()
+	rolled := make([]object.Die, dice.Quantity)
+	for i := range rolled {
+		roll := uint32(r.Intn(int(dice.Size)) + 1)
+		rolled[i] = object.Die{Index: i, Roll: roll, Value: roll}
+	}
+
+	if dice.MaxValue > 0 || dice.MinValue > 0 {
+		for i := range rolled {
+			die := &rolled[i]
+			if dice.MaxValue > 0 && die.Value > dice.MaxValue {
+				die.Value, die.Clamped = dice.MaxValue, true
+			}
+			if dice.MinValue > 0 && die.Value < dice.MinValue {
+				die.Value, die.Clamped = dice.MinValue, true
+			}
+		}
+	}
+	if dice.KeepHighest > 0 {
+		keep(rolled, int(dice.KeepHighest), true)
+	}
+	if dice.KeepLowest > 0 {
+		keep(rolled, int(dice.KeepLowest), false)
+	}
+
+	value := int64(0)
+	for _, die := range rolled {
+		if !die.Dropped {
+			value += int64(die.Value)
+		}
+	}
+
+	md.Add(object.DICE_NODE, dice.Start, dice.End, object.DiceData{
+		Literal: dice.Literal,
+		Tags:    slices.Clone(dice.Tags),
+		Size:    dice.Size,
+		Dice:    rolled,
+		Value:   value,
+	})
+	return value, nil
+}
+
+// keep drops every die not dropped yet but the n highest or lowest. Of
+// several dice of the same value the ones rolled first are kept, like the
+// evaluator does.
+func keep(dice []object.Die, n int, highest bool) {
+	values := make([]uint32, 0, len(dice))
+	for _, die := range dice {
+		if !die.Dropped {
+			values = append(values, die.Value)
+		}
+	}
+	if n >= len(values) {
+		return
+	}
+
+	last, ties := cutoff(values, n, highest)
+	for i := range dice {
+		die := &dice[i]
+		switch {
+		case die.Dropped:
+		case die.Value == last:
+			die.Dropped = ties == 0
+			ties = max(ties-1, 0)
+		case die.Value < last:
+			die.Dropped = highest
+		default:
+			die.Dropped = !highest
+		}
+	}
+}
+
+// cutoff is the value of the last of the n highest or lowest values, and how
+// many of the values equal to it are among them. The values are counted
+// when they are small next to how many there are, and sorted otherwise.
+func cutoff(values []uint32, n int, highest bool) (last uint32, ties int) {
+	largest := slices.Max(values)
+	if int(largest) > 4*len(values)+16 {
+		slices.Sort(values)
+		if highest {
+			slices.Reverse(values)
+		}
+		last = values[n-1]
+		for _, value := range values[:n] {
+			if value == last {
+				ties++
+			}
+		}
+		return last, ties
+	}
+
+	counts := make([]int, largest+1)
+	for _, value := range values {
+		counts[value]++
+	}
+	for i := range counts {
+		value := uint32(i)
+		if highest {
+			value = largest - uint32(i)
+		}
+		if counts[value] >= n {
+			return value, n
+		}
+		n -= counts[value]
+	}
+	return 0, 0 // unreachable, n is below len(values)
+}
+
+func newError(format string, a ...any) *object.Error {
+	return &object.Error{Message: fmt.Sprintf(format, a...)}
+}
@@ -0,0 +1,107 @@
+package vm
+
+import (
+	"slices"
+
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/compiler"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/evaluator"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/object"
+)
+
+// Run runs compiled bytecode, rolling the dice from md and recording them in
+// it. The value, the error and the metadata are the same as evaluating the
+// tree the bytecode was compiled from with evaluator.Eval, and the dice are
+// rolled from md in the same order. Run falls back to the evaluator when
+// md.Trace is set, since tracing describes every step in words and is never
+// on a hot path.
+func Run(bytecode *compiler.Bytecode, md *object.Metadata) object.Object {
+	if md.Trace {
+		return evaluator.Eval(bytecode.Source, md)
+	}
+
+	ins := bytecode.Instructions
+	stack := make([]int64, 0, bytecode.MaxStack)
+	opened := 0 // the nodes opened and not closed yet
+
+	for ip := 0; ip < len(ins); ip++ {
+		switch op := compiler.Opcode(ins[ip]); op {
+		case compiler.OpDice:
+			dice := &bytecode.Dice[compiler.ReadUint16(ins[ip+1:])]
+			ip += 2
+			value, err := roll(dice, md)
+			if err != nil {
+				return fail(md, opened, err)
+			}
+			stack = append(stack, value)
+
+		case compiler.OpInteger:
+			integer := &bytecode.Integers[compiler.ReadUint16(ins[ip+1:])]
+			ip += 2
+			md.Add(object.INTEGER_NODE, integer.Start, integer.End, object.DiceData{
+				Literal: integer.Literal,
+				Tags:    slices.Clone(integer.Tags),
+				Dice:    []object.Die{},
+				Value:   integer.Value,
+			})
+			stack = append(stack, integer.Value)
+
+		case compiler.OpLeaf:
+			leaf := bytecode.Leaves[compiler.ReadUint16(ins[ip+1:])]
+			ip += 2
+			// the evaluator looks up identifiers, and reports expressions
+			// that failed to parse
+			switch result := evaluator.Eval(leaf, md).(type) {
+			case *object.Integer:
+				stack = append(stack, result.Value)
+			case *object.Error:
+				return fail(md, opened, result)
+			}
+
+		case compiler.OpOpen:
+			node := bytecode.Nodes[compiler.ReadUint16(ins[ip+1:])]
+			ip += 2
+			md.Open(node.Kind, node.Operator, node.Start, node.End)
+			opened++
+
+		case compiler.OpClose:
+			md.Close(&object.Integer{Value: stack[len(stack)-1]})
+			opened--
+
+		case compiler.OpPop:
+			stack = stack[:len(stack)-1]
+
+		case compiler.OpEmpty:
+			return fail(md, opened, &object.Error{Message: "empty dice string"})
+
+		case compiler.OpNegate:
+			stack[len(stack)-1] = -stack[len(stack)-1]
+
+		case compiler.OpAdd, compiler.OpSub, compiler.OpMul, compiler.OpDiv, compiler.OpMod, compiler.OpPow:
+			left, right := stack[len(stack)-2], stack[len(stack)-1]
+			value, _ := evaluator.Operate(operators[op], left, right)
+			stack = stack[:len(stack)-1]
+			stack[len(stack)-1] = value
+		}
+	}
+
+	return &object.Integer{Value: stack[len(stack)-1]}
+}
+
+// operators are the infix operators of the opcodes applying them
+var operators = [...]string{
+	compiler.OpAdd: "+",
+	compiler.OpSub: "-",
+	compiler.OpMul: "*",
+	compiler.OpDiv: "/",
+	compiler.OpMod: "%",
+	compiler.OpPow: "^",
+}
+
+// fail closes the nodes left open, as the evaluator does when an error
+// returns through them
+func fail(md *object.Metadata, opened int, err *object.Error) *object.Error {
+	for ; opened > 0; opened-- {
+		md.Close(err)
+	}
+	return err
+}
@@ -0,0 +1,167 @@
+package vm
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/ast"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/compiler"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/evaluator"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/lexer"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/object"
+	"github.com/daneofmanythings/calcuroller/pkg/interpreter/parser"
+)
+
+// differentialInputs cover every node kind, modifier and error, and are
+// rolled by both the evaluator and the vm
+var differentialInputs = []string{
+	"d20",
+	"5",
+	"x",
+	"-d6",
+	"--d6qu2",
+	"d20 + 5",
+	"d6qu4kh3 + d8qu2kl1[fire] - 2",
+	"(d6 + 2) * -3[x]",
+	"d12qu4mi2ma10kh2kl1",
+	"d2qu8kh5kl3",
+	"d3qu6kl4kh2",
+	"d6qu5mi3ma4kh2",
+	"d2000 + d6",
+	"d1000qu3kh2 + d1000qu4kl3",
+	"d6qu3 ^ 2 % 7 / d2",
+	"d6 / (d2 - d2) % (d2 - d2)",
+	"2 ^ -d4",
+	"d4 d6 d8 + x",
+	"d20 + y",
+	"d6qu6 + d6qu6",
+	"d0 + d6",
+	"d6 + 1 +",
+	"(d6 + 2",
+	"d6 * @",
+	"d6 / / 2",
+	"",
+	"   ",
+}
+
+func compile(t testing.TB, input string) (*ast.Program, *compiler.Bytecode) {
+	t.Helper()
+	program := parser.New(lexer.New(input)).ParseProgram()
+	bytecode, err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("could not compile %q: %v", input, err)
+	}
+	return program, bytecode
+}
+
+func newMetadata(seed int64, trace bool) *object.Metadata {
+	md := object.NewSeededMetadata(seed)
+	md.MaxDice = 10
+	md.MaxDieSize = 1000
+	md.Variables = map[string]int64{"x": 3}
+	md.Trace = trace
+	return md
+}
+
+// encode is everything a roll produced: its value or error, and the dice
+// and tree recorded in its metadata
+func encode(t *testing.T, input string, value object.Object, md *object.Metadata) string {
+	t.Helper()
+	encoded, err := json.Marshal(object.NewRollResult(input, value, md))
+	if err != nil {
+		t.Fatalf("could not encode %q: %v", input, err)
+	}
+	return string(encoded)
+}
+
+func TestRunMatchesEval(t *testing.T) {
+	for _, input := range differentialInputs {
+		t.Run(input, func(t *testing.T) {
+			program, bytecode := compile(t, input)
+			for seed := int64(0); seed < 20; seed++ {
+				for _, trace := range []bool{false, true} {
+					md := newMetadata(seed, trace)
+					expected := encode(t, input, evaluator.Eval(program, md), md)
+
+					md = newMetadata(seed, trace)
+					actual := encode(t, input, Run(bytecode, md), md)
+					if actual != expected {
+						t.Fatalf("seed %d, trace=%v:\nexpected=%s\ngot=%s", seed, trace, expected, actual)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRunSyntaxErrors(t *testing.T) {
+	_, bytecode := compile(t, "d6 + @")
+	err, ok := Run(bytecode, object.NewMetadata()).(*object.Error)
+	if !ok || !err.Syntax {
+		t.Fatalf("expected a syntax error, got=%v", err)
+	}
+}
+
+// the bytecode is run many times, and isn't changed by running it
+func TestRunRepeatedly(t *testing.T) {
+	_, bytecode := compile(t, "d6qu4kh3[str] + d20")
+	before := bytecode.String()
+	for seed := int64(0); seed < 100; seed++ {
+		value := Run(bytecode, object.NewSeededMetadata(seed))
+		if value.Type() != object.INTEGER_OBJ {
+			t.Fatalf("expected a value, got=%s", value.Inspect())
+		}
+	}
+	if after := bytecode.String(); after != before {
+		t.Fatalf("expected the bytecode to be unchanged, got=\n%s", after)
+	}
+}
+
+// the benchmarks roll from crypto metadata, since seeding math/rand takes
+// longer than rolling most dice strings
+var benchmarkInputs = []struct {
+	name  string
+	input string
+}{
+	{"single die", "d20"},
+	{"attack", "d20 + 5 + d4[bless]"},
+	{"stats", "d6qu4kh3 + d6qu4kh3 + d6qu4kh3 + d6qu4kh3 + d6qu4kh3 + d6qu4kh3"},
+	{"arithmetic", "((2 + 3) * 4 - 6 / 2) ^ 2 % 1000 + -(7 * 8)"},
+	{"pool", "d6qu100kh10 + d20qu50mi5kl3"},
+}
+
+// BenchmarkParseAndEval is how a dice string is rolled without compiling it
+func BenchmarkParseAndEval(b *testing.B) {
+	for _, bc := range benchmarkInputs {
+		b.Run(bc.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				program := parser.New(lexer.New(bc.input)).ParseProgram()
+				evaluator.Eval(program, object.NewCryptoMetadata())
+			}
+		})
+	}
+}
+
+func BenchmarkEval(b *testing.B) {
+	for _, bc := range benchmarkInputs {
+		b.Run(bc.name, func(b *testing.B) {
+			program := parser.New(lexer.New(bc.input)).ParseProgram()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				evaluator.Eval(program, object.NewCryptoMetadata())
+			}
+		})
+	}
+}
+
+func BenchmarkRun(b *testing.B) {
+	for _, bc := range benchmarkInputs {
+		b.Run(bc.name, func(b *testing.B) {
+			_, bytecode := compile(b, bc.input)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				Run(bytecode, object.NewCryptoMetadata())
+			}
+		})
+	}
+}